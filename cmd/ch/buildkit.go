@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/timo-reymann/ContainerHive/internal/buildkit"
+	"github.com/timo-reymann/ContainerHive/internal/buildkitd"
+	"github.com/timo-reymann/ContainerHive/pkg/discovery"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// buildkitFlags are the connection flags of all commands talking to BuildKit
+type buildkitFlags struct {
+	address        *string
+	caCert         *string
+	cert           *string
+	key            *string
+	serverName     *string
+	connectTimeout *time.Duration
+	autoStart      *bool
+}
+
+func registerBuildkitFlags(flags *flag.FlagSet) *buildkitFlags {
+	return &buildkitFlags{
+		address:        flags.String("buildkit-addr", "", "BuildKit address: tcp://, unix://, docker-container:// or kube-pod://"),
+		caCert:         flags.String("buildkit-tls-ca-cert", "", "PEM file used to verify the BuildKit server certificate"),
+		cert:           flags.String("buildkit-tls-cert", "", "PEM file with the client certificate for BuildKit"),
+		key:            flags.String("buildkit-tls-key", "", "PEM file with the client key for BuildKit"),
+		serverName:     flags.String("buildkit-tls-server-name", "", "Server name to verify the BuildKit server certificate against"),
+		connectTimeout: flags.Duration("buildkit-connect-timeout", 0, "Timeout for connecting to BuildKit"),
+		autoStart:      flags.Bool("buildkit-auto-start", false, "Start a rootless buildkitd when BuildKit is not reachable"),
+	}
+}
+
+// connection resolves the BuildKit connection, flags take precedence over the environment and hive.yml
+func (f *buildkitFlags) connection(config *model.BuildkitConfig) (buildkit.ConnectionConfig, error) {
+	connection := buildkit.ConnectionConfig{Address: buildkitAddr}
+	if config != nil {
+		var err error
+		connection, err = toConnectionConfig(connection.Address, config.Address, config.TLS, config.ConnectTimeout)
+		if err != nil {
+			return connection, err
+		}
+	}
+
+	connection, err := connection.WithEnvOverrides()
+	if err != nil {
+		return connection, err
+	}
+
+	overrides := map[*string]string{
+		&connection.Address:    *f.address,
+		&connection.CACert:     *f.caCert,
+		&connection.Cert:       *f.cert,
+		&connection.Key:        *f.key,
+		&connection.ServerName: *f.serverName,
+	}
+	for target, val := range overrides {
+		if val != "" {
+			*target = val
+		}
+	}
+	if *f.connectTimeout != 0 {
+		connection.ConnectTimeout = *f.connectTimeout
+	}
+
+	return connection, nil
+}
+
+// toConnectionConfig converts the hive.yml connection settings, falling back to defaultAddress when address is empty
+func toConnectionConfig(defaultAddress, address string, tls model.BuildkitTLSConfig, connectTimeout string) (buildkit.ConnectionConfig, error) {
+	connection := buildkit.ConnectionConfig{
+		Address:    defaultAddress,
+		CACert:     tls.CACert,
+		Cert:       tls.Cert,
+		Key:        tls.Key,
+		ServerName: tls.ServerName,
+	}
+	if address != "" {
+		connection.Address = address
+	}
+	if connectTimeout != "" {
+		timeout, err := time.ParseDuration(connectTimeout)
+		if err != nil {
+			return connection, fmt.Errorf("invalid BuildKit connect timeout %q", connectTimeout)
+		}
+		connection.ConnectTimeout = timeout
+	}
+	return connection, nil
+}
+
+// shouldAutoStart reports if a managed buildkitd should be started, the flag and environment take precedence over hive.yml
+func (f *buildkitFlags) shouldAutoStart(config *model.BuildkitConfig) (bool, error) {
+	if *f.autoStart {
+		return true, nil
+	}
+	if val := os.Getenv("CONTAINER_HIVE_BUILDKIT_AUTO_START"); val != "" {
+		autoStart, err := strconv.ParseBool(val)
+		if err != nil {
+			return false, fmt.Errorf("invalid value for CONTAINER_HIVE_BUILDKIT_AUTO_START: %q", val)
+		}
+		return autoStart, nil
+	}
+	return config != nil && config.AutoStart, nil
+}
+
+// daemonStateDir returns the state directory of the managed buildkitd, relative dirs are resolved against the project root
+func daemonStateDir(config *model.BuildkitConfig, projectRoot string) (string, error) {
+	if config == nil || config.StateDir == "" {
+		return buildkitd.DefaultStateDir()
+	}
+	if filepath.IsAbs(config.StateDir) {
+		return config.StateDir, nil
+	}
+	return filepath.Join(projectRoot, config.StateDir), nil
+}
+
+// requestedEntitlements returns the insecure entitlements requested by the build settings of the images, tags and variants
+func requestedEntitlements(project *model.ContainerHiveProject) []string {
+	requested := make(map[model.Entitlement]bool)
+	add := func(config *model.BuildConfig) {
+		if config == nil {
+			return
+		}
+		for _, entitlement := range config.Entitlements {
+			requested[entitlement] = true
+		}
+	}
+
+	for _, image := range project.ImagesByIdentifier {
+		add(image.Build)
+		for _, tag := range image.Tags {
+			add(tag.Build)
+		}
+		for _, variant := range image.Variants {
+			add(variant.Build)
+		}
+	}
+
+	var entitlements []string
+	for _, entitlement := range model.Entitlements {
+		if requested[entitlement] {
+			entitlements = append(entitlements, string(entitlement))
+		}
+	}
+	return entitlements
+}
+
+// connect resolves the connection and connects to BuildKit, starting a managed buildkitd if it is not reachable
+// and auto start is enabled. The managed daemon only allows the given insecure entitlements.
+// The returned function stops the managed daemon and must be called after closing the client.
+func (f *buildkitFlags) connect(ctx context.Context, config *model.BuildkitConfig, projectRoot string, entitlements []string) (*buildkit.Client, func(), error) {
+	noop := func() {}
+	connection, err := f.connection(config)
+	if err != nil {
+		return nil, noop, err
+	}
+	autoStart, err := f.shouldAutoStart(config)
+	if err != nil {
+		return nil, noop, err
+	}
+
+	bkClient, connectErr := buildkit.NewClient(ctx, connection)
+	if connectErr == nil || !autoStart {
+		return bkClient, noop, connectErr
+	}
+
+	stateDir, err := daemonStateDir(config, projectRoot)
+	if err != nil {
+		return nil, noop, err
+	}
+	log.Printf("BuildKit is not reachable at %s, starting buildkitd in %s", connection.Address, stateDir)
+	daemon := buildkitd.NewDaemon(stateDir, entitlements...)
+	if err := daemon.Start(ctx); err != nil {
+		return nil, noop, errors.Join(connectErr, err)
+	}
+	stop := func() {
+		if err := daemon.Stop(context.Background()); err != nil {
+			log.Printf("Warning: Failed to stop buildkitd: %v", err)
+		}
+	}
+
+	bkClient, err = buildkit.NewClient(ctx, buildkit.ConnectionConfig{
+		Address:        daemon.Address(),
+		ConnectTimeout: connection.ConnectTimeout,
+	})
+	if err != nil {
+		stop()
+		return nil, noop, err
+	}
+	log.Printf("Started buildkitd listening on %s", daemon.Address())
+	return bkClient, stop, nil
+}
+
+// connectNodes connects to the default BuildKit daemon like connect and to all nodes configured in hive.yml.
+// The returned function stops the managed daemon and must be called after closing the nodes.
+func (f *buildkitFlags) connectNodes(ctx context.Context, config *model.BuildkitConfig, projectRoot string, entitlements []string) (*buildkit.Nodes, func(), error) {
+	bkClient, stopDaemon, err := f.connect(ctx, config, projectRoot, entitlements)
+	if err != nil {
+		return nil, stopDaemon, err
+	}
+	nodes := buildkit.NewNodes(bkClient)
+	if config == nil {
+		return nodes, stopDaemon, nil
+	}
+
+	for _, node := range config.Nodes {
+		if err := connectNode(ctx, nodes, node); err != nil {
+			nodes.Close()
+			stopDaemon()
+			return nil, func() {}, err
+		}
+		log.Printf("Building %s on BuildKit node %s", strings.Join(node.Platforms, ", "), node.Address)
+	}
+	return nodes, stopDaemon, nil
+}
+
+func connectNode(ctx context.Context, nodes *buildkit.Nodes, node model.BuildkitNodeConfig) error {
+	if len(node.Platforms) == 0 {
+		return fmt.Errorf("BuildKit node %s has no platforms", node.Address)
+	}
+	connection, err := toConnectionConfig("", node.Address, node.TLS, node.ConnectTimeout)
+	if err != nil {
+		return err
+	}
+	nodeClient, err := buildkit.NewClient(ctx, connection)
+	if err != nil {
+		return err
+	}
+	if err := nodes.Add(nodeClient, node.Platforms...); err != nil {
+		nodeClient.Close()
+		return err
+	}
+	return nil
+}
+
+// buildkitInfo checks the connection to BuildKit and prints its workers and their garbage collection policy.
+// A managed buildkitd is started like for a build of the selected images.
+func buildkitInfo(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("buildkit-info", flag.ContinueOnError)
+	projectDir := flags.String("project", ".", "Project directory containing the hive.yml")
+	bkFlags := registerBuildkitFlags(flags)
+	selector := registerSelectionFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	project, err := discovery.DiscoverProject(ctx, *projectDir)
+	if err != nil {
+		return err
+	}
+	selected, err := selectProject(selector, project)
+	if err != nil {
+		return err
+	}
+
+	bkClient, stopDaemon, err := bkFlags.connect(ctx, project.Config.Buildkit, project.RootDir, requestedEntitlements(selected))
+	if err != nil {
+		return err
+	}
+	defer stopDaemon()
+	defer bkClient.Close()
+
+	health, err := bkClient.Health(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Print(health)
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/timo-reymann/ContainerHive/internal/buildkit"
+	"github.com/timo-reymann/ContainerHive/internal/oci_layout"
+	"github.com/timo-reymann/ContainerHive/internal/registry"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// imageExport decides how built images are exported, based on the export config of the project
+type imageExport struct {
+	config *model.ExportConfig
+	mode   buildkit.ExportMode
+	// store is shared by all images when exporting OCI layouts
+	store *oci_layout.Store
+}
+
+func newImageExport(config *model.ExportConfig, distPath string) (*imageExport, error) {
+	if config == nil {
+		config = &model.ExportConfig{}
+	}
+	mode, err := buildkit.ParseExportMode(config.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &imageExport{config: config, mode: mode}
+	if mode.Layout {
+		export.store, err = oci_layout.NewStore(filepath.Join(distPath, "oci"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return export, nil
+}
+
+// pushesToStaging reports if BuildKit pushes the images to the staging registry itself
+func (e *imageExport) pushesToStaging() bool {
+	return e.mode.Push && e.config.Registry == ""
+}
+
+// exportTarget is where a single image tag is exported to
+type exportTarget struct {
+	imageTag string
+	tarFile  string
+	store    *oci_layout.Store
+	sbomFile string
+	push     *buildkit.PushOpts
+}
+
+// forTag returns the export target for an image tag, staging is nil if no staging registry is running.
+// Images used as base by other images are always exported locally, unless BuildKit already pushes them to staging.
+func (e *imageExport) forTag(distPath, name, tag string, staging registry.Registry, usedAsBase bool) (*exportTarget, error) {
+	target := &exportTarget{imageTag: name + ":" + tag}
+
+	switch {
+	case e.mode.Layout:
+		target.store = e.store
+		target.sbomFile = filepath.Join(distPath, name, tag, "image.sbom.spdx.json")
+	case e.mode.Tar || (usedAsBase && !e.pushesToStaging()):
+		target.tarFile = tarFilePath(distPath, name, tag)
+		target.sbomFile = target.tarFile + ".sbom.spdx.json"
+	}
+
+	if !e.mode.Push {
+		return target, nil
+	}
+
+	registryAddr := e.config.Registry
+	insecure := e.config.Insecure
+	if registryAddr == "" {
+		if staging == nil {
+			return nil, errors.New("pushing without a registry configured requires the staging registry")
+		}
+		registryAddr = staging.Address()
+		insecure = staging.IsLocal()
+	}
+
+	target.push = &buildkit.PushOpts{
+		Refs:     []string{strings.TrimSuffix(registryAddr, "/") + "/" + target.imageTag},
+		Insecure: insecure,
+	}
+	return target, nil
+}
+
+// layoutDir returns the OCI layout directory to export to, empty when not exporting a layout
+func (t *exportTarget) layoutDir() string {
+	if t.store == nil {
+		return ""
+	}
+	return t.store.Path()
+}
+
+// isLocal reports if the image is available locally for scanning and testing
+func (t *exportTarget) isLocal() bool {
+	return t.tarFile != "" || t.store != nil
+}
+
+// stage pushes the locally exported image to the staging registry
+func (t *exportTarget) stage(ctx context.Context, staging registry.Registry, name, tag string) error {
+	if t.store != nil {
+		return staging.PushFromStore(ctx, t.store, name, tag)
+	}
+	return staging.Push(ctx, name, tag, t.tarFile)
+}
+
+// describe summarizes where the built image was exported to
+func (t *exportTarget) describe(result *buildkit.BuildResult) string {
+	var targets []string
+	if t.tarFile != "" {
+		targets = append(targets, t.tarFile)
+	}
+	if t.store != nil {
+		targets = append(targets, t.store.Path())
+	}
+	if t.push != nil {
+		for _, ref := range t.push.Refs {
+			targets = append(targets, ref+"@"+result.Digest)
+		}
+	}
+	return strings.Join(targets, ", ")
+}
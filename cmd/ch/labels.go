@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/timo-reymann/ContainerHive/internal/buildkit"
+	"github.com/timo-reymann/ContainerHive/internal/dependency"
+	"github.com/timo-reymann/ContainerHive/internal/oci_labels"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// imageLabeler computes the labels of the built images, combining the standard OCI labels with the user defined ones
+type imageLabeler struct {
+	project  *model.ContainerHiveProject
+	revision string
+	source   string
+	// registry the images are published to, empty if they are not pushed to a configured registry
+	registry string
+	// digests of the built images by image tag, for images based on them
+	digests map[string]string
+}
+
+// newImageLabeler reads the git metadata of the project, which is omitted from the labels if not available
+func newImageLabeler(ctx context.Context, project *model.ContainerHiveProject, export *imageExport) *imageLabeler {
+	labeler := &imageLabeler{project: project, digests: make(map[string]string)}
+
+	if revision, err := oci_labels.GitRevision(ctx, project.RootDir); err == nil {
+		labeler.revision = revision
+	} else {
+		log.Printf("Not adding revision label: %v", err)
+	}
+	if source, err := oci_labels.GitSourceURL(ctx, project.RootDir); err == nil {
+		labeler.source = source
+	}
+	if export != nil && export.mode.Push {
+		labeler.registry = strings.TrimSuffix(export.config.Registry, "/")
+	}
+
+	return labeler
+}
+
+// labels returns the labels of an image tag built from the rendered Dockerfile.
+// User labels override the standard ones, in the order project, image, tag and variant.
+func (l *imageLabeler) labels(imageDef *model.Image, version, dockerfilePath string, sourceDateEpoch int64, user model.Labels) model.Labels {
+	metadata := &oci_labels.Metadata{
+		Title:    imageDef.Name,
+		Version:  version,
+		Revision: l.revision,
+		Source:   l.source,
+		Created:  sourceDateEpoch,
+	}
+
+	base, err := dependency.FinalStageHiveRef(dockerfilePath)
+	if err != nil {
+		log.Printf("Warning: Failed to determine base image of %s:%s: %v", imageDef.Name, version, err)
+	}
+	if base != nil {
+		baseTag := base.ImageName + ":" + base.Tag
+		metadata.BaseName = l.publicRef(baseTag)
+		metadata.BaseDigest = l.digests[baseTag]
+	}
+
+	return oci_labels.Merge(metadata.ToLabels(), l.project.Config.Labels, user)
+}
+
+// built records the digest of a built image, used for images based on it
+func (l *imageLabeler) built(imageTag string, result *buildkit.BuildResult) {
+	if result != nil && result.Digest != "" {
+		l.digests[imageTag] = result.Digest
+	}
+}
+
+// publicRef returns the reference an image tag is published as, empty when it is not pushed,
+// as the name of the staging registry is meaningless outside the build
+func (l *imageLabeler) publicRef(imageTag string) string {
+	if l.registry == "" {
+		return ""
+	}
+	return l.registry + "/" + imageTag
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -9,7 +10,10 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/anchore/syft/syft/sbom"
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/util/progress/progressui"
 	"github.com/timo-reymann/ContainerHive/internal/buildconfig_resolver"
@@ -19,33 +23,35 @@ import (
 	"github.com/timo-reymann/ContainerHive/internal/container_structure_test"
 	"github.com/timo-reymann/ContainerHive/internal/dependency"
 	"github.com/timo-reymann/ContainerHive/internal/docker"
+	"github.com/timo-reymann/ContainerHive/internal/oci_labels"
 	"github.com/timo-reymann/ContainerHive/internal/registry"
+	"github.com/timo-reymann/ContainerHive/internal/reproducible"
+	"github.com/timo-reymann/ContainerHive/internal/secrets"
 	"github.com/timo-reymann/ContainerHive/internal/syft"
+	"github.com/timo-reymann/ContainerHive/internal/vault"
 	"github.com/timo-reymann/ContainerHive/pkg/discovery"
 	"github.com/timo-reymann/ContainerHive/pkg/model"
 	"github.com/timo-reymann/ContainerHive/pkg/rendering"
 )
 
 const (
-	// Matches hack/docker-compose.yml buildkitd service
+	// Default BuildKit address, matches hack/docker-compose.yml buildkitd service
 	buildkitAddr = "tcp://127.0.0.1:8502"
-
-	// Matches hack/garage/init.sh S3 cache configuration
-	// Note: Use docker-compose service name 'garage' since buildkitd runs in container
-	s3Endpoint  = "http://127.0.0.1:39505"
-	s3Bucket    = "buildkit-cache"
-	s3Region    = "garage"
-	s3AccessKey = "GK31337cafe000000000000000"
-	s3SecretKey = "1337cafe0000000000000000000000000000000000000000000000000000dead"
-
-	imageName = "ch-smoke-test:latest"
 )
 
 var platform = "linux/" + runtime.GOARCH
 
-// newProgressWriter returns a buildkit status handler that displays build progress.
-func newProgressWriter() func(chan *client.SolveStatus) error {
-	return func(ch chan *client.SolveStatus) error {
+// commands run instead of the build when passed as first argument
+var commands = map[string]func(ctx context.Context, args []string) error{
+	"buildkit-info":       buildkitInfo,
+	"prune-cache":         pruneCache,
+	"validate":            validate,
+	"verify-reproducible": verifyReproducible,
+}
+
+// newProgressWriter returns a buildkit status handler that displays build progress with secrets masked.
+func newProgressWriter(redactor *secrets.Redactor) func(chan *client.SolveStatus) error {
+	return buildkit.RedactStatusUpdates(redactor.Redact, func(ch chan *client.SolveStatus) error {
 		// TODO for production support writing trace
 		d, err := progressui.NewDisplay(os.Stdout, progressui.TtyMode)
 		if err != nil {
@@ -53,17 +59,18 @@ func newProgressWriter() func(chan *client.SolveStatus) error {
 		}
 		_, err = d.UpdateFrom(context.TODO(), ch)
 		return err
-	}
+	})
 }
 
 // patchHiveRefs rewrites __hive__/ references in a Dockerfile for registry use.
-// Returns the patched file path and a cleanup function.
-func patchHiveRefs(dockerfilePath, registryAddr string) (string, func()) {
+// Returns the patched file path and a cleanup function removing it.
+func patchHiveRefs(dockerfilePath, registryAddr string) (string, func(), error) {
 	patched := dockerfilePath + ".patched"
 	if err := build_context.RewriteHiveRefs(dockerfilePath, patched, registryAddr); err != nil {
-		log.Fatalf("Failed to rewrite hive refs for %s: %v", dockerfilePath, err)
+		os.Remove(patched)
+		return "", nil, fmt.Errorf("failed to rewrite hive refs for %s: %w", dockerfilePath, err)
 	}
-	return patched, func() { os.Remove(patched) }
+	return patched, func() { os.Remove(patched) }, nil
 }
 
 // tarFilePath returns the OCI tar output path inside the rendered dist directory for a given image tag.
@@ -87,10 +94,21 @@ func collectTestDefinitions(distDir string) []string {
 	return paths
 }
 
-// generateSBOM generates an SPDX SBOM from a built image tar and writes it alongside the tar.
-func generateSBOM(ctx context.Context, sbomTool *syft.SBOMImageTool, tarFile, imageTag string) {
+// generateSBOM generates an SPDX SBOM from a locally exported image and writes it alongside the image.
+func generateSBOM(ctx context.Context, sbomTool *syft.SBOMImageTool, redactor *secrets.Redactor, target *exportTarget) {
+	imageTag := target.imageTag
+	if !target.isLocal() {
+		log.Printf("%s was only pushed, skipping SBOM", imageTag)
+		return
+	}
 	log.Printf("Generating SBOM for %s ...", imageTag)
-	sbomResult, err := sbomTool.GenerateSBOM(ctx, tarFile)
+	var sbomResult *sbom.SBOM
+	var err error
+	if target.store != nil {
+		sbomResult, err = sbomTool.GenerateSBOMFromStore(ctx, target.store, imageTag)
+	} else {
+		sbomResult, err = sbomTool.GenerateSBOM(ctx, target.tarFile)
+	}
 	if err != nil {
 		log.Printf("Warning: SBOM generation failed for %s: %v", imageTag, err)
 		return
@@ -100,30 +118,40 @@ func generateSBOM(ctx context.Context, sbomTool *syft.SBOMImageTool, tarFile, im
 		log.Printf("Warning: SBOM serialization failed for %s: %v", imageTag, err)
 		return
 	}
-	sbomPath := tarFile + ".sbom.spdx.json"
-	if err := os.WriteFile(sbomPath, serialized, 0644); err != nil {
+	serialized = redactor.RedactBytes(serialized)
+	if err := os.WriteFile(target.sbomFile, serialized, 0644); err != nil {
 		log.Printf("Warning: Failed to write SBOM for %s: %v", imageTag, err)
 		return
 	}
-	log.Printf("SBOM written for %s -> %s (%d bytes)", imageTag, sbomPath, len(serialized))
+	log.Printf("SBOM written for %s -> %s (%d bytes)", imageTag, target.sbomFile, len(serialized))
 }
 
-// runContainerStructureTests runs container structure tests for a built image tar.
-func runContainerStructureTests(dockerClient *docker.Client, tarFile string, testDefs []string, imageTag, reportDir string) {
+// runContainerStructureTests runs container structure tests for a locally exported image.
+func runContainerStructureTests(dockerClient *docker.Client, redactor *secrets.Redactor, target *exportTarget, testDefs []string, reportDir string) {
+	imageTag := target.imageTag
 	if len(testDefs) == 0 {
 		log.Printf("No container-structure-test definitions for %s, skipping", imageTag)
 		return
 	}
+	if !target.isLocal() {
+		log.Printf("%s was only pushed, skipping container-structure-tests", imageTag)
+		return
+	}
 
 	reportFile := filepath.Join(reportDir, fmt.Sprintf("%s-cst-report.xml", strings.ReplaceAll(imageTag, ":", "-")))
 	log.Printf("Running container-structure-tests for %s (%d test file(s))...", imageTag, len(testDefs))
 
 	runner := &container_structure_test.TestRunner{
 		TestDefinitionPaths: testDefs,
-		Image:               tarFile,
+		Image:               target.tarFile,
 		Platform:            platform,
 		ReportFile:          reportFile,
 		DockerClient:        dockerClient,
+		Redactor:            redactor,
+	}
+	if target.store != nil {
+		runner.Image = imageTag
+		runner.Store = target.store
 	}
 
 	if err := runner.Run(); err != nil {
@@ -133,101 +161,275 @@ func runContainerStructureTests(dockerClient *docker.Client, tarFile string, tes
 	log.Printf("Container structure tests passed for %s -> %s", imageTag, reportFile)
 }
 
-func main() {
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, os.Interrupt)
+// sshForwards maps the ssh configuration of an image, resolving key paths relative to the image directory.
+func sshForwards(imageDef *model.Image) []buildkit.SSHForward {
+	forwards := make([]buildkit.SSHForward, 0, len(imageDef.SSH))
+	for _, sshConfig := range imageDef.SSH {
+		paths := make([]string, 0, len(sshConfig.Paths))
+		for _, path := range sshConfig.Paths {
+			if path == "~" || strings.HasPrefix(path, "~/") {
+				if home, err := os.UserHomeDir(); err == nil {
+					path = filepath.Join(home, path[1:])
+				}
+			} else if !filepath.IsAbs(path) {
+				path = filepath.Join(imageDef.RootDir, path)
+			}
+			paths = append(paths, path)
+		}
+		forwards = append(forwards, buildkit.SSHForward{ID: sshConfig.ID, Paths: paths})
+	}
+	return forwards
+}
+
+// resolveSourceDateEpoch determines the SOURCE_DATE_EPOCH of an image, making its builds reproducible.
+func resolveSourceDateEpoch(ctx context.Context, project *model.ContainerHiveProject, imageDef *model.Image) (int64, error) {
+	epoch, source, err := reproducible.SourceDateEpoch(ctx, imageDef.RootDir, imageDef.SourceDateEpoch, project.Config.SourceDateEpoch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to determine SOURCE_DATE_EPOCH for %s: %w", imageDef.Name, err)
+	}
+	if source == reproducible.SourceDefault {
+		log.Printf("Warning: No git commit found for %s, using the default SOURCE_DATE_EPOCH=%d. Configure source_date_epoch or set SOURCE_DATE_EPOCH to use a meaningful date.", imageDef.Name, epoch)
+		return epoch, nil
+	}
+	log.Printf("Using SOURCE_DATE_EPOCH=%d from %s for %s", epoch, source, imageDef.Name)
+	return epoch, nil
+}
 
+// buildPlatforms returns the platforms to build the image for, the image setting takes precedence over the project
+func buildPlatforms(project *model.ContainerHiveProject, imageDef *model.Image) []string {
+	if len(imageDef.Platforms) > 0 {
+		return imageDef.Platforms
+	}
+	if len(project.Config.Platforms) > 0 {
+		return project.Config.Platforms
+	}
+	return []string{platform}
+}
+
+// configureSecrets applies the project settings used for resolving secrets.
+func configureSecrets(project *model.ContainerHiveProject) error {
+	secrets.SetProjectRoot(project.RootDir)
+	configureVault(project.Config.Vault)
+
+	var timeout time.Duration
+	if project.Config.Secrets != nil && project.Config.Secrets.ExecTimeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(project.Config.Secrets.ExecTimeout)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid exec secret timeout %q", project.Config.Secrets.ExecTimeout)
+		}
+	}
+	secrets.SetExecTimeout(timeout)
+	return nil
+}
+
+// configureVault applies the project vault configuration used for resolving vault:// secrets.
+func configureVault(vaultConfig *model.VaultConfig) {
+	if vaultConfig == nil {
+		return
+	}
+	vault.Configure(vault.Config{
+		Address:   vaultConfig.Address,
+		Namespace: vaultConfig.Namespace,
+		TLS: vault.TLSConfig{
+			CACert:     vaultConfig.TLS.CACert,
+			CAPath:     vaultConfig.TLS.CAPath,
+			ClientCert: vaultConfig.TLS.ClientCert,
+			ClientKey:  vaultConfig.TLS.ClientKey,
+			ServerName: vaultConfig.TLS.ServerName,
+			SkipVerify: vaultConfig.TLS.SkipVerify,
+		},
+		Auth: vault.AuthConfig{
+			Method:    vaultConfig.Auth.Method,
+			Mount:     vaultConfig.Auth.Mount,
+			Role:      vaultConfig.Auth.Role,
+			RoleId:    vaultConfig.Auth.RoleId,
+			JwtEnv:    vaultConfig.Auth.JwtEnv,
+			TokenFile: vaultConfig.Auth.TokenFile,
+		},
+	})
+}
+
+// exitCodeInterrupted is the exit code of a cancelled run, following the shell convention for SIGINT
+const exitCodeInterrupted = 130
+
+func main() {
+	// the first signal cancels the run so it can abort in-flight solves and clean up,
+	// afterwards the default handling is restored and a second signal terminates immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	go func() {
-		<-done
-		os.Exit(0)
+		<-ctx.Done()
+		stop()
 	}()
 
-	ctx := context.TODO()
+	run := build
+	args := os.Args[1:]
+	if len(args) > 0 {
+		if command, ok := commands[args[0]]; ok {
+			run = command
+			args = args[1:]
+		}
+	}
+	os.Exit(exitCode(ctx, run(ctx, args)))
+}
+
+// exitCode logs the error of the run and returns the exit code for it
+func exitCode(ctx context.Context, err error) int {
+	switch {
+	case ctx.Err() != nil:
+		log.Println("Interrupted, exiting after cleanup")
+		return exitCodeInterrupted
+	case err != nil:
+		log.Println(err)
+		return 1
+	default:
+		return 0
+	}
+}
+
+// build renders, builds, tests and exports all images of the project
+func build(ctx context.Context, args []string) error {
+	bkFlags := registerBuildkitFlags(flag.CommandLine)
+	selector := registerSelectionFlags(flag.CommandLine)
+	if err := flag.CommandLine.Parse(args); err != nil {
+		return err
+	}
+
+	// Secrets are resolved lazily when building and only once per run, even if shared by multiple images
+	secretResolver := secrets.NewRunResolver()
+	// Every resolved secret is masked in all output of the run
+	redactor := secretResolver.Redactor()
+	logOutput := redactor.Writer(os.Stderr)
+	log.SetOutput(logOutput)
+	defer logOutput.Close()
+
 	project, err := discovery.DiscoverProject(ctx, "example")
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Printf("Discovered %d image(s) in project %s", len(project.ImagesByIdentifier), project.RootDir)
+	if err := configureSecrets(project); err != nil {
+		return err
+	}
 	for name, images := range project.ImagesByName {
 		for _, img := range images {
 			log.Printf("  image %q: %d tag(s), %d variant(s)", name, len(img.Tags), len(img.Variants))
 		}
 	}
 
-	distPath := "example/dist"
-	if err := rendering.RenderProject(ctx, project, distPath); err != nil {
-		log.Fatal(err)
+	// Step: Restrict the run to the selected images, only they are rendered, built, tested and published
+	fullProject := project
+	if project, err = selectProject(selector, project); err != nil {
+		return err
 	}
-	log.Println("Rendered project to", distPath)
 
-	// Step: Scan rendered Dockerfiles for __hive__/ dependencies
-	log.Println("Scanning rendered project for base image dependencies...")
-	scannedGraph, err := dependency.ScanRenderedProject(distPath)
-	if err != nil {
-		log.Fatalf("Dependency scanning failed: %v", err)
-	}
+	distPath := "example/dist"
+	var graph *dependency.Graph
+	if selector.IsEmpty() {
+		if err := rendering.RenderProject(ctx, project, distPath); err != nil {
+			return err
+		}
+		log.Println("Rendered project to", distPath)
 
-	// Step: Merge auto-detected deps with explicit depends_on from image configs
-	graph, err := dependency.BuildDependencyGraph(scannedGraph, project)
-	if err != nil {
-		log.Fatalf("Dependency graph construction failed: %v", err)
+		// Step: Scan rendered Dockerfiles for __hive__/ dependencies
+		log.Println("Scanning rendered project for base image dependencies...")
+		scannedGraph, err := dependency.ScanRenderedProject(distPath)
+		if err != nil {
+			return fmt.Errorf("dependency scanning failed: %w", err)
+		}
+
+		// Step: Merge auto-detected deps with explicit depends_on from image configs
+		graph, err = dependency.BuildDependencyGraph(scannedGraph, project)
+		if err != nil {
+			return fmt.Errorf("dependency graph construction failed: %w", err)
+		}
+	} else {
+		// the graph is scanned in memory, so an incomplete selection fails before anything is rendered
+		if graph, err = selectionGraph(fullProject, project); err != nil {
+			return err
+		}
+
+		// the output of images outside the selection is kept
+		if err := rendering.RenderImages(ctx, project, distPath); err != nil {
+			return err
+		}
+		log.Println("Rendered selected images to", distPath)
 	}
 
 	buildOrder, err := graph.TopologicalSort()
 	if err != nil {
-		log.Fatalf("Dependency resolution failed: %v", err)
+		return fmt.Errorf("dependency resolution failed: %w", err)
 	}
 	log.Printf("Build order: %v", buildOrder)
 
 	reportDir := "example/reports"
 	if err := os.MkdirAll(reportDir, 0755); err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	// Initialize BuildKit client
 	log.Println("Connecting to BuildKit...")
-	bkClient, err := buildkit.NewClient(ctx, buildkitAddr)
+	nodes, stopDaemon, err := bkFlags.connectNodes(ctx, project.Config.Buildkit, project.RootDir, requestedEntitlements(project))
 	if err != nil {
-		log.Fatalf("Failed to connect to BuildKit at %s: %v", buildkitAddr, err)
+		return err
 	}
-	defer bkClient.Close()
+	defer stopDaemon()
+	defer nodes.Close()
 
-	version, err := bkClient.Version(ctx)
+	health, err := nodes.Default().Health(ctx)
 	if err != nil {
-		log.Fatalf("Failed to get BuildKit version: %v", err)
+		return fmt.Errorf("failed to query BuildKit: %w", err)
+	}
+	log.Printf("BuildKit version: %s", health.Version)
+	for _, worker := range health.Workers {
+		log.Printf("  worker %s: platforms %s, gc policy %s", worker.ID, strings.Join(worker.Platforms, ", "), strings.Join(worker.GCPolicy, "; "))
 	}
-	log.Printf("BuildKit version: %s", version)
 
 	// Initialize SBOM tool
 	sbomTool, err := syft.NewSBOMImageTool()
 	if err != nil {
-		log.Fatalf("Failed to initialize SBOM tool: %v", err)
+		return fmt.Errorf("failed to initialize SBOM tool: %w", err)
 	}
 
 	// Initialize Docker client for container-structure-tests
 	dockerClient, err := docker.NewClient()
 	if err != nil {
-		log.Fatalf("Failed to initialize Docker client: %v", err)
+		return fmt.Errorf("failed to initialize Docker client: %w", err)
 	}
 	defer dockerClient.Close()
 
-	// Configure S3 cache (matches hack/docker-compose.yml garage service)
-	s3Cache := &cache.S3BuildKitCache{
-		EndpointUrl:     s3Endpoint,
-		Bucket:          s3Bucket,
-		Region:          s3Region,
-		AccessKeyId:     s3AccessKey,
-		SecretAccessKey: s3SecretKey,
-		UsePathStyle:    true,
-		CacheKey:        imageName,
+	// Configure the cache backend from hive.yml (example matches hack/docker-compose.yml garage service)
+	cacheSelection, err := cache.NewSelection(project.Config.Cache, project.RootDir)
+	if err != nil {
+		return err
+	}
+	if project.Config.Cache != nil {
+		log.Printf("%s cache configured", project.Config.Cache.Type)
+	}
+
+	export, err := newImageExport(project.Config.Export, distPath)
+	if err != nil {
+		return err
+	}
+	labeler := newImageLabeler(ctx, project, export)
+
+	retries, err := newRetryPolicies(project.Config.Retry)
+	if err != nil {
+		return err
 	}
-	log.Printf("S3 cache configured: endpoint=%s, bucket=%s", s3Endpoint, s3Bucket)
+	report := &runReport{}
+	reportFile := filepath.Join(reportDir, "run-report.json")
+	defer func() {
+		if err := report.write(reportFile, redactor); err != nil {
+			log.Printf("Warning: Failed to write run report: %v", err)
+		}
+	}()
 
 	// Step: Build images according to DAG
 	if graph.HasDependencies() {
 		reg := registry.NewRegistry()
 		if err := reg.Start(ctx); err != nil {
-			log.Fatalf("Failed to start registry: %v", err)
+			return fmt.Errorf("failed to start registry: %w", err)
 		}
 		defer reg.Stop(ctx)
 		log.Printf("Registry started: local=%v address=%s", reg.IsLocal(), reg.Address())
@@ -249,49 +451,87 @@ func main() {
 				continue
 			}
 
+			compression, err := buildkit.NewCompression(project.Config.Compression, imageDef.Compression)
+			if err != nil {
+				return fmt.Errorf("invalid compression for %s: %w", imgName, err)
+			}
+
+			sourceDateEpoch, err := resolveSourceDateEpoch(ctx, project, imageDef)
+			if err != nil {
+				return err
+			}
+			imagePlatforms := buildPlatforms(project, imageDef)
+
 			// Build all tags for this image
 			for tagName := range imageDef.Tags {
 				// Find the rendered Dockerfile path - format is distPath/imageName/tagName/Dockerfile
 				dockerfilePath := filepath.Join(distPath, imgName, tagName, "Dockerfile")
 				if _, err := os.Stat(dockerfilePath); os.IsNotExist(err) {
-					log.Fatalf("Dockerfile not found for %s:%s at %s", imgName, tagName, dockerfilePath)
+					return fmt.Errorf("dockerfile not found for %s:%s at %s", imgName, tagName, dockerfilePath)
 				}
 
 				// Patch hive from container ref
-				patchedPath, cleanup := patchHiveRefs(dockerfilePath, reg.Address())
+				patchedPath, cleanup, err := patchHiveRefs(dockerfilePath, reg.Address())
+				if err != nil {
+					return err
+				}
 				defer cleanup()
 
 				// Build the image
 				root, _ := filepath.Abs(filepath.Dir(patchedPath))
 				imageTag := fmt.Sprintf("%s:%s", imgName, tagName)
-				tf := tarFilePath(distPath, imgName, tagName)
-				build_args, err := buildconfig_resolver.
+				usedAsBase := len(graph.Dependents(imgName)) > 0
+				target, err := export.forTag(distPath, imgName, tagName, reg, usedAsBase)
+				if err != nil {
+					return err
+				}
+				build_args := buildconfig_resolver.
 					ForTag(imageDef, imageDef.Tags[tagName])
+				frontend, err := buildkit.NewFrontendOptions(imageDef.Build, imageDef.Tags[tagName].Build)
+				if err != nil {
+					return fmt.Errorf("invalid build settings for %s: %w", imageTag, err)
+				}
+				build_secrets, err := build_args.ResolveSecrets(secretResolver)
 				if err != nil {
-					log.Fatalf("Failed to resolve build args for variant %s:%s: %v", imgName, tagName, err)
+					return fmt.Errorf("failed to resolve secrets for %s: %w", imageTag, err)
 				}
 
-				err = bkClient.Build(ctx, &buildkit.BuildOpts{
-					ImageName: imageTag,
-					Platform:  platform,
-					TarFile:   tf,
-					Cache:     s3Cache,
+				labels := labeler.labels(imageDef, tagName, dockerfilePath, sourceDateEpoch, build_args.Labels)
+				cacheImports, cacheExports := cacheSelection.ForImage(imageDef, tagName)
+				result, err := retries.buildImage(ctx, nodes, &buildkit.BuildOpts{
+					ImageName:       imageTag,
+					TarFile:         target.tarFile,
+					LayoutDir:       target.layoutDir(),
+					Push:            target.push,
+					Compression:     compression,
+					SourceDateEpoch: sourceDateEpoch,
+					Frontend:        frontend,
+					CacheImports:    cacheImports,
+					CacheExports:    cacheExports,
 					BuildContext: &build_context.DockerfileBuildContext{
 						Root:       root,
 						Dockerfile: "Dockerfile.patched",
 					},
-					BuildArgs: build_args.ToBuildArgs(),
-					Secrets:   build_args.Secrets,
-				}, newProgressWriter())
+					BuildArgs:   build_args.ToBuildArgs(),
+					Secrets:     build_secrets,
+					SSH:         sshForwards(imageDef),
+					Labels:      labels,
+					Annotations: oci_labels.Annotations(labels),
+				}, imagePlatforms, redactor, report.image(imageTag))
 				if err != nil {
+					// the remaining images are not built after an interrupt
+					if ctx.Err() != nil {
+						return err
+					}
 					log.Printf("Warning: Build failed for %s: %v", imageTag, err)
 					continue
 				}
-				log.Printf("Built %s -> %s", imageTag, tf)
+				labeler.built(imageTag, result)
+				log.Printf("Built %s -> %s", imageTag, target.describe(result))
 
-				generateSBOM(ctx, sbomTool, tf, imageTag)
+				generateSBOM(ctx, sbomTool, redactor, target)
 				testDefs := collectTestDefinitions(filepath.Join(distPath, imgName, tagName))
-				runContainerStructureTests(dockerClient, tf, testDefs, imageTag, reportDir)
+				runContainerStructureTests(dockerClient, redactor, target, testDefs, reportDir)
 
 				// Build all variants for this tag
 				for variantName, variantDef := range imageDef.Variants {
@@ -301,43 +541,70 @@ func main() {
 						continue
 					}
 
-					variantPatchedPath, variantCleanup := patchHiveRefs(variantDockerfilePath, reg.Address())
+					variantPatchedPath, variantCleanup, err := patchHiveRefs(variantDockerfilePath, reg.Address())
+					if err != nil {
+						return err
+					}
 					defer variantCleanup()
 
 					variantRoot, _ := filepath.Abs(filepath.Dir(variantPatchedPath))
 					variantTag := fmt.Sprintf("%s:%s%s", imgName, tagName, variantDef.TagSuffix)
-					variantTf := tarFilePath(distPath, imgName, tagName+variantDef.TagSuffix)
+					variantTarget, err := export.forTag(distPath, imgName, tagName+variantDef.TagSuffix, reg, usedAsBase)
+					if err != nil {
+						return err
+					}
 
-					build_args, err := buildconfig_resolver.
+					build_args := buildconfig_resolver.
 						ForTagVariant(imageDef, variantDef, imageDef.Tags[tagName])
+					frontend, err := buildkit.NewFrontendOptions(imageDef.Build, imageDef.Tags[tagName].Build, variantDef.Build)
+					if err != nil {
+						return fmt.Errorf("invalid build settings for variant %s:%s:%s: %w", imgName, tagName, variantName, err)
+					}
+					build_secrets, err := build_args.ResolveSecrets(secretResolver)
 					if err != nil {
-						log.Fatalf("Failed to resolve build args for variant %s:%s:%s: %v", imgName, tagName, variantName, err)
+						return fmt.Errorf("failed to resolve secrets for variant %s:%s:%s: %w", imgName, tagName, variantName, err)
 					}
 
-					err = bkClient.Build(ctx, &buildkit.BuildOpts{
-						ImageName: variantTag,
-						Platform:  platform,
-						TarFile:   variantTf,
-						Cache:     s3Cache,
+					variantLabels := labeler.labels(imageDef, tagName+variantDef.TagSuffix, variantDockerfilePath, sourceDateEpoch, build_args.Labels)
+					cacheImports, cacheExports := cacheSelection.ForImage(imageDef, tagName+variantDef.TagSuffix)
+					variantResult, err := retries.buildImage(ctx, nodes, &buildkit.BuildOpts{
+						ImageName:       variantTag,
+						TarFile:         variantTarget.tarFile,
+						LayoutDir:       variantTarget.layoutDir(),
+						Push:            variantTarget.push,
+						Compression:     compression,
+						SourceDateEpoch: sourceDateEpoch,
+						Frontend:        frontend,
+						CacheImports:    cacheImports,
+						CacheExports:    cacheExports,
 						BuildContext: &build_context.DockerfileBuildContext{
 							Root:       variantRoot,
 							Dockerfile: "Dockerfile.patched",
 						},
-						BuildArgs: build_args.ToBuildArgs(),
-					}, newProgressWriter())
+						BuildArgs:   build_args.ToBuildArgs(),
+						Secrets:     build_secrets,
+						SSH:         sshForwards(imageDef),
+						Labels:      variantLabels,
+						Annotations: oci_labels.Annotations(variantLabels),
+					}, imagePlatforms, redactor, report.image(variantTag))
 					if err != nil {
+						// the remaining images are not built after an interrupt
+						if ctx.Err() != nil {
+							return err
+						}
 						log.Printf("Warning: Build failed for variant %s: %v", variantTag, err)
 						continue
 					}
-					log.Printf("Built variant %s -> %s", variantTag, variantTf)
+					labeler.built(variantTag, variantResult)
+					log.Printf("Built variant %s -> %s", variantTag, variantTarget.describe(variantResult))
 
-					generateSBOM(ctx, sbomTool, variantTf, variantTag)
+					generateSBOM(ctx, sbomTool, redactor, variantTarget)
 					variantTestDefs := collectTestDefinitions(filepath.Join(distPath, imgName, tagName+variantDef.TagSuffix))
-					runContainerStructureTests(dockerClient, variantTf, variantTestDefs, variantTag, reportDir)
+					runContainerStructureTests(dockerClient, redactor, variantTarget, variantTestDefs, reportDir)
 
-					// Push variant to local registry if other images depend on it
-					if deps := graph.Dependents(imgName); len(deps) > 0 {
-						if err := reg.Push(ctx, imgName, tagName+variantDef.TagSuffix, variantTf); err != nil {
+					// Push variant to local registry if other images depend on it and BuildKit did not push it already
+					if usedAsBase && !export.pushesToStaging() {
+						if err := retries.stage(ctx, variantTarget, reg, imgName, tagName+variantDef.TagSuffix, report.image(variantTag)); err != nil {
 							log.Printf("Warning: Failed to push variant %s to registry: %v", variantTag, err)
 						} else {
 							log.Printf("Pushed variant %s to local registry", variantTag)
@@ -345,9 +612,9 @@ func main() {
 					}
 				}
 
-				// Push to local registry if other images depend on it
-				if deps := graph.Dependents(imgName); len(deps) > 0 {
-					if err := reg.Push(ctx, imgName, tagName, tf); err != nil {
+				// Push to local registry if other images depend on it and BuildKit did not push it already
+				if usedAsBase && !export.pushesToStaging() {
+					if err := retries.stage(ctx, target, reg, imgName, tagName, report.image(imageTag)); err != nil {
 						log.Printf("Warning: Failed to push %s:%s to registry: %v", imgName, tagName, err)
 					} else {
 						log.Printf("Pushed %s:%s to local registry", imgName, tagName)
@@ -363,6 +630,17 @@ func main() {
 			for _, imageDef := range images {
 				log.Printf("Building image: %s", imageDef.Name)
 
+				compression, err := buildkit.NewCompression(project.Config.Compression, imageDef.Compression)
+				if err != nil {
+					return fmt.Errorf("invalid compression for %s: %w", imageDef.Name, err)
+				}
+
+				sourceDateEpoch, err := resolveSourceDateEpoch(ctx, project, imageDef)
+				if err != nil {
+					return err
+				}
+				imagePlatforms := buildPlatforms(project, imageDef)
+
 				// Build all tags for this image
 				for tagName := range imageDef.Tags {
 					// Find the rendered Dockerfile path - format is distPath/imageName/tagName/Dockerfile
@@ -374,28 +652,54 @@ func main() {
 
 					// Build the image
 					imageTag := fmt.Sprintf("%s:%s", imageDef.Name, tagName)
-					tf := tarFilePath(distPath, imageDef.Name, tagName)
+					target, err := export.forTag(distPath, imageDef.Name, tagName, nil, false)
+					if err != nil {
+						return err
+					}
+					build_args := buildconfig_resolver.
+						ForTag(imageDef, imageDef.Tags[tagName])
+					frontend, err := buildkit.NewFrontendOptions(imageDef.Build, imageDef.Tags[tagName].Build)
+					if err != nil {
+						return fmt.Errorf("invalid build settings for %s: %w", imageTag, err)
+					}
+					build_secrets, err := build_args.ResolveSecrets(secretResolver)
+					if err != nil {
+						return fmt.Errorf("failed to resolve secrets for %s: %w", imageTag, err)
+					}
 
-					err = bkClient.Build(ctx, &buildkit.BuildOpts{
-						ImageName: imageTag,
-						Platform:  platform,
-						TarFile:   tf,
-						Cache:     s3Cache,
+					labels := labeler.labels(imageDef, tagName, dockerfilePath, sourceDateEpoch, build_args.Labels)
+					cacheImports, cacheExports := cacheSelection.ForImage(imageDef, tagName)
+					result, err := retries.buildImage(ctx, nodes, &buildkit.BuildOpts{
+						ImageName:       imageTag,
+						TarFile:         target.tarFile,
+						LayoutDir:       target.layoutDir(),
+						Push:            target.push,
+						Compression:     compression,
+						SourceDateEpoch: sourceDateEpoch,
+						Frontend:        frontend,
+						CacheImports:    cacheImports,
+						CacheExports:    cacheExports,
 						BuildContext: &build_context.DockerfileBuildContext{
 							Root: filepath.Dir(dockerfilePath),
 						},
-					}, newProgressWriter())
+						BuildArgs:   build_args.ToBuildArgs(),
+						Secrets:     build_secrets,
+						SSH:         sshForwards(imageDef),
+						Labels:      labels,
+						Annotations: oci_labels.Annotations(labels),
+					}, imagePlatforms, redactor, report.image(imageTag))
 					if err != nil {
-						log.Fatalf("Build failed for %s: %v", imageTag, err)
+						return fmt.Errorf("build failed for %s: %w", imageTag, err)
 					}
-					log.Printf("Built %s -> %s", imageTag, tf)
+					log.Printf("Built %s -> %s", imageTag, target.describe(result))
 
-					generateSBOM(ctx, sbomTool, tf, imageTag)
+					generateSBOM(ctx, sbomTool, redactor, target)
 					testDefs := collectTestDefinitions(filepath.Join(distPath, imageDef.Name, tagName))
-					runContainerStructureTests(dockerClient, tf, testDefs, imageTag, reportDir)
+					runContainerStructureTests(dockerClient, redactor, target, testDefs, reportDir)
 				}
 			}
 		}
 	}
 
+	return nil
 }
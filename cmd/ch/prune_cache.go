@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/timo-reymann/ContainerHive/internal/buildkit/cache"
+	"github.com/timo-reymann/ContainerHive/pkg/discovery"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// pruneCache enforces the size and age limits of the local build cache, restricted to the selected images if any.
+// Limits passed as flags take precedence over the ones configured in hive.yml.
+func pruneCache(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("prune-cache", flag.ContinueOnError)
+	projectDir := flags.String("project", ".", "Project directory containing the hive.yml")
+	maxSize := flags.String("max-size", "", "Maximum total size of the cache, e.g. 10GB")
+	maxAge := flags.String("max-age", "", "Maximum age of unused cache entries, e.g. 7d")
+	selector := registerSelectionFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	project, err := discovery.DiscoverProject(ctx, *projectDir)
+	if err != nil {
+		return err
+	}
+
+	cacheConfig := project.Config.Cache
+	if cacheConfig == nil || cacheConfig.Type != "local" {
+		return errors.New("prune-cache requires a local cache to be configured in hive.yml")
+	}
+
+	limits := model.LocalCacheConfig{}
+	if cacheConfig.Local != nil {
+		limits = *cacheConfig.Local
+	}
+	if *maxSize != "" {
+		limits.MaxSize = *maxSize
+	}
+	if *maxAge != "" {
+		limits.MaxAge = *maxAge
+	}
+
+	opts, err := cache.NewPruneOptions(&limits)
+	if err != nil {
+		return err
+	}
+	if !selector.IsEmpty() {
+		selected, err := selectProject(selector, project)
+		if err != nil {
+			return err
+		}
+		opts.Images = slices.Sorted(maps.Keys(selected.ImagesByName))
+	}
+
+	root, err := cache.LocalCacheRoot(cacheConfig.Local, project.RootDir)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Pruning local cache in %s", root)
+	result, err := cache.PruneLocal(root, opts, time.Now())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed %d cache entries and %d unreferenced blobs, freed %d bytes, %d bytes remaining\n",
+		result.RemovedEntries, result.RemovedBlobs, result.FreedBytes, result.RemainingBytes)
+	return nil
+}
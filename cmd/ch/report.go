@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/timo-reymann/ContainerHive/internal/retry"
+	"github.com/timo-reymann/ContainerHive/internal/secrets"
+)
+
+// runReport summarizes the outcome of all builds of a run, including retried attempts
+type runReport struct {
+	Images []*imageReport `json:"images"`
+}
+
+type imageReport struct {
+	Image         string          `json:"image"`
+	Digest        string          `json:"digest,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	BuildAttempts []retry.Attempt `json:"build_attempts,omitempty"`
+	PushAttempts  []retry.Attempt `json:"push_attempts,omitempty"`
+}
+
+// image returns the report of the image tag, adding it if it is not reported yet
+func (r *runReport) image(imageTag string) *imageReport {
+	for _, image := range r.Images {
+		if image.Image == imageTag {
+			return image
+		}
+	}
+	image := &imageReport{Image: imageTag}
+	r.Images = append(r.Images, image)
+	return image
+}
+
+// failed records the error the image failed with
+func (i *imageReport) failed(err error) {
+	i.Error = err.Error()
+}
+
+// write saves the report as JSON, masking resolved secrets that ended up in error messages
+func (r *runReport) write(path string, redactor *secrets.Redactor) error {
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, redactor.RedactBytes(content), 0644)
+}
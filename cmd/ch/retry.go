@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/timo-reymann/ContainerHive/internal/buildkit"
+	"github.com/timo-reymann/ContainerHive/internal/registry"
+	"github.com/timo-reymann/ContainerHive/internal/retry"
+	"github.com/timo-reymann/ContainerHive/internal/secrets"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// retryPolicies are the retry policies for builds and pushes
+type retryPolicies struct {
+	build retry.Policy
+	push  retry.Policy
+}
+
+func newRetryPolicies(config *model.RetryConfig) (*retryPolicies, error) {
+	if config == nil {
+		config = &model.RetryConfig{}
+	}
+	build, err := retry.NewPolicy(config.Build)
+	if err != nil {
+		return nil, errors.Join(errors.New("invalid build retry policy"), err)
+	}
+	push, err := retry.NewPolicy(config.Push)
+	if err != nil {
+		return nil, errors.Join(errors.New("invalid push retry policy"), err)
+	}
+	return &retryPolicies{build: build, push: push}, nil
+}
+
+// buildImage builds the image, retrying transient failures, and records the attempts in the report
+func (p *retryPolicies) buildImage(ctx context.Context, nodes *buildkit.Nodes, opts *buildkit.BuildOpts, buildPlatforms []string, redactor *secrets.Redactor, report *imageReport) (*buildkit.BuildResult, error) {
+	var result *buildkit.BuildResult
+	attempts, err := p.build.Do(ctx, "Build of "+opts.ImageName, func(ctx context.Context) error {
+		var err error
+		// every attempt needs its own progress display
+		result, err = nodes.Build(ctx, opts, buildPlatforms, newProgressWriter(redactor))
+		return err
+	})
+	report.BuildAttempts = attempts
+	if err != nil {
+		report.failed(err)
+		return nil, err
+	}
+	report.Digest = result.Digest
+	return result, nil
+}
+
+// stage pushes the image to the staging registry, retrying transient failures, and records the attempts in the report
+func (p *retryPolicies) stage(ctx context.Context, target *exportTarget, staging registry.Registry, name, tag string, report *imageReport) error {
+	attempts, err := p.push.Do(ctx, "Push of "+name+":"+tag, func(ctx context.Context) error {
+		return target.stage(ctx, staging, name, tag)
+	})
+	report.PushAttempts = attempts
+	if err != nil {
+		report.failed(err)
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/timo-reymann/ContainerHive/internal/dependency"
+	"github.com/timo-reymann/ContainerHive/internal/selection"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// listFlag collects the values of a flag that can be repeated or passed comma separated
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(val string) error {
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+func registerSelectionFlags(flags *flag.FlagSet) *selection.Selector {
+	selector := &selection.Selector{}
+	flags.Var((*listFlag)(&selector.Images), "image", "Glob of image names to select, can be repeated")
+	flags.Var((*listFlag)(&selector.Tags), "tag", "Glob of tag names to select, can be repeated")
+	flags.Var((*listFlag)(&selector.Variants), "variant", "Glob of variant names to select, can be repeated")
+	flags.Var((*listFlag)(&selector.Labels), "label", "Label selector key=value, key!=value or key to select images by, can be repeated")
+	flags.BoolVar(&selector.WithDependencies, "with-dependencies", false, "Also select the images the selected images are based on")
+	flags.BoolVar(&selector.WithDependents, "with-dependents", false, "Also select the images based on the selected images")
+	return selector
+}
+
+// selectProject restricts the project to the selected images before anything is rendered. The dependency graph of
+// the whole project is only scanned if the selection is expanded with dependencies or dependents.
+func selectProject(selector *selection.Selector, project *model.ContainerHiveProject) (*model.ContainerHiveProject, error) {
+	if selector.IsEmpty() {
+		return project, nil
+	}
+
+	var graph *dependency.Graph
+	if selector.WithDependencies || selector.WithDependents {
+		scannedGraph, err := dependency.ScanProject(project)
+		if err != nil {
+			return nil, fmt.Errorf("dependency scanning failed: %w", err)
+		}
+		if graph, err = dependency.BuildDependencyGraph(scannedGraph, project); err != nil {
+			return nil, fmt.Errorf("dependency graph construction failed: %w", err)
+		}
+	}
+
+	selected, err := selector.Apply(project, graph)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Selected %d image(s)", len(selected.ImagesByIdentifier))
+	return selected, nil
+}
+
+// selectionGraph builds the dependency graph of the selected images, depends_on is resolved against the whole project.
+// Base images outside the selection fail the run, as they are only resolvable when built in the same run.
+func selectionGraph(project, selected *model.ContainerHiveProject) (*dependency.Graph, error) {
+	scannedGraph, err := dependency.ScanProject(selected)
+	if err != nil {
+		return nil, fmt.Errorf("dependency scanning failed: %w", err)
+	}
+	graph, err := dependency.BuildDependencyGraph(scannedGraph, project)
+	if err != nil {
+		return nil, fmt.Errorf("dependency graph construction failed: %w", err)
+	}
+
+	var names []string
+	var missing []error
+	for _, name := range slices.Sorted(maps.Keys(selected.ImagesByName)) {
+		names = append(names, name)
+		// dependencies can be both referenced in the Dockerfile and declared with depends_on
+		for _, dependencyName := range slices.Compact(slices.Sorted(slices.Values(graph.Dependencies(name)))) {
+			if _, ok := selected.ImagesByName[dependencyName]; !ok {
+				missing = append(missing, fmt.Errorf("%s is based on %s, which is not selected, use -with-dependencies to build it as well", name, dependencyName))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.Join(missing...)
+	}
+	return graph.Subgraph(names), nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/timo-reymann/ContainerHive/internal/validation"
+	"github.com/timo-reymann/ContainerHive/pkg/discovery"
+)
+
+// validationResult is the machine-readable output of the validate command
+type validationResult struct {
+	Valid    bool               `json:"valid"`
+	Problems discovery.Problems `json:"problems"`
+}
+
+// validate checks the project against the schemas and runs the semantic checks without building anything.
+// With a selection only the selected images are checked.
+// Problems are printed as file:line:column: message, or as JSON for editors and CI.
+func validate(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	projectDir := flags.String("project", ".", "Project directory containing the hive.yml")
+	format := flags.String("format", "text", "Output format, text or json")
+	selector := registerSelectionFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unsupported output format %s, must be text or json", *format)
+	}
+
+	// discovery problems prevent the semantic checks, as the project is incomplete
+	var problems discovery.Problems
+	project, err := discovery.DiscoverProject(ctx, *projectDir)
+	if err != nil && !errors.As(err, &problems) {
+		return err
+	}
+	if err == nil {
+		if err := configureSecrets(project); err != nil {
+			return err
+		}
+		selected, err := selectProject(selector, project)
+		if err != nil {
+			return err
+		}
+		if problems, err = validation.Validate(project, selected); err != nil {
+			return err
+		}
+	}
+
+	if err := writeValidationResult(os.Stdout, *format, problems); err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("validation failed with %d problem(s)", len(problems))
+	}
+	return nil
+}
+
+func writeValidationResult(w io.Writer, format string, problems discovery.Problems) error {
+	if format == "json" {
+		if problems == nil {
+			problems = discovery.Problems{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(validationResult{Valid: len(problems) == 0, Problems: problems})
+	}
+
+	for _, problem := range problems {
+		if _, err := fmt.Fprintln(w, problem.Error()); err != nil {
+			return err
+		}
+	}
+	if len(problems) == 0 {
+		_, err := fmt.Fprintln(w, "Project is valid")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/timo-reymann/ContainerHive/internal/buildconfig_resolver"
+	"github.com/timo-reymann/ContainerHive/internal/buildkit"
+	"github.com/timo-reymann/ContainerHive/internal/buildkit/build_context"
+	"github.com/timo-reymann/ContainerHive/internal/oci_labels"
+	"github.com/timo-reymann/ContainerHive/internal/oci_layout"
+	"github.com/timo-reymann/ContainerHive/internal/reproducible"
+	"github.com/timo-reymann/ContainerHive/internal/secrets"
+	"github.com/timo-reymann/ContainerHive/pkg/discovery"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+	"github.com/timo-reymann/ContainerHive/pkg/rendering"
+)
+
+// verifyTarget is a tag or variant of an image that is verified
+type verifyTarget struct {
+	image   *model.Image
+	tag     *model.Tag
+	variant *model.ImageVariant
+}
+
+// verifyTargets returns the selected tags and variants ordered by image identifier, tag and variant name
+func verifyTargets(project *model.ContainerHiveProject) []verifyTarget {
+	var targets []verifyTarget
+	for _, identifier := range slices.Sorted(maps.Keys(project.ImagesByIdentifier)) {
+		imageDef := project.ImagesByIdentifier[identifier]
+		for _, tagName := range slices.Sorted(maps.Keys(imageDef.Tags)) {
+			tag := imageDef.Tags[tagName]
+			targets = append(targets, verifyTarget{image: imageDef, tag: tag})
+			for _, variantName := range slices.Sorted(maps.Keys(imageDef.Variants)) {
+				targets = append(targets, verifyTarget{image: imageDef, tag: tag, variant: imageDef.Variants[variantName]})
+			}
+		}
+	}
+	return targets
+}
+
+// verifyReproducible builds the selected image tags and variants twice without cache and compares the resulting manifests.
+func verifyReproducible(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("verify-reproducible", flag.ContinueOnError)
+	projectDir := flags.String("project", ".", "Project directory containing the hive.yml")
+	selector := registerSelectionFlags(flags)
+	bkFlags := registerBuildkitFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if selector.IsEmpty() {
+		return errors.New("verify-reproducible requires a selection, e.g. -image and -tag")
+	}
+
+	project, err := discovery.DiscoverProject(ctx, *projectDir)
+	if err != nil {
+		return err
+	}
+	if err := configureSecrets(project); err != nil {
+		return err
+	}
+
+	selected, err := selectProject(selector, project)
+	if err != nil {
+		return err
+	}
+
+	distPath, err := os.MkdirTemp("", "verify-reproducible-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(distPath)
+
+	if err := rendering.RenderProject(ctx, selected, distPath); err != nil {
+		return err
+	}
+
+	secretResolver := secrets.NewRunResolver()
+	redactor := secretResolver.Redactor()
+	log.SetOutput(redactor.Writer(os.Stderr))
+
+	bkClient, stopDaemon, err := bkFlags.connect(ctx, project.Config.Buildkit, project.RootDir, requestedEntitlements(selected))
+	if err != nil {
+		return err
+	}
+	defer stopDaemon()
+	defer bkClient.Close()
+
+	var failed []error
+	for _, target := range verifyTargets(selected) {
+		if err := verifyTargetReproducible(ctx, project, target, distPath, bkClient, secretResolver, redactor); err != nil {
+			failed = append(failed, err)
+		}
+	}
+	return errors.Join(failed...)
+}
+
+// verifyTargetReproducible builds the tag or variant twice and prints the differences if the builds do not match
+func verifyTargetReproducible(ctx context.Context, project *model.ContainerHiveProject, target verifyTarget, distPath string, bkClient *buildkit.Client, secretResolver *secrets.RunResolver, redactor *secrets.Redactor) error {
+	imageDef := target.image
+	buildValues := buildconfig_resolver.ForTag(imageDef, target.tag)
+	buildConfigs := []*model.BuildConfig{imageDef.Build, target.tag.Build}
+	renderedTag := target.tag.Name
+	if target.variant != nil {
+		buildValues = buildconfig_resolver.ForTagVariant(imageDef, target.variant, target.tag)
+		buildConfigs = append(buildConfigs, target.variant.Build)
+		renderedTag += target.variant.TagSuffix
+	}
+	imageTag := imageDef.Name + ":" + renderedTag
+
+	frontend, err := buildkit.NewFrontendOptions(buildConfigs...)
+	if err != nil {
+		return err
+	}
+
+	buildRoot := filepath.Join(distPath, imageDef.Name, renderedTag)
+	dockerfile, err := os.ReadFile(filepath.Join(buildRoot, "Dockerfile"))
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed to read rendered Dockerfile of %s", imageTag), err)
+	}
+	if strings.Contains(string(dockerfile), "__hive__/") {
+		return fmt.Errorf("verifying %s is not supported yet, it is based on another image of the project", imageTag)
+	}
+
+	buildSecrets, err := buildValues.ResolveSecrets(secretResolver)
+	if err != nil {
+		return err
+	}
+
+	compression, err := buildkit.NewCompression(project.Config.Compression, imageDef.Compression)
+	if err != nil {
+		return err
+	}
+	sourceDateEpoch, err := resolveSourceDateEpoch(ctx, project, imageDef)
+	if err != nil {
+		return err
+	}
+
+	labels := newImageLabeler(ctx, project, nil).labels(imageDef, renderedTag, filepath.Join(buildRoot, "Dockerfile"), sourceDateEpoch, buildValues.Labels)
+	var images [2]v1.Image
+	for i := range images {
+		log.Printf("Building %s (%d/%d) ...", imageTag, i+1, len(images))
+		tarFile := filepath.Join(distPath, fmt.Sprintf("build-%d.tar", i))
+		_, err := bkClient.Build(ctx, &buildkit.BuildOpts{
+			ImageName:       imageTag,
+			Platform:        platform,
+			TarFile:         tarFile,
+			Compression:     compression,
+			SourceDateEpoch: sourceDateEpoch,
+			Frontend:        frontend,
+			// a cached second build would trivially match the first one
+			NoCache: true,
+			BuildContext: &build_context.DockerfileBuildContext{
+				Root: buildRoot,
+			},
+			BuildArgs:   buildValues.ToBuildArgs(),
+			Secrets:     buildSecrets,
+			SSH:         sshForwards(imageDef),
+			Labels:      labels,
+			Annotations: oci_labels.Annotations(labels),
+		}, newProgressWriter(redactor))
+		if err != nil {
+			return errors.Join(fmt.Errorf("build %d of %s failed", i+1, imageTag), err)
+		}
+
+		img, _, cleanup, err := oci_layout.ImageFromTar(tarFile)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		images[i] = img
+	}
+
+	comparison, err := reproducible.Compare(images[0], images[1])
+	if err != nil {
+		return err
+	}
+	if comparison.Reproducible() {
+		fmt.Printf("%s is reproducible: %s\n", imageTag, comparison.DigestA)
+		return nil
+	}
+
+	fmt.Printf("%s is not reproducible: %s != %s\n", imageTag, comparison.DigestA, comparison.DigestB)
+	if comparison.ConfigDigestA != comparison.ConfigDigestB {
+		fmt.Printf("  config differs: %s != %s\n", comparison.ConfigDigestA, comparison.ConfigDigestB)
+	}
+	for _, layer := range comparison.Layers {
+		fmt.Printf("  layer %d differs: %s != %s\n", layer.Index, orMissing(layer.DigestA), orMissing(layer.DigestB))
+	}
+	return fmt.Errorf("%s is not reproducible", imageTag)
+}
+
+func orMissing(digest string) string {
+	if digest == "" {
+		return "<missing>"
+	}
+	return digest
+}
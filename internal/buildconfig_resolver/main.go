@@ -10,14 +10,41 @@ import (
 type ResolvedBuildValues struct {
 	BuildArgs model.BuildArgs
 	Versions  model.Versions
-	Secrets   map[string][]byte
+	// Secrets contains the merged secret definitions, values are only resolved using ResolveSecrets
+	Secrets model.Secrets
+	// Labels contains the merged user defined labels, nil if none are defined
+	Labels model.Labels
 }
 
-func ForTag(image *model.Image, tag *model.Tag) (*ResolvedBuildValues, error) {
+// mergeSecrets merges the given secret definitions, later ones overriding earlier ones with the same name.
+func mergeSecrets(definitions ...model.Secrets) model.Secrets {
+	merged := make(model.Secrets)
+	for _, definition := range definitions {
+		for k, secret := range definition {
+			merged[k] = secret
+		}
+	}
+	return merged
+}
+
+// mergeLabels merges the given labels, later ones overriding earlier ones with the same key.
+func mergeLabels(definitions ...model.Labels) model.Labels {
+	var merged model.Labels
+	for _, definition := range definitions {
+		for k, v := range definition {
+			if merged == nil {
+				merged = make(model.Labels)
+			}
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func mergeTag(image *model.Image, tag *model.Tag) *ResolvedBuildValues {
 	resolved := &ResolvedBuildValues{
 		BuildArgs: tag.BuildArgs,
 		Versions:  image.Versions,
-		Secrets:   make(map[string][]byte),
 	}
 
 	if resolved.Versions == nil {
@@ -36,23 +63,22 @@ func ForTag(image *model.Image, tag *model.Tag) (*ResolvedBuildValues, error) {
 		resolved.BuildArgs[k] = v
 	}
 
-	// Resolve secrets
-	for k, secret := range image.Secrets {
-		resolvedValue, err := secrets.Resolve(secret.SourceType, secret.Value)
-		if err != nil {
-			return nil, fmt.Errorf("failed to resolve secret '%s': %w", k, err)
-		}
-		resolved.Secrets[k] = []byte(resolvedValue)
-	}
+	return resolved
+}
 
-	return resolved, nil
+// ForTag resolves the build values for a tag of an image.
+// Secrets and labels defined on the tag override image ones with the same name.
+func ForTag(image *model.Image, tag *model.Tag) *ResolvedBuildValues {
+	resolved := mergeTag(image, tag)
+	resolved.Secrets = mergeSecrets(image.Secrets, tag.Secrets)
+	resolved.Labels = mergeLabels(image.Labels, tag.Labels)
+	return resolved
 }
 
-func ForTagVariant(image *model.Image, variant *model.ImageVariant, tag *model.Tag) (*ResolvedBuildValues, error) {
-	resolved, err := ForTag(image, tag)
-	if err != nil {
-		return nil, err
-	}
+// ForTagVariant resolves the build values for a variant of an image tag.
+// Secrets and labels defined on the variant override tag and image ones with the same name.
+func ForTagVariant(image *model.Image, variant *model.ImageVariant, tag *model.Tag) *ResolvedBuildValues {
+	resolved := mergeTag(image, tag)
 
 	for k, v := range variant.Versions {
 		resolved.Versions[k] = v
@@ -62,6 +88,22 @@ func ForTagVariant(image *model.Image, variant *model.ImageVariant, tag *model.T
 		resolved.BuildArgs[k] = v
 	}
 
+	resolved.Secrets = mergeSecrets(image.Secrets, tag.Secrets, variant.Secrets)
+	resolved.Labels = mergeLabels(image.Labels, tag.Labels, variant.Labels)
+	return resolved
+}
+
+// ResolveSecrets resolves the values of the merged secret definitions using the run scoped resolver.
+func (r *ResolvedBuildValues) ResolveSecrets(resolver *secrets.RunResolver) (map[string][]byte, error) {
+	resolved := make(map[string][]byte, len(r.Secrets))
+	for k, secret := range r.Secrets {
+		resolvedValue, err := resolver.Resolve(secret.SourceType, secret.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve secret '%s': %w", k, err)
+		}
+		resolved[k] = []byte(resolvedValue)
+	}
+
 	return resolved, nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/timo-reymann/ContainerHive/internal/secrets"
 	"github.com/timo-reymann/ContainerHive/pkg/model"
 )
 
@@ -19,7 +20,7 @@ func TestForTag(t *testing.T) {
 			expected: &ResolvedBuildValues{
 				BuildArgs: model.BuildArgs{},
 				Versions:  model.Versions{},
-				Secrets:   map[string][]byte{},
+				Secrets:   model.Secrets{},
 			},
 		},
 		"image with versions only": {
@@ -36,7 +37,7 @@ func TestForTag(t *testing.T) {
 					"python": "3.11",
 					"pip":    "23.0",
 				},
-				Secrets: map[string][]byte{},
+				Secrets: model.Secrets{},
 			},
 		},
 		"image with build args only": {
@@ -53,7 +54,7 @@ func TestForTag(t *testing.T) {
 					"WORKDIR":    "/app",
 				},
 				Versions: model.Versions{},
-				Secrets:  map[string][]byte{},
+				Secrets:  model.Secrets{},
 			},
 		},
 		"tag with versions only": {
@@ -68,7 +69,7 @@ func TestForTag(t *testing.T) {
 				Versions: model.Versions{
 					"node": "20.0.0",
 				},
-				Secrets: map[string][]byte{},
+				Secrets: model.Secrets{},
 			},
 		},
 		"tag with build args only": {
@@ -83,7 +84,7 @@ func TestForTag(t *testing.T) {
 					"BUILD_TYPE": "release",
 				},
 				Versions: model.Versions{},
-				Secrets:  map[string][]byte{},
+				Secrets:  model.Secrets{},
 			},
 		},
 		"tag versions override image versions": {
@@ -104,7 +105,7 @@ func TestForTag(t *testing.T) {
 					"python": "3.11", // overridden by tag
 					"pip":    "22.0", // from image
 				},
-				Secrets: map[string][]byte{},
+				Secrets: model.Secrets{},
 			},
 		},
 		"image build args override tag build args": {
@@ -125,7 +126,7 @@ func TestForTag(t *testing.T) {
 					"EXTRA_ARG":  "value",       // from image
 				},
 				Versions: model.Versions{},
-				Secrets:  map[string][]byte{},
+				Secrets:  model.Secrets{},
 			},
 		},
 		"complex merge scenario": {
@@ -160,17 +161,35 @@ func TestForTag(t *testing.T) {
 					"poetry": "1.5.0", // from image
 					"pip":    "23.0",  // from tag
 				},
-				Secrets: map[string][]byte{},
+				Secrets: model.Secrets{},
+			},
+		},
+		"tag secrets override image secrets": {
+			image: &model.Image{
+				Secrets: model.Secrets{
+					"token":   model.Secret{SourceType: "plain", Value: "image-token"},
+					"api_key": model.Secret{SourceType: "plain", Value: "image-key"},
+				},
+			},
+			tag: &model.Tag{
+				Secrets: model.Secrets{
+					"token": model.Secret{SourceType: "plain", Value: "tag-token"},
+				},
+			},
+			expected: &ResolvedBuildValues{
+				BuildArgs: model.BuildArgs{},
+				Versions:  model.Versions{},
+				Secrets: model.Secrets{
+					"token":   model.Secret{SourceType: "plain", Value: "tag-token"}, // tag overrides image
+					"api_key": model.Secret{SourceType: "plain", Value: "image-key"}, // from image
+				},
 			},
 		},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			got, err := ForTag(tc.image, tc.tag)
-			if err != nil {
-				t.Fatalf("ForTag() unexpected error: %v", err)
-			}
+			got := ForTag(tc.image, tc.tag)
 
 			if diff := cmp.Diff(tc.expected, got); diff != "" {
 				t.Errorf("ForTag() mismatch (-expected +got):\n%s", diff)
@@ -193,7 +212,7 @@ func TestForTagVariant(t *testing.T) {
 			expected: &ResolvedBuildValues{
 				BuildArgs: model.BuildArgs{},
 				Versions:  model.Versions{},
-				Secrets:   map[string][]byte{},
+				Secrets:   model.Secrets{},
 			},
 		},
 		"variant with versions only": {
@@ -209,7 +228,7 @@ func TestForTagVariant(t *testing.T) {
 				Versions: model.Versions{
 					"nodejs": "20.0.0",
 				},
-				Secrets: map[string][]byte{},
+				Secrets: model.Secrets{},
 			},
 		},
 		"variant with build args only": {
@@ -225,7 +244,7 @@ func TestForTagVariant(t *testing.T) {
 					"VARIANT_ARG": "value",
 				},
 				Versions: model.Versions{},
-				Secrets:  map[string][]byte{},
+				Secrets:  model.Secrets{},
 			},
 		},
 		"variant versions override tag and image versions": {
@@ -253,7 +272,7 @@ func TestForTagVariant(t *testing.T) {
 					"poetry": "1.5.0",  // from image
 					"nodejs": "20.0.0", // from variant
 				},
-				Secrets: map[string][]byte{},
+				Secrets: model.Secrets{},
 			},
 		},
 		"variant build args override image build args": {
@@ -281,7 +300,7 @@ func TestForTagVariant(t *testing.T) {
 					"VARIANT_ARG": "value",        // from variant
 				},
 				Versions: model.Versions{},
-				Secrets:  map[string][]byte{},
+				Secrets:  model.Secrets{},
 			},
 		},
 		"complex three-way merge": {
@@ -332,17 +351,74 @@ func TestForTagVariant(t *testing.T) {
 					"go":     "1.21",   // from tag
 					"nodejs": "20.0.0", // from variant
 				},
-				Secrets: map[string][]byte{},
+				Secrets: model.Secrets{},
+			},
+		},
+		"variant secrets override tag and image secrets": {
+			image: &model.Image{
+				Secrets: model.Secrets{
+					"token":   model.Secret{SourceType: "plain", Value: "image-token"},
+					"api_key": model.Secret{SourceType: "plain", Value: "image-key"},
+					"npmrc":   model.Secret{SourceType: "plain", Value: "image-npmrc"},
+				},
+			},
+			variant: &model.ImageVariant{
+				Secrets: model.Secrets{
+					"token":    model.Secret{SourceType: "plain", Value: "variant-token"},
+					"registry": model.Secret{SourceType: "plain", Value: "variant-registry"},
+				},
+			},
+			tag: &model.Tag{
+				Secrets: model.Secrets{
+					"token":   model.Secret{SourceType: "plain", Value: "tag-token"},
+					"api_key": model.Secret{SourceType: "plain", Value: "tag-key"},
+				},
+			},
+			expected: &ResolvedBuildValues{
+				BuildArgs: model.BuildArgs{},
+				Versions:  model.Versions{},
+				Secrets: model.Secrets{
+					"token":    model.Secret{SourceType: "plain", Value: "variant-token"},    // variant overrides all
+					"api_key":  model.Secret{SourceType: "plain", Value: "tag-key"},          // tag overrides image
+					"npmrc":    model.Secret{SourceType: "plain", Value: "image-npmrc"},      // from image
+					"registry": model.Secret{SourceType: "plain", Value: "variant-registry"}, // from variant
+				},
+			},
+		},
+		"variant labels override tag and image labels": {
+			image: &model.Image{
+				Labels: model.Labels{
+					"maintainer": "image",
+					"team":       "platform",
+				},
+			},
+			variant: &model.ImageVariant{
+				Labels: model.Labels{
+					"maintainer": "variant",
+				},
+			},
+			tag: &model.Tag{
+				Labels: model.Labels{
+					"maintainer": "tag",
+					"channel":    "stable",
+				},
+			},
+			expected: &ResolvedBuildValues{
+				BuildArgs: model.BuildArgs{},
+				Versions:  model.Versions{},
+				Secrets:   model.Secrets{},
+				Labels: model.Labels{
+					"maintainer": "variant",
+					"team":       "platform",
+					"channel":    "stable",
+				},
 			},
 		},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			got, err := ForTagVariant(tc.image, tc.variant, tc.tag)
-			if err != nil {
-				t.Fatalf("ForTagVariant() unexpected error: %v", err)
-			}
+			got := ForTagVariant(tc.image, tc.variant, tc.tag)
 
 			if diff := cmp.Diff(tc.expected, got); diff != "" {
 				t.Errorf("ForTagVariant() mismatch (-expected +got):\n%s", diff)
@@ -467,3 +543,75 @@ func TestToBuildArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestResolvedBuildValues_ResolveSecrets(t *testing.T) {
+	t.Setenv("BUILDCONFIG_RESOLVER_TOKEN", "env-token")
+
+	tests := map[string]struct {
+		resolved    *ResolvedBuildValues
+		expected    map[string][]byte
+		expectError bool
+	}{
+		"no secrets": {
+			resolved: &ResolvedBuildValues{Secrets: model.Secrets{}},
+			expected: map[string][]byte{},
+		},
+		"plain secrets": {
+			resolved: &ResolvedBuildValues{
+				Secrets: model.Secrets{
+					"token":   model.Secret{SourceType: "plain", Value: "tag-token"},
+					"api_key": model.Secret{SourceType: "plain", Value: "image-key"},
+				},
+			},
+			expected: map[string][]byte{
+				"token":   []byte("tag-token"),
+				"api_key": []byte("image-key"),
+			},
+		},
+		"env secret given by name": {
+			resolved: &ResolvedBuildValues{
+				Secrets: model.Secrets{
+					"token": model.Secret{SourceType: "env", Value: "BUILDCONFIG_RESOLVER_TOKEN"},
+				},
+			},
+			expected: map[string][]byte{
+				"token": []byte("env-token"),
+			},
+		},
+		"unset env secret given by name": {
+			resolved: &ResolvedBuildValues{
+				Secrets: model.Secrets{
+					"token": model.Secret{SourceType: "env", Value: "BUILDCONFIG_RESOLVER_UNSET"},
+				},
+			},
+			expectError: true,
+		},
+		"unresolvable secret": {
+			resolved: &ResolvedBuildValues{
+				Secrets: model.Secrets{
+					"token": model.Secret{SourceType: "unknown", Value: "value"},
+				},
+			},
+			expectError: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.resolved.ResolveSecrets(secrets.NewRunResolver())
+			if tc.expectError {
+				if err == nil {
+					t.Fatal("ResolveSecrets() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveSecrets() unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("ResolveSecrets() mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package cache
+
+type AzBlobCache struct {
+	AccountUrl      string
+	SecretAccessKey string
+	CacheKey        string
+}
+
+func (a *AzBlobCache) Name() string {
+	return "azblob"
+}
+
+func (a *AzBlobCache) ToAttributes() map[string]string {
+	attrs := map[string]string{
+		"account_url": a.AccountUrl,
+		"name":        a.CacheKey,
+		"mode":        "max",
+	}
+	if a.SecretAccessKey != "" {
+		attrs["secret_access_key"] = a.SecretAccessKey
+	}
+	return attrs
+}
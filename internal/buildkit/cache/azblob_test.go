@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAzBlobCache_ToAttributes(t *testing.T) {
+	tests := map[string]struct {
+		cache    *AzBlobCache
+		expected map[string]string
+	}{
+		"without secret": {
+			cache: &AzBlobCache{AccountUrl: "https://account.blob.core.windows.net", CacheKey: "app-1.0"},
+			expected: map[string]string{
+				"account_url": "https://account.blob.core.windows.net",
+				"name":        "app-1.0",
+				"mode":        "max",
+			},
+		},
+		"with secret": {
+			cache: &AzBlobCache{AccountUrl: "https://account.blob.core.windows.net", SecretAccessKey: "key", CacheKey: "app-1.0"},
+			expected: map[string]string{
+				"account_url":       "https://account.blob.core.windows.net",
+				"name":              "app-1.0",
+				"mode":              "max",
+				"secret_access_key": "key",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tc.cache.Name() != "azblob" {
+				t.Errorf("Name() = %q, want %q", tc.cache.Name(), "azblob")
+			}
+			if diff := cmp.Diff(tc.expected, tc.cache.ToAttributes()); diff != "" {
+				t.Errorf("ToAttributes() mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// entryFunc creates the cache entry for a cache key of an image
+type entryFunc func(image, key string) BuildkitCache
+
+// backend creates the cache entries for a cache key, importing and exporting might need different entries.
+// A nil entry means the backend can not be used in that direction.
+type backend struct {
+	importEntry entryFunc
+	exportEntry entryFunc
+}
+
+func sameEntry(entry entryFunc) *backend {
+	return &backend{importEntry: entry, exportEntry: entry}
+}
+
+func envOrDefault(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func newBackend(config *model.CacheConfig, projectRoot string) (*backend, error) {
+	switch config.Type {
+	case "s3":
+		c := config.S3
+		if c == nil || c.Bucket == "" {
+			return nil, errors.New("s3 cache requires a bucket")
+		}
+		accessKeyId := envOrDefault("AWS_ACCESS_KEY_ID", c.AccessKeyId)
+		secretAccessKey := envOrDefault("AWS_SECRET_ACCESS_KEY", c.SecretAccessKey)
+		return sameEntry(func(_, key string) BuildkitCache {
+			return &S3BuildKitCache{
+				EndpointUrl:     c.EndpointUrl,
+				Bucket:          c.Bucket,
+				Region:          c.Region,
+				AccessKeyId:     accessKeyId,
+				SecretAccessKey: secretAccessKey,
+				UsePathStyle:    c.UsePathStyle,
+				CacheKey:        key,
+			}
+		}), nil
+	case "registry":
+		c := config.Registry
+		if c == nil || c.Repository == "" {
+			return nil, errors.New("registry cache requires a repository")
+		}
+		return sameEntry(func(_, key string) BuildkitCache {
+			return &RegistryCache{CacheRef: c.Repository + ":" + key, Insecure: c.Insecure}
+		}), nil
+	case "local":
+		dir, err := LocalCacheRoot(config.Local, projectRoot)
+		if err != nil {
+			return nil, err
+		}
+		return &backend{
+			importEntry: func(image, key string) BuildkitCache {
+				src := filepath.Join(dir, image, key)
+				// importing a cache that has not been exported yet fails the build
+				if _, err := os.Stat(filepath.Join(src, "index.json")); err != nil {
+					return nil
+				}
+				return &LocalCache{Src: src}
+			},
+			exportEntry: func(image, key string) BuildkitCache {
+				return &LocalCache{Dest: filepath.Join(dir, image, key)}
+			},
+		}, nil
+	case "inline":
+		c := config.Inline
+		return &backend{
+			importEntry: func(_, key string) BuildkitCache {
+				if c == nil || c.Repository == "" {
+					return nil
+				}
+				return &RegistryCache{CacheRef: c.Repository + ":" + key}
+			},
+			exportEntry: func(string, string) BuildkitCache {
+				return &InlineCache{}
+			},
+		}, nil
+	case "azblob":
+		c := config.AzBlob
+		if c == nil || c.AccountUrl == "" {
+			return nil, errors.New("azblob cache requires an account_url")
+		}
+		secretAccessKey := envOrDefault("AZURE_STORAGE_ACCOUNT_KEY", c.SecretAccessKey)
+		return sameEntry(func(_, key string) BuildkitCache {
+			return &AzBlobCache{AccountUrl: c.AccountUrl, SecretAccessKey: secretAccessKey, CacheKey: key}
+		}), nil
+	case "gha":
+		c := config.GHA
+		if c == nil {
+			c = &model.GHACacheConfig{}
+		}
+		url := envOrDefault("ACTIONS_CACHE_URL", c.Url)
+		token := envOrDefault("ACTIONS_RUNTIME_TOKEN", c.Token)
+		if url == "" || token == "" {
+			return nil, errors.New("gha cache requires ACTIONS_CACHE_URL and ACTIONS_RUNTIME_TOKEN, which are only available in GitHub Actions")
+		}
+		return sameEntry(func(_, key string) BuildkitCache {
+			return &GHACache{Url: url, Token: token, Scope: key}
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported cache type '%s', must be one of s3, registry, local, inline, azblob, gha", config.Type)
+	}
+}
+
+// Selection holds the configured cache backend and the scopes caches are imported from and exported to
+type Selection struct {
+	backend    *backend
+	importFrom []string
+	exportTo   []string
+}
+
+// NewSelection creates the cache selection from the project configuration.
+// It returns nil when no cache is configured, which disables caching.
+func NewSelection(config *model.CacheConfig, projectRoot string) (*Selection, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	b, err := newBackend(config, projectRoot)
+	if err != nil {
+		return nil, errors.Join(errors.New("invalid cache configuration"), err)
+	}
+
+	return &Selection{
+		backend:    b,
+		importFrom: expandScopes(config.ImportFrom),
+		exportTo:   expandScopes(config.ExportTo),
+	}, nil
+}
+
+// expandScopes expands environment variables and removes duplicates,
+// scopes that are empty after expansion refer to the unscoped cache
+func expandScopes(scopes []string) []string {
+	if len(scopes) == 0 {
+		return []string{""}
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	expanded := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = sanitizeKey(strings.TrimSpace(os.ExpandEnv(scope)))
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		expanded = append(expanded, scope)
+	}
+	return expanded
+}
+
+var invalidKeyChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// maxKeyLength is the maximum length of a tag in an OCI registry
+const maxKeyLength = 128
+
+func sanitizeKey(key string) string {
+	key = strings.Trim(invalidKeyChars.ReplaceAllString(key, "-"), "-.")
+	if len(key) > maxKeyLength {
+		key = key[:maxKeyLength]
+	}
+	return key
+}
+
+// keyHashLength is the number of hex characters of the hash appended to each key
+const keyHashLength = 12
+
+// Key derives the cache key for a tag of an image, tag includes the suffix for variants.
+// The key is usable as registry tag, file name and object name. A hash of the unmodified scope, image and tag is
+// appended, so keys stay unique when the names are ambiguous after joining, sanitizing or truncating.
+func Key(scope, image, tag string) string {
+	key := image + "-" + tag
+	if scope != "" {
+		key = scope + "-" + key
+	}
+
+	sum := sha256.Sum256([]byte(scope + "\x00" + image + "\x00" + tag))
+	hash := hex.EncodeToString(sum[:])[:keyHashLength]
+
+	key = sanitizeKey(key)
+	if len(key) > maxKeyLength-keyHashLength-1 {
+		key = strings.TrimRight(key[:maxKeyLength-keyHashLength-1], "-.")
+	}
+	return key + "-" + hash
+}
+
+// ForImage returns the caches to import from and export to when building the tag of an image.
+// No caches are returned when caching is not configured or disabled for the image.
+func (s *Selection) ForImage(image *model.Image, tag string) (imports []BuildkitCache, exports []BuildkitCache) {
+	if s == nil || (image.Cache != nil && image.Cache.Disabled) {
+		return nil, nil
+	}
+
+	for _, scope := range s.importFrom {
+		if entry := s.backend.importEntry(image.Name, Key(scope, image.Name, tag)); entry != nil {
+			imports = append(imports, entry)
+		}
+	}
+
+	for _, scope := range s.exportTo {
+		if entry := s.backend.exportEntry(image.Name, Key(scope, image.Name, tag)); entry != nil {
+			exports = append(exports, entry)
+		}
+	}
+
+	return imports, exports
+}
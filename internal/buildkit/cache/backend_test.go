@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+func TestKey(t *testing.T) {
+	tests := map[string]struct {
+		scope    string
+		image    string
+		tag      string
+		expected string
+	}{
+		"unscoped":             {image: "python", tag: "3.13", expected: "python-3.13-36a8e3b700a1"},
+		"scoped":               {scope: "main", image: "python", tag: "3.13", expected: "main-python-3.13-ebd14665f98a"},
+		"variant suffix":       {image: "dotnet", tag: "8.0-node", expected: "dotnet-8.0-node-acf2fde58543"},
+		"invalid characters":   {scope: "feature/cache keys", image: "python", tag: "3.13+1", expected: "feature-cache-keys-python-3.13-1-d41e78c38e2b"},
+		"truncated to max tag": {image: strings.Repeat("a", 200), tag: "1", expected: strings.Repeat("a", 115) + "-9f45e00490af"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := Key(tc.scope, tc.image, tc.tag); got != tc.expected {
+				t.Errorf("Key() = %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestKey_Unique(t *testing.T) {
+	tests := map[string]struct {
+		a [3]string
+		b [3]string
+	}{
+		"image and tag split":   {a: [3]string{"", "a-b", "c"}, b: [3]string{"", "a", "b-c"}},
+		"scope and image split": {a: [3]string{"main-a", "b", "1"}, b: [3]string{"main", "a-b", "1"}},
+		"sanitized characters":  {a: [3]string{"", "python", "3.13+1"}, b: [3]string{"", "python", "3.13-1"}},
+		"truncated":             {a: [3]string{"", strings.Repeat("a", 200), "1"}, b: [3]string{"", strings.Repeat("a", 200), "2"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := Key(tc.a[0], tc.a[1], tc.a[2])
+			b := Key(tc.b[0], tc.b[1], tc.b[2])
+			if a == b {
+				t.Errorf("Key() = %q for both %v and %v", a, tc.a, tc.b)
+			}
+			if len(a) > maxKeyLength {
+				t.Errorf("Key() = %q exceeds %d characters", a, maxKeyLength)
+			}
+		})
+	}
+}
+
+func TestNewSelection_Errors(t *testing.T) {
+	t.Setenv("ACTIONS_CACHE_URL", "")
+	t.Setenv("ACTIONS_RUNTIME_TOKEN", "")
+
+	tests := map[string]struct {
+		config        *model.CacheConfig
+		errorContains string
+	}{
+		"unknown type":         {config: &model.CacheConfig{Type: "redis"}, errorContains: "unsupported cache type 'redis'"},
+		"s3 without bucket":    {config: &model.CacheConfig{Type: "s3"}, errorContains: "requires a bucket"},
+		"registry without ref": {config: &model.CacheConfig{Type: "registry"}, errorContains: "requires a repository"},
+		"azblob without url":   {config: &model.CacheConfig{Type: "azblob"}, errorContains: "requires an account_url"},
+		"gha outside actions":  {config: &model.CacheConfig{Type: "gha"}, errorContains: "requires ACTIONS_CACHE_URL"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSelection(tc.config, "/project")
+			if err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tc.errorContains) {
+				t.Errorf("expected error containing %q, got %q", tc.errorContains, err.Error())
+			}
+		})
+	}
+}
+
+func TestSelection_ForImage(t *testing.T) {
+	t.Setenv("BRANCH", "feature-x")
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("ACTIONS_CACHE_URL", "https://cache.example.com/")
+	t.Setenv("ACTIONS_RUNTIME_TOKEN", "token")
+
+	projectRoot := t.TempDir()
+	exportedKey := filepath.Join(projectRoot, ".cache", "python", "main-python-3.13-ebd14665f98a")
+	if err := os.MkdirAll(exportedKey, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(exportedKey, "index.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	image := &model.Image{Name: "python"}
+
+	tests := map[string]struct {
+		config          *model.CacheConfig
+		image           *model.Image
+		expectedImports []BuildkitCache
+		expectedExports []BuildkitCache
+	}{
+		"not configured": {
+			image: image,
+		},
+		"disabled for image": {
+			config: &model.CacheConfig{Type: "registry", Registry: &model.RegistryCacheConfig{Repository: "ghcr.io/org/cache"}},
+			image:  &model.Image{Name: "python", Cache: &model.ImageCacheConfig{Disabled: true}},
+		},
+		"s3 unscoped": {
+			config: &model.CacheConfig{Type: "s3", S3: &model.S3CacheConfig{Bucket: "cache", Region: "eu-central-1"}},
+			image:  image,
+			expectedImports: []BuildkitCache{
+				&S3BuildKitCache{Bucket: "cache", Region: "eu-central-1", CacheKey: "python-3.13-36a8e3b700a1"},
+			},
+			expectedExports: []BuildkitCache{
+				&S3BuildKitCache{Bucket: "cache", Region: "eu-central-1", CacheKey: "python-3.13-36a8e3b700a1"},
+			},
+		},
+		"registry import from main and branch, export to branch": {
+			config: &model.CacheConfig{
+				Type:       "registry",
+				Registry:   &model.RegistryCacheConfig{Repository: "ghcr.io/org/cache"},
+				ImportFrom: []string{"main", "${BRANCH}", "main"},
+				ExportTo:   []string{"${BRANCH}"},
+			},
+			image: image,
+			expectedImports: []BuildkitCache{
+				&RegistryCache{CacheRef: "ghcr.io/org/cache:main-python-3.13-ebd14665f98a"},
+				&RegistryCache{CacheRef: "ghcr.io/org/cache:feature-x-python-3.13-f58f09901cbb"},
+			},
+			expectedExports: []BuildkitCache{
+				&RegistryCache{CacheRef: "ghcr.io/org/cache:feature-x-python-3.13-f58f09901cbb"},
+			},
+		},
+		"local skips missing imports": {
+			config: &model.CacheConfig{
+				Type:       "local",
+				Local:      &model.LocalCacheConfig{Dir: ".cache"},
+				ImportFrom: []string{"main", "${BRANCH}"},
+				ExportTo:   []string{"${BRANCH}"},
+			},
+			image: image,
+			expectedImports: []BuildkitCache{
+				&LocalCache{Src: exportedKey},
+			},
+			expectedExports: []BuildkitCache{
+				&LocalCache{Dest: filepath.Join(projectRoot, ".cache", "python", "feature-x-python-3.13-f58f09901cbb")},
+			},
+		},
+		"inline": {
+			config: &model.CacheConfig{Type: "inline", Inline: &model.InlineCacheConfig{Repository: "ghcr.io/org/python"}},
+			image:  image,
+			expectedImports: []BuildkitCache{
+				&RegistryCache{CacheRef: "ghcr.io/org/python:python-3.13-36a8e3b700a1"},
+			},
+			expectedExports: []BuildkitCache{
+				&InlineCache{},
+			},
+		},
+		"gha from environment": {
+			config: &model.CacheConfig{Type: "gha"},
+			image:  image,
+			expectedImports: []BuildkitCache{
+				&GHACache{Url: "https://cache.example.com/", Token: "token", Scope: "python-3.13-36a8e3b700a1"},
+			},
+			expectedExports: []BuildkitCache{
+				&GHACache{Url: "https://cache.example.com/", Token: "token", Scope: "python-3.13-36a8e3b700a1"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			selection, err := NewSelection(tc.config, projectRoot)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			imports, exports := selection.ForImage(tc.image, "3.13")
+			if diff := cmp.Diff(tc.expectedImports, imports); diff != "" {
+				t.Errorf("imports mismatch (-expected +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedExports, exports); diff != "" {
+				t.Errorf("exports mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
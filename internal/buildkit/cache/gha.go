@@ -0,0 +1,25 @@
+package cache
+
+type GHACache struct {
+	Url   string
+	Token string
+	Scope string
+}
+
+func (g *GHACache) Name() string {
+	return "gha"
+}
+
+func (g *GHACache) ToAttributes() map[string]string {
+	attrs := map[string]string{
+		"scope": g.Scope,
+		"mode":  "max",
+	}
+	if g.Url != "" {
+		attrs["url"] = g.Url
+	}
+	if g.Token != "" {
+		attrs["token"] = g.Token
+	}
+	return attrs
+}
@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGHACache_ToAttributes(t *testing.T) {
+	cache := &GHACache{Url: "https://cache.example.com/", Token: "token", Scope: "app-1.0"}
+
+	expected := map[string]string{
+		"url":   "https://cache.example.com/",
+		"token": "token",
+		"scope": "app-1.0",
+		"mode":  "max",
+	}
+
+	if cache.Name() != "gha" {
+		t.Errorf("Name() = %q, want %q", cache.Name(), "gha")
+	}
+	if diff := cmp.Diff(expected, cache.ToAttributes()); diff != "" {
+		t.Errorf("ToAttributes() mismatch (-expected +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,13 @@
+package cache
+
+// InlineCache embeds the cache metadata into the built image, it can only be exported.
+// Importing is done from the pushed image using RegistryCache.
+type InlineCache struct{}
+
+func (i *InlineCache) Name() string {
+	return "inline"
+}
+
+func (i *InlineCache) ToAttributes() map[string]string {
+	return map[string]string{}
+}
@@ -0,0 +1,24 @@
+package cache
+
+// LocalCache stores the cache in a directory on the client.
+// Src is used when importing, Dest when exporting.
+type LocalCache struct {
+	Src  string
+	Dest string
+}
+
+func (l *LocalCache) Name() string {
+	return "local"
+}
+
+func (l *LocalCache) ToAttributes() map[string]string {
+	attrs := map[string]string{}
+	if l.Src != "" {
+		attrs["src"] = l.Src
+	}
+	if l.Dest != "" {
+		attrs["dest"] = l.Dest
+		attrs["mode"] = "max"
+	}
+	return attrs
+}
@@ -0,0 +1,358 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// maxParsedBlobSize limits which blobs are inspected for references, layers are never parsed
+const maxParsedBlobSize = 4 << 20
+
+// PruneOptions limits the local cache, zero values disable the limit
+type PruneOptions struct {
+	MaxSize int64
+	MaxAge  time.Duration
+	// Images restricts pruning to the entries of these images, the size limit only applies to their entries
+	Images []string
+}
+
+// ParseAge parses durations, additionally supporting days like 7d
+func ParseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		count, err := strconv.ParseFloat(days, 64)
+		if err != nil || count < 0 {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+		return time.Duration(count * float64(24*time.Hour)), nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age %q", value)
+	}
+	return age, nil
+}
+
+// NewPruneOptions parses the limits configured for the local cache
+func NewPruneOptions(config *model.LocalCacheConfig) (PruneOptions, error) {
+	opts := PruneOptions{}
+	if config == nil {
+		return opts, nil
+	}
+
+	if config.MaxSize != "" {
+		// sizes with binary units like GiB are parsed as powers of 1024, all others as powers of 1000
+		parseSize := units.FromHumanSize
+		if strings.Contains(strings.ToLower(config.MaxSize), "ib") {
+			parseSize = units.RAMInBytes
+		}
+		size, err := parseSize(strings.TrimSpace(config.MaxSize))
+		if err != nil || size < 0 {
+			return opts, fmt.Errorf("invalid size %q", config.MaxSize)
+		}
+		opts.MaxSize = size
+	}
+
+	if config.MaxAge != "" {
+		age, err := ParseAge(config.MaxAge)
+		if err != nil {
+			return opts, err
+		}
+		opts.MaxAge = age
+	}
+
+	return opts, nil
+}
+
+type PruneResult struct {
+	RemovedEntries int
+	RemovedBlobs   int
+	FreedBytes     int64
+	RemainingBytes int64
+}
+
+type localCacheEntry struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// PruneLocal prunes the local cache stored in root.
+// Blobs no longer referenced by an entry are removed first, afterward entries unused for longer than MaxAge
+// and, starting with the least recently exported, entries exceeding MaxSize are removed.
+func PruneLocal(root string, opts PruneOptions, now time.Time) (*PruneResult, error) {
+	result := &PruneResult{}
+
+	entries, err := listLocalCacheEntries(root, opts.Images)
+	if err != nil {
+		return nil, err
+	}
+
+	var remaining []*localCacheEntry
+	for _, entry := range entries {
+		removedBlobs, freed, err := removeUnreferencedBlobs(entry.path)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to prune blobs of cache entry %q", entry.path), err)
+		}
+		result.RemovedBlobs += removedBlobs
+		result.FreedBytes += freed
+		entry.size -= freed
+
+		if opts.MaxAge > 0 && now.Sub(entry.modTime) > opts.MaxAge {
+			if err := removeLocalCacheEntry(entry, result); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		remaining = append(remaining, entry)
+	}
+
+	var total int64
+	for _, entry := range remaining {
+		total += entry.size
+	}
+
+	if opts.MaxSize > 0 {
+		sort.Slice(remaining, func(i, j int) bool {
+			return remaining[i].modTime.Before(remaining[j].modTime)
+		})
+		for len(remaining) > 0 && total > opts.MaxSize {
+			if err := removeLocalCacheEntry(remaining[0], result); err != nil {
+				return nil, err
+			}
+			total -= remaining[0].size
+			remaining = remaining[1:]
+		}
+	}
+
+	result.RemainingBytes = total
+	removeEmptyImageDirs(root)
+
+	return result, nil
+}
+
+// listLocalCacheEntries lists the cache entries stored as <root>/<image>/<key>, all images if none are given
+func listLocalCacheEntries(root string, imageNames []string) ([]*localCacheEntry, error) {
+	images, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Join(fmt.Errorf("failed to read local cache dir %q", root), err)
+	}
+
+	var entries []*localCacheEntry
+	for _, image := range images {
+		if !image.IsDir() || (len(imageNames) > 0 && !slices.Contains(imageNames, image.Name())) {
+			continue
+		}
+
+		keys, err := os.ReadDir(filepath.Join(root, image.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if !key.IsDir() {
+				continue
+			}
+			entry, err := newLocalCacheEntry(filepath.Join(root, image.Name(), key.Name()))
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func newLocalCacheEntry(path string) (*localCacheEntry, error) {
+	entry := &localCacheEntry{path: path}
+
+	// index.json is rewritten on every export, incomplete exports fall back to the directory
+	info, err := os.Stat(filepath.Join(path, "index.json"))
+	if err != nil {
+		info, err = os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	entry.modTime = info.ModTime()
+
+	err = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entry.size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to determine size of cache entry %q", path), err)
+	}
+
+	return entry, nil
+}
+
+func removeLocalCacheEntry(entry *localCacheEntry, result *PruneResult) error {
+	if err := os.RemoveAll(entry.path); err != nil {
+		return errors.Join(fmt.Errorf("failed to remove cache entry %q", entry.path), err)
+	}
+	result.RemovedEntries++
+	result.FreedBytes += entry.size
+	return nil
+}
+
+func removeEmptyImageDirs(root string) {
+	images, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	for _, image := range images {
+		// Remove only succeeds for empty directories
+		_ = os.Remove(filepath.Join(root, image.Name()))
+	}
+}
+
+func blobPath(entryPath, digest string) (string, bool) {
+	algorithm, hex, ok := strings.Cut(digest, ":")
+	if !ok || algorithm == "" || hex == "" || strings.ContainsAny(digest, `/\`) {
+		return "", false
+	}
+	return filepath.Join(entryPath, "blobs", algorithm, hex), true
+}
+
+// collectDigests adds all digests referenced in a JSON document
+func collectDigests(value any, digests map[string]bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if digest, ok := child.(string); ok && key == "digest" {
+				digests[digest] = true
+				continue
+			}
+			collectDigests(child, digests)
+		}
+	case []any:
+		for _, child := range v {
+			collectDigests(child, digests)
+		}
+	}
+}
+
+// referencedBlobs walks all blobs reachable from the index of an entry
+func referencedBlobs(entryPath string) (map[string]bool, error) {
+	index, err := os.ReadFile(filepath.Join(entryPath, "index.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed any
+	if err := json.Unmarshal(index, &parsed); err != nil {
+		return nil, errors.Join(errors.New("malformed index.json"), err)
+	}
+
+	referenced := make(map[string]bool)
+	pending := make(map[string]bool)
+	collectDigests(parsed, pending)
+
+	for len(pending) > 0 {
+		next := make(map[string]bool)
+		for digest := range pending {
+			if referenced[digest] {
+				continue
+			}
+			referenced[digest] = true
+
+			path, ok := blobPath(entryPath, digest)
+			if !ok {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil || info.Size() > maxParsedBlobSize {
+				continue
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			var blob any
+			if json.Unmarshal(content, &blob) == nil {
+				collectDigests(blob, next)
+			}
+		}
+		pending = next
+	}
+
+	return referenced, nil
+}
+
+// removeUnreferencedBlobs removes blobs no longer referenced by the index of the entry.
+// Blobs newer than the index might belong to an export in progress and are kept.
+func removeUnreferencedBlobs(entryPath string) (int, int64, error) {
+	indexInfo, err := os.Stat(filepath.Join(entryPath, "index.json"))
+	if err != nil {
+		return 0, 0, nil
+	}
+
+	referenced, err := referencedBlobs(entryPath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	blobsDir := filepath.Join(entryPath, "blobs")
+	algorithms, err := os.ReadDir(blobsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, 0, nil
+		}
+		return 0, 0, err
+	}
+
+	removed := 0
+	var freed int64
+	for _, algorithm := range algorithms {
+		if !algorithm.IsDir() {
+			continue
+		}
+		blobs, err := os.ReadDir(filepath.Join(blobsDir, algorithm.Name()))
+		if err != nil {
+			return removed, freed, err
+		}
+		for _, blob := range blobs {
+			if referenced[algorithm.Name()+":"+blob.Name()] {
+				continue
+			}
+			info, err := blob.Info()
+			if err != nil {
+				return removed, freed, err
+			}
+			if info.ModTime().After(indexInfo.ModTime()) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(blobsDir, algorithm.Name(), blob.Name())); err != nil {
+				return removed, freed, err
+			}
+			removed++
+			freed += info.Size()
+		}
+	}
+
+	return removed, freed, nil
+}
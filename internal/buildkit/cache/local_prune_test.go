@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+func TestNewPruneOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        *model.LocalCacheConfig
+		expected      PruneOptions
+		errorContains string
+	}{
+		{name: "not configured"},
+		{name: "plain bytes", config: &model.LocalCacheConfig{MaxSize: "1024"}, expected: PruneOptions{MaxSize: 1024}},
+		{name: "decimal size", config: &model.LocalCacheConfig{MaxSize: "10GB"}, expected: PruneOptions{MaxSize: 10_000_000_000}},
+		{name: "binary size", config: &model.LocalCacheConfig{MaxSize: "1.5 GiB"}, expected: PruneOptions{MaxSize: 1536 << 20}},
+		{name: "age in days", config: &model.LocalCacheConfig{MaxAge: "7d"}, expected: PruneOptions{MaxAge: 7 * 24 * time.Hour}},
+		{name: "age as duration", config: &model.LocalCacheConfig{MaxAge: "36h"}, expected: PruneOptions{MaxAge: 36 * time.Hour}},
+		{name: "invalid size", config: &model.LocalCacheConfig{MaxSize: "lots"}, errorContains: `invalid size "lots"`},
+		{name: "negative age", config: &model.LocalCacheConfig{MaxAge: "-1d"}, errorContains: `invalid age "-1d"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := NewPruneOptions(tt.config)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, opts); diff != "" {
+				t.Errorf("NewPruneOptions() mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+var pruneNow = time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+// writeCacheEntry writes a cache entry with an index, manifest, layer and a stale unreferenced blob
+func writeCacheEntry(t *testing.T, root, image, key string, exportedAt time.Time) string {
+	t.Helper()
+	entry := filepath.Join(root, image, key)
+	blobs := filepath.Join(entry, "blobs", "sha256")
+	if err := os.MkdirAll(blobs, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		filepath.Join(blobs, "manifest"):   `{"layers":[{"digest":"sha256:layer"}]}`,
+		filepath.Join(blobs, "layer"):      strings.Repeat("l", 100),
+		filepath.Join(blobs, "stale"):      strings.Repeat("s", 50),
+		filepath.Join(entry, "index.json"): `{"manifests":[{"digest":"sha256:manifest"}]}`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, exportedAt, exportedAt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return entry
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestPruneLocal_RemovesUnreferencedBlobs(t *testing.T) {
+	root := t.TempDir()
+	entry := writeCacheEntry(t, root, "python", "python-3.13", pruneNow.Add(-time.Hour))
+
+	// blobs written after the index belong to an export in progress
+	inProgress := filepath.Join(entry, "blobs", "sha256", "in-progress")
+	if err := os.WriteFile(inProgress, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := PruneLocal(root, PruneOptions{}, pruneNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.RemovedBlobs != 1 || result.FreedBytes != 50 {
+		t.Errorf("expected 1 removed blob freeing 50 bytes, got %+v", result)
+	}
+	if exists(filepath.Join(entry, "blobs", "sha256", "stale")) {
+		t.Error("expected stale blob to be removed")
+	}
+	for _, blob := range []string{"manifest", "layer", "in-progress"} {
+		if !exists(filepath.Join(entry, "blobs", "sha256", blob)) {
+			t.Errorf("expected blob %q to be kept", blob)
+		}
+	}
+}
+
+func TestPruneLocal_Limits(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            PruneOptions
+		expectedRemoved []string
+		expectedKept    []string
+	}{
+		{
+			name:         "no limits",
+			expectedKept: []string{"python/old", "python/recent", "dotnet/newest"},
+		},
+		{
+			name:            "max age",
+			opts:            PruneOptions{MaxAge: 48 * time.Hour},
+			expectedRemoved: []string{"python/old"},
+			expectedKept:    []string{"python/recent", "dotnet/newest"},
+		},
+		{
+			name:            "max size removes least recently exported first",
+			opts:            PruneOptions{MaxSize: 250},
+			expectedRemoved: []string{"python/old", "python/recent"},
+			expectedKept:    []string{"dotnet/newest"},
+		},
+		{
+			name:            "restricted to images",
+			opts:            PruneOptions{MaxSize: 50, Images: []string{"dotnet"}},
+			expectedRemoved: []string{"dotnet/newest"},
+			expectedKept:    []string{"python/old", "python/recent"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeCacheEntry(t, root, "python", "old", pruneNow.Add(-72*time.Hour))
+			writeCacheEntry(t, root, "python", "recent", pruneNow.Add(-24*time.Hour))
+			writeCacheEntry(t, root, "dotnet", "newest", pruneNow.Add(-time.Hour))
+
+			result, err := PruneLocal(root, tt.opts, pruneNow)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.RemovedEntries != len(tt.expectedRemoved) {
+				t.Errorf("expected %d removed entries, got %d", len(tt.expectedRemoved), result.RemovedEntries)
+			}
+			for _, entry := range tt.expectedRemoved {
+				if exists(filepath.Join(root, entry)) {
+					t.Errorf("expected entry %q to be removed", entry)
+				}
+			}
+			for _, entry := range tt.expectedKept {
+				if !exists(filepath.Join(root, entry)) {
+					t.Errorf("expected entry %q to be kept", entry)
+				}
+			}
+		})
+	}
+}
+
+func TestPruneLocal_RemovesEmptyImageDirs(t *testing.T) {
+	root := t.TempDir()
+	writeCacheEntry(t, root, "python", "old", pruneNow.Add(-72*time.Hour))
+
+	result, err := PruneLocal(root, PruneOptions{MaxAge: time.Hour}, pruneNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.RemainingBytes != 0 {
+		t.Errorf("expected no remaining bytes, got %d", result.RemainingBytes)
+	}
+	if exists(filepath.Join(root, "python")) {
+		t.Error("expected empty image dir to be removed")
+	}
+}
+
+func TestPruneLocal_MissingRoot(t *testing.T) {
+	result, err := PruneLocal(filepath.Join(t.TempDir(), "missing"), PruneOptions{MaxSize: 1}, pruneNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RemovedEntries != 0 {
+		t.Errorf("expected nothing to be removed, got %+v", result)
+	}
+}
@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// LocalCacheRoot returns the root directory of the local cache.
+// Relative directories are resolved against the project root, without a directory the user cache directory is used.
+func LocalCacheRoot(config *model.LocalCacheConfig, projectRoot string) (string, error) {
+	if config == nil || config.Dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", errors.Join(errors.New("failed to determine default local cache dir"), err)
+		}
+		return filepath.Join(userCacheDir, "container-hive", "cache"), nil
+	}
+
+	if filepath.IsAbs(config.Dir) {
+		return config.Dir, nil
+	}
+	return filepath.Join(projectRoot, config.Dir), nil
+}
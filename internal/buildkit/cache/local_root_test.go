@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+func TestLocalCacheRoot(t *testing.T) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		t.Skip("no user cache dir available")
+	}
+
+	tests := map[string]struct {
+		config   *model.LocalCacheConfig
+		expected string
+	}{
+		"not configured": {expected: filepath.Join(userCacheDir, "container-hive", "cache")},
+		"without dir":    {config: &model.LocalCacheConfig{MaxAge: "7d"}, expected: filepath.Join(userCacheDir, "container-hive", "cache")},
+		"relative dir":   {config: &model.LocalCacheConfig{Dir: ".cache"}, expected: filepath.Join("/project", ".cache")},
+		"absolute dir":   {config: &model.LocalCacheConfig{Dir: "/var/cache/hive"}, expected: "/var/cache/hive"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := LocalCacheRoot(tc.config, "/project")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("LocalCacheRoot() = %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLocalCache_ToAttributes(t *testing.T) {
+	tests := map[string]struct {
+		cache    *LocalCache
+		expected map[string]string
+	}{
+		"import": {
+			cache:    &LocalCache{Src: "/cache/app"},
+			expected: map[string]string{"src": "/cache/app"},
+		},
+		"export": {
+			cache:    &LocalCache{Dest: "/cache/app"},
+			expected: map[string]string{"dest": "/cache/app", "mode": "max"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tc.cache.Name() != "local" {
+				t.Errorf("Name() = %q, want %q", tc.cache.Name(), "local")
+			}
+			if diff := cmp.Diff(tc.expected, tc.cache.ToAttributes()); diff != "" {
+				t.Errorf("ToAttributes() mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
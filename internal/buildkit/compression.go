@@ -0,0 +1,93 @@
+package buildkit
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// maxCompressionLevels limits the level per compression type, uncompressed has no level
+var maxCompressionLevels = map[string]int{
+	"gzip":         9,
+	"estargz":      9,
+	"zstd":         22,
+	"uncompressed": -1,
+}
+
+// Compression configures how the layers of exported images are compressed
+type Compression struct {
+	Type  string
+	Level *int
+	Force bool
+}
+
+// NewCompression merges the compression configs, later configs override earlier ones, e.g. project and image.
+// Level and force belong to the type, so they are reset when a later config changes the type without setting them.
+// Returns nil if nothing is configured, leaving the BuildKit default in place.
+func NewCompression(configs ...*model.CompressionConfig) (*Compression, error) {
+	var compression *Compression
+	for _, config := range configs {
+		if config == nil {
+			continue
+		}
+		if compression == nil {
+			compression = &Compression{}
+		}
+		if config.Type != "" && config.Type != compression.Type {
+			compression.Type = config.Type
+			compression.Level = nil
+			compression.Force = false
+		}
+		if config.Level != nil {
+			compression.Level = config.Level
+		}
+		if config.Force != nil {
+			compression.Force = *config.Force
+		}
+	}
+
+	if compression == nil {
+		return nil, nil
+	}
+
+	compressionType := compression.Type
+	if compressionType == "" {
+		compressionType = "gzip"
+	}
+	maxLevel, ok := maxCompressionLevels[compressionType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported compression '%s'", compression.Type)
+	}
+	if compression.Level != nil && (*compression.Level < 0 || *compression.Level > maxLevel) {
+		if maxLevel < 0 {
+			return nil, fmt.Errorf("compression level is not supported for %s", compressionType)
+		}
+		return nil, fmt.Errorf("compression level for %s must be between 0 and %d, got %d", compressionType, maxLevel, *compression.Level)
+	}
+
+	return compression, nil
+}
+
+// ToAttributes returns the exporter attributes for the compression
+func (c *Compression) ToAttributes() map[string]string {
+	attrs := map[string]string{}
+	if c == nil {
+		return attrs
+	}
+
+	if c.Type != "" {
+		attrs["compression"] = c.Type
+	}
+	if c.Level != nil {
+		attrs["compression-level"] = strconv.Itoa(*c.Level)
+	}
+	if c.Force {
+		attrs["force-compression"] = "true"
+	}
+	// zstd and estargz layers are only valid with OCI media types
+	if c.Type == "zstd" || c.Type == "estargz" {
+		attrs["oci-mediatypes"] = "true"
+	}
+	return attrs
+}
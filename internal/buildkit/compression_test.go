@@ -0,0 +1,143 @@
+package buildkit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func TestNewCompression(t *testing.T) {
+	tests := []struct {
+		name          string
+		project       *model.CompressionConfig
+		image         *model.CompressionConfig
+		expected      *Compression
+		errorContains string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:     "project only",
+			project:  &model.CompressionConfig{Type: "zstd", Level: intPtr(3)},
+			expected: &Compression{Type: "zstd", Level: intPtr(3)},
+		},
+		{
+			name:     "image overrides type and resets project level and force",
+			project:  &model.CompressionConfig{Type: "zstd", Level: intPtr(15), Force: boolPtr(true)},
+			image:    &model.CompressionConfig{Type: "gzip"},
+			expected: &Compression{Type: "gzip"},
+		},
+		{
+			name:     "image overrides type with own level and force",
+			project:  &model.CompressionConfig{Type: "zstd", Level: intPtr(15)},
+			image:    &model.CompressionConfig{Type: "gzip", Level: intPtr(6), Force: boolPtr(true)},
+			expected: &Compression{Type: "gzip", Level: intPtr(6), Force: true},
+		},
+		{
+			name:     "image repeats type and keeps project level",
+			project:  &model.CompressionConfig{Type: "zstd", Level: intPtr(15)},
+			image:    &model.CompressionConfig{Type: "zstd", Force: boolPtr(true)},
+			expected: &Compression{Type: "zstd", Level: intPtr(15), Force: true},
+		},
+		{
+			name:     "image disables force",
+			project:  &model.CompressionConfig{Type: "zstd", Force: boolPtr(true)},
+			image:    &model.CompressionConfig{Force: boolPtr(false)},
+			expected: &Compression{Type: "zstd"},
+		},
+		{
+			name:     "level defaults to gzip range",
+			image:    &model.CompressionConfig{Level: intPtr(9)},
+			expected: &Compression{Level: intPtr(9)},
+		},
+		{
+			name:          "unsupported type",
+			project:       &model.CompressionConfig{Type: "brotli"},
+			errorContains: "unsupported compression 'brotli'",
+		},
+		{
+			name:          "level out of range",
+			project:       &model.CompressionConfig{Type: "gzip", Level: intPtr(12)},
+			errorContains: "must be between 0 and 9, got 12",
+		},
+		{
+			name:          "level for uncompressed",
+			project:       &model.CompressionConfig{Type: "uncompressed", Level: intPtr(1)},
+			errorContains: "not supported for uncompressed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compression, err := NewCompression(tt.project, tt.image)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, compression); diff != "" {
+				t.Errorf("NewCompression() mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCompression_ToAttributes(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression *Compression
+		expected    map[string]string
+	}{
+		{
+			name:     "nil",
+			expected: map[string]string{},
+		},
+		{
+			name:        "gzip with level",
+			compression: &Compression{Type: "gzip", Level: intPtr(0)},
+			expected:    map[string]string{"compression": "gzip", "compression-level": "0"},
+		},
+		{
+			name:        "forced zstd",
+			compression: &Compression{Type: "zstd", Level: intPtr(19), Force: true},
+			expected: map[string]string{
+				"compression":       "zstd",
+				"compression-level": "19",
+				"force-compression": "true",
+				"oci-mediatypes":    "true",
+			},
+		},
+		{
+			name:        "estargz",
+			compression: &Compression{Type: "estargz", Force: true},
+			expected: map[string]string{
+				"compression":       "estargz",
+				"force-compression": "true",
+				"oci-mediatypes":    "true",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.expected, tt.compression.ToAttributes()); diff != "" {
+				t.Errorf("ToAttributes() mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package buildkit
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	_ "github.com/moby/buildkit/client/connhelper/dockercontainer" // registers docker-container://
+	_ "github.com/moby/buildkit/client/connhelper/kubepod"         // registers kube-pod://
+)
+
+// DefaultConnectTimeout limits establishing the connection to BuildKit when no timeout is configured
+const DefaultConnectTimeout = 30 * time.Second
+
+// ConnectionConfig for connecting to BuildKit, mirrors the connection options of buildctl
+type ConnectionConfig struct {
+	// Address of the daemon: tcp://<host>:<port>, unix://<socket>, docker-container://<container> or kube-pod://<pod>
+	Address string
+	// CACert is a PEM file used to verify the server certificate, the system pool is used when only other TLS options are set
+	CACert string
+	// Cert and Key are PEM files used for TLS client authentication
+	Cert string
+	Key  string
+	// ServerName to verify the server certificate against, defaults to the host of the address
+	ServerName string
+	// ConnectTimeout limits establishing the connection, defaults to DefaultConnectTimeout
+	ConnectTimeout time.Duration
+}
+
+// WithEnvOverrides returns a copy of the config with values set via BUILDKIT_HOST, BUILDKIT_TLS_CA_CERT,
+// BUILDKIT_TLS_CERT, BUILDKIT_TLS_KEY, BUILDKIT_TLS_SERVER_NAME and BUILDKIT_CONNECT_TIMEOUT taking precedence
+func (c ConnectionConfig) WithEnvOverrides() (ConnectionConfig, error) {
+	overrides := map[string]*string{
+		"BUILDKIT_HOST":            &c.Address,
+		"BUILDKIT_TLS_CA_CERT":     &c.CACert,
+		"BUILDKIT_TLS_CERT":        &c.Cert,
+		"BUILDKIT_TLS_KEY":         &c.Key,
+		"BUILDKIT_TLS_SERVER_NAME": &c.ServerName,
+	}
+	for env, target := range overrides {
+		if val := os.Getenv(env); val != "" {
+			*target = val
+		}
+	}
+
+	if val := os.Getenv("BUILDKIT_CONNECT_TIMEOUT"); val != "" {
+		timeout, err := time.ParseDuration(val)
+		if err != nil {
+			return c, fmt.Errorf("invalid value for BUILDKIT_CONNECT_TIMEOUT: %q", val)
+		}
+		c.ConnectTimeout = timeout
+	}
+
+	return c, nil
+}
+
+func (c ConnectionConfig) usesTLS() bool {
+	return c.CACert != "" || c.Cert != "" || c.Key != "" || c.ServerName != ""
+}
+
+func (c ConnectionConfig) validate() error {
+	if c.Address == "" {
+		return errors.New("no BuildKit address configured")
+	}
+
+	address, err := url.Parse(c.Address)
+	if err != nil {
+		return errors.Join(fmt.Errorf("invalid BuildKit address %q", c.Address), err)
+	}
+	switch address.Scheme {
+	case "tcp":
+	case "unix", "docker-container", "kube-pod":
+		if c.usesTLS() {
+			return fmt.Errorf("TLS is only supported for tcp BuildKit addresses, not %s", address.Scheme)
+		}
+	default:
+		return fmt.Errorf("unsupported BuildKit address scheme %q, expected tcp, unix, docker-container or kube-pod", address.Scheme)
+	}
+
+	if (c.Cert == "") != (c.Key == "") {
+		return errors.New("BuildKit TLS client authentication requires both a certificate and key")
+	}
+	if c.ConnectTimeout < 0 {
+		return errors.New("BuildKit connect timeout must not be negative")
+	}
+	return nil
+}
+
+// clientOpts converts the TLS settings to client options
+func (c ConnectionConfig) clientOpts() []client.ClientOpt {
+	var opts []client.ClientOpt
+	if !c.usesTLS() {
+		return opts
+	}
+
+	if c.CACert != "" {
+		opts = append(opts, client.WithServerConfig(c.ServerName, c.CACert))
+	} else {
+		opts = append(opts, client.WithServerConfigSystem(c.ServerName))
+	}
+	if c.Cert != "" {
+		opts = append(opts, client.WithCredentials(c.Cert, c.Key))
+	}
+	return opts
+}
+
+func (c ConnectionConfig) connectTimeout() time.Duration {
+	if c.ConnectTimeout == 0 {
+		return DefaultConnectTimeout
+	}
+	return c.ConnectTimeout
+}
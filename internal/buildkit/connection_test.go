@@ -0,0 +1,78 @@
+package buildkit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConnectionConfigWithEnvOverrides(t *testing.T) {
+	t.Setenv("BUILDKIT_HOST", "unix:///run/buildkit/buildkitd.sock")
+	t.Setenv("BUILDKIT_TLS_SERVER_NAME", "buildkit.example.com")
+	t.Setenv("BUILDKIT_CONNECT_TIMEOUT", "5s")
+
+	config, err := ConnectionConfig{Address: "tcp://127.0.0.1:8502", CACert: "ca.pem"}.WithEnvOverrides()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := ConnectionConfig{
+		Address:        "unix:///run/buildkit/buildkitd.sock",
+		CACert:         "ca.pem",
+		ServerName:     "buildkit.example.com",
+		ConnectTimeout: 5 * time.Second,
+	}
+	if config != expected {
+		t.Errorf("WithEnvOverrides() = %+v, want %+v", config, expected)
+	}
+
+	t.Setenv("BUILDKIT_CONNECT_TIMEOUT", "soon")
+	if _, err := (ConnectionConfig{}).WithEnvOverrides(); err == nil {
+		t.Error("expected error for invalid timeout")
+	}
+}
+
+func TestConnectionConfigValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        ConnectionConfig
+		errorContains string
+	}{
+		{name: "tcp", config: ConnectionConfig{Address: "tcp://127.0.0.1:8502"}},
+		{name: "tcp with mTLS", config: ConnectionConfig{Address: "tcp://buildkit:1234", CACert: "ca.pem", Cert: "cert.pem", Key: "key.pem"}},
+		{name: "unix socket", config: ConnectionConfig{Address: "unix:///run/buildkit/buildkitd.sock"}},
+		{name: "docker container", config: ConnectionConfig{Address: "docker-container://buildkitd"}},
+		{name: "kubernetes pod", config: ConnectionConfig{Address: "kube-pod://buildkitd-0?namespace=ci"}},
+		{name: "missing address", config: ConnectionConfig{}, errorContains: "no BuildKit address"},
+		{name: "unsupported scheme", config: ConnectionConfig{Address: "http://buildkit"}, errorContains: "unsupported BuildKit address scheme"},
+		{name: "TLS over unix socket", config: ConnectionConfig{Address: "unix:///run/buildkit.sock", CACert: "ca.pem"}, errorContains: "only supported for tcp"},
+		{name: "cert without key", config: ConnectionConfig{Address: "tcp://buildkit:1234", Cert: "cert.pem"}, errorContains: "both a certificate and key"},
+		{name: "negative timeout", config: ConnectionConfig{Address: "tcp://buildkit:1234", ConnectTimeout: -time.Second}, errorContains: "must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.validate()
+			if tt.errorContains == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Fatalf("expected error containing %q, got %v", tt.errorContains, err)
+			}
+		})
+	}
+}
+
+func TestNewClientUnreachable(t *testing.T) {
+	_, err := NewClient(context.Background(), ConnectionConfig{
+		Address:        "unix://" + t.TempDir() + "/buildkitd.sock",
+		ConnectTimeout: time.Second,
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to reach BuildKit") {
+		t.Fatalf("expected unreachable error, got %v", err)
+	}
+}
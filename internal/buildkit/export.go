@@ -0,0 +1,151 @@
+package buildkit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/moby/buildkit/client"
+	"github.com/timo-reymann/ContainerHive/internal/utils"
+)
+
+const (
+	// ExportModeTar writes the image as OCI tar
+	ExportModeTar = "tar"
+	// ExportModeLayout writes the image to an OCI layout directory
+	ExportModeLayout = "layout"
+	// ExportModePush lets BuildKit push the image to the registry directly
+	ExportModePush = "push"
+)
+
+// ExportMode combines the ways an image is exported, parsed from modes joined with +, e.g. layout+push
+type ExportMode struct {
+	Tar    bool
+	Layout bool
+	Push   bool
+}
+
+// PushOpts configures pushing the image from BuildKit using the image exporter
+type PushOpts struct {
+	// Refs are the full image references to push to
+	Refs []string
+	// Insecure allows pushing to registries served via HTTP or with self-signed certificates
+	Insecure bool
+}
+
+// BuildResult contains the outputs of a build
+type BuildResult struct {
+	// Digest of the image manifest
+	Digest string
+}
+
+// ParseExportMode parses the export mode, defaulting to tar only
+func ParseExportMode(mode string) (ExportMode, error) {
+	parsed := ExportMode{}
+	if mode == "" {
+		parsed.Tar = true
+		return parsed, nil
+	}
+
+	for _, part := range strings.Split(mode, "+") {
+		switch part {
+		case ExportModeTar:
+			parsed.Tar = true
+		case ExportModeLayout:
+			parsed.Layout = true
+		case ExportModePush:
+			parsed.Push = true
+		default:
+			return ExportMode{}, fmt.Errorf("unsupported export mode '%s'", mode)
+		}
+	}
+
+	if parsed.Tar && parsed.Layout {
+		return ExportMode{}, errors.New("export modes tar and layout can not be combined")
+	}
+
+	return parsed, nil
+}
+
+// toExportEntries creates the exporters for the OCI tar or layout and the registry push
+func toExportEntries(opts *BuildOpts) ([]client.ExportEntry, error) {
+	var exports []client.ExportEntry
+
+	if opts.TarFile != "" && opts.LayoutDir != "" {
+		return nil, errors.New("tar file and layout dir can not be exported at the same time")
+	}
+
+	if opts.TarFile != "" {
+		exports = append(exports, client.ExportEntry{
+			Type: client.ExporterOCI,
+			Attrs: map[string]string{
+				"name":              opts.ImageName,
+				"rewrite-timestamp": "true",
+			},
+			Output: func(_ map[string]string) (io.WriteCloser, error) {
+				return os.Create(opts.TarFile)
+			},
+		})
+	}
+
+	if opts.LayoutDir != "" {
+		// BuildKit adds the image to the index of the layout, annotated with its name
+		exports = append(exports, client.ExportEntry{
+			Type: client.ExporterOCI,
+			Attrs: map[string]string{
+				"name":              opts.ImageName,
+				"rewrite-timestamp": "true",
+				"tar":               "false",
+			},
+			OutputDir: opts.LayoutDir,
+		})
+	}
+
+	if opts.Push != nil {
+		if len(opts.Push.Refs) == 0 {
+			return nil, errors.New("push requires at least one image reference")
+		}
+		attrs := map[string]string{
+			"name":              strings.Join(opts.Push.Refs, ","),
+			"push":              "true",
+			"rewrite-timestamp": "true",
+		}
+		if opts.Push.Insecure {
+			attrs["registry.insecure"] = "true"
+		}
+		exports = append(exports, client.ExportEntry{
+			Type:  client.ExporterImage,
+			Attrs: attrs,
+		})
+	}
+
+	if len(exports) == 0 {
+		return nil, errors.New("build requires a tar file, layout dir or push references to export the image to")
+	}
+
+	for _, export := range exports {
+		utils.MergeMapWithPrefix("", export.Attrs, opts.Compression.ToAttributes())
+		if opts.SourceDateEpoch != 0 {
+			export.Attrs["source-date-epoch"] = strconv.FormatInt(opts.SourceDateEpoch, 10)
+		}
+		utils.MergeMapWithPrefix("annotation.", export.Attrs, opts.Annotations)
+		utils.MergeMapWithPrefix("annotation-manifest-descriptor.", export.Attrs, opts.Annotations)
+		// index annotations are rejected by BuildKit for single platform exports
+		if strings.Contains(opts.Platform, ",") {
+			utils.MergeMapWithPrefix("annotation-index.", export.Attrs, opts.Annotations)
+			utils.MergeMapWithPrefix("annotation-index-descriptor.", export.Attrs, opts.Annotations)
+		}
+	}
+
+	return exports, nil
+}
+
+// removePartialOutput removes the tar file of a failed or cancelled build, so it is not mistaken for a complete image
+func removePartialOutput(opts *BuildOpts) {
+	if opts.TarFile != "" {
+		os.Remove(opts.TarFile)
+	}
+}
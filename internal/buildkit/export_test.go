@@ -0,0 +1,232 @@
+package buildkit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/moby/buildkit/client"
+)
+
+func TestParseExportMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		expected    ExportMode
+		expectError bool
+	}{
+		{name: "default", mode: "", expected: ExportMode{Tar: true}},
+		{name: "tar", mode: "tar", expected: ExportMode{Tar: true}},
+		{name: "layout", mode: "layout", expected: ExportMode{Layout: true}},
+		{name: "push", mode: "push", expected: ExportMode{Push: true}},
+		{name: "tar and push", mode: "tar+push", expected: ExportMode{Tar: true, Push: true}},
+		{name: "layout and push", mode: "layout+push", expected: ExportMode{Layout: true, Push: true}},
+		{name: "tar and layout", mode: "tar+layout", expectError: true},
+		{name: "unknown", mode: "docker", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, err := ParseExportMode(tt.mode)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ParseExportMode() error = %v, expectError %v", err, tt.expectError)
+			}
+			if mode != tt.expected {
+				t.Errorf("ParseExportMode() = %+v, want %+v", mode, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToExportEntries(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          *BuildOpts
+		expected      []client.ExportEntry
+		errorContains string
+	}{
+		{
+			name: "tar only",
+			opts: &BuildOpts{ImageName: "python:3.13", TarFile: "image.tar"},
+			expected: []client.ExportEntry{
+				{Type: "oci", Attrs: map[string]string{"name": "python:3.13", "rewrite-timestamp": "true"}},
+			},
+		},
+		{
+			name: "push only",
+			opts: &BuildOpts{
+				ImageName: "python:3.13",
+				Push:      &PushOpts{Refs: []string{"ghcr.io/org/python:3.13", "ghcr.io/org/python:3"}},
+			},
+			expected: []client.ExportEntry{
+				{Type: "image", Attrs: map[string]string{
+					"name":              "ghcr.io/org/python:3.13,ghcr.io/org/python:3",
+					"push":              "true",
+					"rewrite-timestamp": "true",
+				}},
+			},
+		},
+		{
+			name: "tar and insecure push",
+			opts: &BuildOpts{
+				ImageName: "python:3.13",
+				TarFile:   "image.tar",
+				Push:      &PushOpts{Refs: []string{"localhost:5000/python:3.13"}, Insecure: true},
+			},
+			expected: []client.ExportEntry{
+				{Type: "oci", Attrs: map[string]string{"name": "python:3.13", "rewrite-timestamp": "true"}},
+				{Type: "image", Attrs: map[string]string{
+					"name":              "localhost:5000/python:3.13",
+					"push":              "true",
+					"rewrite-timestamp": "true",
+					"registry.insecure": "true",
+				}},
+			},
+		},
+		{
+			name: "layout",
+			opts: &BuildOpts{ImageName: "python:3.13", LayoutDir: "dist/oci"},
+			expected: []client.ExportEntry{
+				{
+					Type:      "oci",
+					Attrs:     map[string]string{"name": "python:3.13", "rewrite-timestamp": "true", "tar": "false"},
+					OutputDir: "dist/oci",
+				},
+			},
+		},
+		{
+			name: "compression applies to all exporters",
+			opts: &BuildOpts{
+				ImageName:   "python:3.13",
+				TarFile:     "image.tar",
+				Push:        &PushOpts{Refs: []string{"ghcr.io/org/python:3.13"}},
+				Compression: &Compression{Type: "zstd", Force: true},
+			},
+			expected: []client.ExportEntry{
+				{Type: "oci", Attrs: map[string]string{
+					"name":              "python:3.13",
+					"rewrite-timestamp": "true",
+					"compression":       "zstd",
+					"force-compression": "true",
+					"oci-mediatypes":    "true",
+				}},
+				{Type: "image", Attrs: map[string]string{
+					"name":              "ghcr.io/org/python:3.13",
+					"push":              "true",
+					"rewrite-timestamp": "true",
+					"compression":       "zstd",
+					"force-compression": "true",
+					"oci-mediatypes":    "true",
+				}},
+			},
+		},
+		{
+			name: "source date epoch",
+			opts: &BuildOpts{ImageName: "python:3.13", TarFile: "image.tar", SourceDateEpoch: 1700000000},
+			expected: []client.ExportEntry{
+				{Type: "oci", Attrs: map[string]string{
+					"name":              "python:3.13",
+					"rewrite-timestamp": "true",
+					"source-date-epoch": "1700000000",
+				}},
+			},
+		},
+		{
+			name: "annotations apply to manifest and descriptor",
+			opts: &BuildOpts{
+				ImageName:   "python:3.13",
+				LayoutDir:   "dist/oci",
+				Annotations: map[string]string{"org.opencontainers.image.version": "3.13"},
+			},
+			expected: []client.ExportEntry{
+				{
+					Type: "oci",
+					Attrs: map[string]string{
+						"name":              "python:3.13",
+						"rewrite-timestamp": "true",
+						"tar":               "false",
+						"annotation.org.opencontainers.image.version":                     "3.13",
+						"annotation-manifest-descriptor.org.opencontainers.image.version": "3.13",
+					},
+					OutputDir: "dist/oci",
+				},
+			},
+		},
+		{
+			name: "annotations of multi-platform images apply to the index",
+			opts: &BuildOpts{
+				ImageName:   "python:3.13",
+				Platform:    "linux/amd64,linux/arm64",
+				TarFile:     "image.tar",
+				Annotations: map[string]string{"org.opencontainers.image.version": "3.13"},
+			},
+			expected: []client.ExportEntry{
+				{Type: "oci", Attrs: map[string]string{
+					"name":              "python:3.13",
+					"rewrite-timestamp": "true",
+					"annotation.org.opencontainers.image.version":                     "3.13",
+					"annotation-manifest-descriptor.org.opencontainers.image.version": "3.13",
+					"annotation-index.org.opencontainers.image.version":               "3.13",
+					"annotation-index-descriptor.org.opencontainers.image.version":    "3.13",
+				}},
+			},
+		},
+		{
+			name:          "tar and layout",
+			opts:          &BuildOpts{TarFile: "image.tar", LayoutDir: "dist/oci"},
+			errorContains: "can not be exported at the same time",
+		},
+		{
+			name:          "push without refs",
+			opts:          &BuildOpts{Push: &PushOpts{}},
+			errorContains: "at least one image reference",
+		},
+		{
+			name:          "nothing to export",
+			opts:          &BuildOpts{ImageName: "python:3.13"},
+			errorContains: "requires a tar file, layout dir or push references",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exports, err := toExportEntries(tt.opts)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for i := range exports {
+				if (exports[i].Output != nil) != (exports[i].Type == "oci" && exports[i].OutputDir == "") {
+					t.Errorf("export %d: expected only the oci tar exporter to write output", i)
+				}
+				exports[i].Output = nil
+			}
+			if diff := cmp.Diff(tt.expected, exports); diff != "" {
+				t.Errorf("toExportEntries() mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRemovePartialOutput(t *testing.T) {
+	tarFile := filepath.Join(t.TempDir(), "image.tar")
+	if err := os.WriteFile(tarFile, []byte("partial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	removePartialOutput(&BuildOpts{TarFile: tarFile})
+	if _, err := os.Stat(tarFile); !os.IsNotExist(err) {
+		t.Errorf("expected partial tar to be removed, got %v", err)
+	}
+
+	// nothing to remove without tar output
+	removePartialOutput(&BuildOpts{})
+}
@@ -0,0 +1,176 @@
+package buildkit
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// FrontendOptions configure how the Dockerfile is built and which entitlements the build is granted
+type FrontendOptions struct {
+	Target        string
+	Network       model.NetworkMode
+	AddHosts      []string
+	ShmSize       int64
+	Ulimits       []string
+	NoCache       bool
+	NoCacheFilter []string
+	Entitlements  []model.Entitlement
+}
+
+// NewFrontendOptions merges the build configs, set fields of later configs override earlier ones, e.g. image, tag and variant.
+// Returns nil if nothing is configured.
+func NewFrontendOptions(configs ...*model.BuildConfig) (*FrontendOptions, error) {
+	var merged *model.BuildConfig
+	for _, config := range configs {
+		if config == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &model.BuildConfig{}
+		}
+		if config.Target != "" {
+			merged.Target = config.Target
+		}
+		if config.Network != "" {
+			merged.Network = config.Network
+		}
+		if config.AddHosts != nil {
+			merged.AddHosts = config.AddHosts
+		}
+		if config.ShmSize != "" {
+			merged.ShmSize = config.ShmSize
+		}
+		if config.Ulimits != nil {
+			merged.Ulimits = config.Ulimits
+		}
+		if config.NoCache != nil {
+			merged.NoCache = config.NoCache
+		}
+		if config.NoCacheFilter != nil {
+			merged.NoCacheFilter = config.NoCacheFilter
+		}
+		if config.Entitlements != nil {
+			merged.Entitlements = config.Entitlements
+		}
+	}
+
+	if merged == nil {
+		return nil, nil
+	}
+	return toFrontendOptions(merged)
+}
+
+// toFrontendOptions validates the build config and converts it to the representation used by BuildKit
+func toFrontendOptions(config *model.BuildConfig) (*FrontendOptions, error) {
+	options := &FrontendOptions{
+		Target:        config.Target,
+		Network:       config.Network,
+		NoCache:       config.NoCache != nil && *config.NoCache,
+		NoCacheFilter: config.NoCacheFilter,
+		Entitlements:  config.Entitlements,
+	}
+
+	for _, entitlement := range config.Entitlements {
+		if !slices.Contains(model.Entitlements, entitlement) {
+			return nil, fmt.Errorf("unsupported entitlement '%s'", entitlement)
+		}
+	}
+
+	switch config.Network {
+	case "", model.NetworkModeDefault, model.NetworkModeNone:
+	case model.NetworkModeHost:
+		if !slices.Contains(config.Entitlements, model.EntitlementNetworkHost) {
+			return nil, fmt.Errorf("network mode host requires the %s entitlement", model.EntitlementNetworkHost)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported network mode '%s'", config.Network)
+	}
+
+	for _, host := range config.AddHosts {
+		normalized, err := normalizeHost(host)
+		if err != nil {
+			return nil, err
+		}
+		options.AddHosts = append(options.AddHosts, normalized)
+	}
+
+	if config.ShmSize != "" {
+		shmSize, err := units.RAMInBytes(config.ShmSize)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("invalid shm size '%s'", config.ShmSize), err)
+		}
+		options.ShmSize = shmSize
+	}
+
+	for _, ulimit := range config.Ulimits {
+		parsed, err := units.ParseUlimit(ulimit)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("invalid ulimit '%s'", ulimit), err)
+		}
+		options.Ulimits = append(options.Ulimits, parsed.String())
+	}
+
+	return options, nil
+}
+
+// normalizeHost converts host:ip and host=ip entries to the host=ip format expected by the Dockerfile frontend
+func normalizeHost(entry string) (string, error) {
+	host, ip, ok := strings.Cut(entry, "=")
+	if !ok {
+		host, ip, ok = strings.Cut(entry, ":")
+	}
+	if !ok || host == "" {
+		return "", fmt.Errorf("invalid host entry '%s', expected host=ip", entry)
+	}
+	if net.ParseIP(ip) == nil {
+		return "", fmt.Errorf("invalid IP '%s' for host %s", ip, host)
+	}
+	return host + "=" + ip, nil
+}
+
+// toFrontendAttrs adds the options to the Dockerfile frontend attributes
+func (o *FrontendOptions) toFrontendAttrs(attrs map[string]string) {
+	if o == nil {
+		return
+	}
+	if o.Target != "" {
+		attrs["target"] = o.Target
+	}
+	switch o.Network {
+	case model.NetworkModeNone, model.NetworkModeHost:
+		attrs["force-network-mode"] = string(o.Network)
+	}
+	if len(o.AddHosts) > 0 {
+		attrs["add-hosts"] = strings.Join(o.AddHosts, ",")
+	}
+	if o.ShmSize > 0 {
+		attrs["shm-size"] = strconv.FormatInt(o.ShmSize, 10)
+	}
+	if len(o.Ulimits) > 0 {
+		attrs["ulimit"] = strings.Join(o.Ulimits, ",")
+	}
+	if o.NoCache {
+		attrs["no-cache"] = ""
+	} else if len(o.NoCacheFilter) > 0 {
+		attrs["no-cache"] = strings.Join(o.NoCacheFilter, ",")
+	}
+}
+
+// allowedEntitlements returns the entitlements to request for the solve
+func (o *FrontendOptions) allowedEntitlements() []string {
+	if o == nil {
+		return nil
+	}
+	entitlements := make([]string, len(o.Entitlements))
+	for i, entitlement := range o.Entitlements {
+		entitlements[i] = string(entitlement)
+	}
+	return entitlements
+}
@@ -0,0 +1,165 @@
+package buildkit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+func TestNewFrontendOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		image         *model.BuildConfig
+		tag           *model.BuildConfig
+		variant       *model.BuildConfig
+		expected      *FrontendOptions
+		errorContains string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name: "image only",
+			image: &model.BuildConfig{
+				Target:   "runtime",
+				AddHosts: []string{"registry.local:10.0.0.1", "mirror=::1"},
+				ShmSize:  "128m",
+				Ulimits:  []string{"nofile=1024:2048"},
+			},
+			expected: &FrontendOptions{
+				Target:   "runtime",
+				AddHosts: []string{"registry.local=10.0.0.1", "mirror=::1"},
+				ShmSize:  128 * 1024 * 1024,
+				Ulimits:  []string{"nofile=1024:2048"},
+			},
+		},
+		{
+			name:     "variant overrides tag and image",
+			image:    &model.BuildConfig{Target: "runtime", NoCache: boolPtr(true)},
+			tag:      &model.BuildConfig{Target: "debug", NoCacheFilter: []string{"deps"}},
+			variant:  &model.BuildConfig{NoCache: boolPtr(false), Network: model.NetworkModeNone},
+			expected: &FrontendOptions{Target: "debug", Network: model.NetworkModeNone, NoCacheFilter: []string{"deps"}},
+		},
+		{
+			name: "host network with entitlement",
+			image: &model.BuildConfig{
+				Network:      model.NetworkModeHost,
+				Entitlements: []model.Entitlement{model.EntitlementNetworkHost},
+			},
+			expected: &FrontendOptions{
+				Network:      model.NetworkModeHost,
+				Entitlements: []model.Entitlement{model.EntitlementNetworkHost},
+			},
+		},
+		{
+			name:          "host network without entitlement",
+			image:         &model.BuildConfig{Network: model.NetworkModeHost},
+			tag:           &model.BuildConfig{Entitlements: []model.Entitlement{model.EntitlementSecurityInsecure}},
+			errorContains: "requires the network.host entitlement",
+		},
+		{
+			name:          "unsupported network",
+			image:         &model.BuildConfig{Network: "bridge"},
+			errorContains: "unsupported network mode 'bridge'",
+		},
+		{
+			name:          "unsupported entitlement",
+			image:         &model.BuildConfig{Entitlements: []model.Entitlement{"device"}},
+			errorContains: "unsupported entitlement 'device'",
+		},
+		{
+			name:          "invalid host",
+			image:         &model.BuildConfig{AddHosts: []string{"registry.local"}},
+			errorContains: "expected host=ip",
+		},
+		{
+			name:          "invalid IP",
+			image:         &model.BuildConfig{AddHosts: []string{"registry.local=10.0.0"}},
+			errorContains: "invalid IP '10.0.0'",
+		},
+		{
+			name:          "invalid shm size",
+			image:         &model.BuildConfig{ShmSize: "lots"},
+			errorContains: "invalid shm size 'lots'",
+		},
+		{
+			name:          "invalid ulimit",
+			image:         &model.BuildConfig{Ulimits: []string{"nofile=2048:1024"}},
+			errorContains: "invalid ulimit 'nofile=2048:1024'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := NewFrontendOptions(tt.image, tt.tag, tt.variant)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, options); diff != "" {
+				t.Errorf("NewFrontendOptions() mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFrontendOptions_toFrontendAttrs(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  *FrontendOptions
+		expected map[string]string
+	}{
+		{
+			name:     "nil",
+			expected: map[string]string{},
+		},
+		{
+			name:     "default network is not forced",
+			options:  &FrontendOptions{Network: model.NetworkModeDefault},
+			expected: map[string]string{},
+		},
+		{
+			name: "all options",
+			options: &FrontendOptions{
+				Target:   "runtime",
+				Network:  model.NetworkModeNone,
+				AddHosts: []string{"a=10.0.0.1", "b=10.0.0.2"},
+				ShmSize:  1024,
+				Ulimits:  []string{"nofile=1024:2048", "nproc=512"},
+				NoCache:  true,
+				// ignored as all stages are built without cache
+				NoCacheFilter: []string{"deps"},
+			},
+			expected: map[string]string{
+				"target":             "runtime",
+				"force-network-mode": "none",
+				"add-hosts":          "a=10.0.0.1,b=10.0.0.2",
+				"shm-size":           "1024",
+				"ulimit":             "nofile=1024:2048,nproc=512",
+				"no-cache":           "",
+			},
+		},
+		{
+			name:     "no cache filter",
+			options:  &FrontendOptions{NoCacheFilter: []string{"deps", "build"}},
+			expected: map[string]string{"no-cache": "deps,build"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := map[string]string{}
+			tt.options.toFrontendAttrs(attrs)
+			if diff := cmp.Diff(tt.expected, attrs); diff != "" {
+				t.Errorf("toFrontendAttrs() mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package buildkit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/moby/buildkit/client"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// WorkerHealth describes a worker of the BuildKit daemon
+type WorkerHealth struct {
+	ID        string
+	Platforms []string
+	// GCPolicy contains a human readable description per garbage collection rule
+	GCPolicy []string
+}
+
+// Health of the BuildKit daemon
+type Health struct {
+	Version string
+	Workers []WorkerHealth
+}
+
+// Health queries the version and workers of the BuildKit daemon
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	info, err := c.buildkit.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	workers, err := c.buildkit.ListWorkers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &Health{Version: info.BuildkitVersion.Version}
+	for _, worker := range workers {
+		workerHealth := WorkerHealth{ID: worker.ID}
+		for _, platform := range worker.Platforms {
+			workerHealth.Platforms = append(workerHealth.Platforms, formatPlatform(platform))
+		}
+		for _, policy := range worker.GCPolicy {
+			workerHealth.GCPolicy = append(workerHealth.GCPolicy, formatGCPolicy(policy))
+		}
+		health.Workers = append(health.Workers, workerHealth)
+	}
+	return health, nil
+}
+
+func formatPlatform(platform ocispecs.Platform) string {
+	formatted := platform.OS + "/" + platform.Architecture
+	if platform.Variant != "" {
+		formatted += "/" + platform.Variant
+	}
+	return formatted
+}
+
+func formatGCPolicy(policy client.PruneInfo) string {
+	var parts []string
+	if policy.All {
+		parts = append(parts, "all")
+	}
+	if len(policy.Filter) > 0 {
+		parts = append(parts, "filter="+strings.Join(policy.Filter, ","))
+	}
+	if policy.KeepDuration > 0 {
+		parts = append(parts, "keep="+policy.KeepDuration.String())
+	}
+	if policy.ReservedSpace > 0 {
+		parts = append(parts, "reserved="+units.BytesSize(float64(policy.ReservedSpace)))
+	}
+	if policy.MaxUsedSpace > 0 {
+		parts = append(parts, "max-used="+units.BytesSize(float64(policy.MaxUsedSpace)))
+	}
+	if policy.MinFreeSpace > 0 {
+		parts = append(parts, "min-free="+units.BytesSize(float64(policy.MinFreeSpace)))
+	}
+	if len(parts) == 0 {
+		return "unlimited"
+	}
+	return strings.Join(parts, " ")
+}
+
+// String renders the health as multi-line report
+func (h *Health) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "BuildKit version: %s\n", h.Version)
+	for _, worker := range h.Workers {
+		fmt.Fprintf(&b, "Worker %s\n", worker.ID)
+		fmt.Fprintf(&b, "  platforms: %s\n", strings.Join(worker.Platforms, ", "))
+		for _, policy := range worker.GCPolicy {
+			fmt.Fprintf(&b, "  gc policy: %s\n", policy)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package buildkit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/client"
+)
+
+func TestFormatGCPolicy(t *testing.T) {
+	tests := map[string]struct {
+		policy   client.PruneInfo
+		expected string
+	}{
+		"unlimited": {
+			policy:   client.PruneInfo{},
+			expected: "unlimited",
+		},
+		"filtered with duration": {
+			policy: client.PruneInfo{
+				Filter:       []string{"type==source.local", "type==exec.cachemount"},
+				KeepDuration: 48 * time.Hour,
+				MaxUsedSpace: 512 * 1024 * 1024,
+			},
+			expected: "filter=type==source.local,type==exec.cachemount keep=48h0m0s max-used=512MiB",
+		},
+		"all with reserved space": {
+			policy:   client.PruneInfo{All: true, ReservedSpace: 10 * 1024 * 1024 * 1024, MinFreeSpace: 1024 * 1024 * 1024},
+			expected: "all reserved=10GiB min-free=1GiB",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := formatGCPolicy(tc.policy); got != tc.expected {
+				t.Errorf("formatGCPolicy() = %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
@@ -3,12 +3,14 @@ package buildkit
 import (
 	"context"
 	"errors"
-	"io"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/docker/cli/cli/config"
 	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/auth/authprovider"
 	"github.com/moby/buildkit/session/secrets/secretsprovider"
@@ -23,21 +25,56 @@ type Client struct {
 }
 
 type BuildOpts struct {
-	ImageName    string
-	Platform     string
-	TarFile      string
-	BuildArgs    map[string]string
-	Secrets      map[string][]byte
-	Labels       map[string]string
-	Cache        cache.BuildkitCache
+	ImageName string
+	// Platform to build for, comma separated platforms are built in a single solve into an image index
+	Platform string
+	// TarFile to write the OCI image to, optional when pushing
+	TarFile string
+	// LayoutDir is an OCI layout directory to add the image to, replaces TarFile
+	LayoutDir string
+	// Push the image from BuildKit directly, optional when writing a tar file
+	Push *PushOpts
+	// Compression of the exported layers, BuildKit defaults to gzip
+	Compression *Compression
+	// SourceDateEpoch is passed to the build and used for all timestamps of the image, unset when zero
+	SourceDateEpoch int64
+	// NoCache disables the BuildKit cache for all build steps, regardless of the frontend options
+	NoCache bool
+	// Frontend options like the target stage, network and entitlements, optional
+	Frontend  *FrontendOptions
+	BuildArgs map[string]string
+	Secrets   map[string][]byte
+	SSH       []SSHForward
+	Labels    map[string]string
+	// Annotations are added to the image manifest and its descriptor by every exporter
+	Annotations map[string]string
+	// Cache is imported from and exported to
+	Cache cache.BuildkitCache
+	// CacheImports are only imported from, in addition to Cache
+	CacheImports []cache.BuildkitCache
+	// CacheExports are only exported to, in addition to Cache
+	CacheExports []cache.BuildkitCache
 	BuildContext build_context.BuildContext
 }
 
-func NewClient(ctx context.Context, endpoint string) (*Client, error) {
-	buildkit, err := client.New(ctx, endpoint)
+// NewClient connects to BuildKit, failing when the daemon is not reachable within the connect timeout
+func NewClient(ctx context.Context, config ConnectionConfig) (*Client, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	buildkit, err := client.New(ctx, config.Address, config.clientOpts()...)
 	if err != nil {
 		return nil, err
 	}
+
+	connectCtx, cancel := context.WithTimeout(ctx, config.connectTimeout())
+	defer cancel()
+	if _, err := buildkit.Info(connectCtx); err != nil {
+		buildkit.Close()
+		return nil, errors.Join(fmt.Errorf("failed to reach BuildKit at %s", config.Address), err)
+	}
+
 	return &Client{buildkit}, nil
 }
 
@@ -53,65 +90,86 @@ func (c *Client) Version(ctx context.Context) (string, error) {
 	return info.BuildkitVersion.Version, nil
 }
 
-func (c *Client) Build(ctx context.Context, opts *BuildOpts, statusUpdateHandler func(chan *client.SolveStatus) error) error {
-	var buildCache []client.CacheOptionsEntry
-	if opts.Cache != nil {
-		cacheOpt := opts.Cache
+// toCacheOptions converts the caches to buildkit cache options, ignoring cache errors unless configured otherwise
+func toCacheOptions(caches []cache.BuildkitCache) []client.CacheOptionsEntry {
+	var entries []client.CacheOptionsEntry
+	for _, cacheOpt := range caches {
 		attributes := cacheOpt.ToAttributes()
 		_, hasExplicitIgnoreErr := attributes["ignore-errors"]
 		if !hasExplicitIgnoreErr {
 			attributes["ignore-errors"] = "true"
 		}
-		buildCache = []client.CacheOptionsEntry{
-			{
-				Type:  cacheOpt.Name(),
-				Attrs: attributes,
-			},
-		}
+		entries = append(entries, client.CacheOptionsEntry{
+			Type:  cacheOpt.Name(),
+			Attrs: attributes,
+		})
+	}
+	return entries
+}
+
+func (c *Client) Build(ctx context.Context, opts *BuildOpts, statusUpdateHandler func(chan *client.SolveStatus) error) (*BuildResult, error) {
+	cacheImports := opts.CacheImports
+	cacheExports := opts.CacheExports
+	if opts.Cache != nil {
+		cacheImports = append([]cache.BuildkitCache{opts.Cache}, cacheImports...)
+		cacheExports = append([]cache.BuildkitCache{opts.Cache}, cacheExports...)
+	}
+
+	exports, err := toExportEntries(opts)
+	if err != nil {
+		return nil, err
 	}
 
 	localMounts, err := opts.BuildContext.ToLocalMounts()
 	if err != nil {
-		return errors.Join(errors.New("failed to mount build context"), err)
+		return nil, errors.Join(errors.New("failed to mount build context"), err)
 	}
 
 	frontendAttrs := map[string]string{
-		"filename":                    filepath.Base(opts.BuildContext.FileName()),
-		"build-arg:SOURCE_DATE_EPOCH": "1770336000",
-		"platform":                    opts.Platform,
+		"filename": filepath.Base(opts.BuildContext.FileName()),
+		"platform": opts.Platform,
 		// this will be done using syft explicitly
 		// as this should not rely on a upstream image
 		// "attest:sbom":                 "",
 	}
 
+	if opts.SourceDateEpoch != 0 {
+		frontendAttrs["build-arg:SOURCE_DATE_EPOCH"] = strconv.FormatInt(opts.SourceDateEpoch, 10)
+	}
+	opts.Frontend.toFrontendAttrs(frontendAttrs)
+	if opts.NoCache {
+		frontendAttrs["no-cache"] = ""
+	}
+
 	utils.MergeMapWithPrefix("label:", frontendAttrs, opts.Labels)
 	utils.MergeMapWithPrefix("build-arg:", frontendAttrs, opts.BuildArgs)
 
 	dockerConfig := config.LoadDefaultConfigFile(os.Stderr)
+	attachables := []session.Attachable{
+		authprovider.NewDockerAuthProvider(authprovider.DockerAuthProviderConfig{
+			AuthConfigProvider: authprovider.LoadAuthConfig(dockerConfig),
+		}),
+		secretsprovider.FromMap(opts.Secrets),
+	}
+
+	if len(opts.SSH) > 0 {
+		sshProvider, err := newSSHProvider(opts.SSH)
+		if err != nil {
+			return nil, errors.Join(errors.New("failed to set up ssh forwarding"), err)
+		}
+		attachables = append(attachables, sshProvider)
+	}
+
 	solveOpts := client.SolveOpt{
-		Session: []session.Attachable{
-			authprovider.NewDockerAuthProvider(authprovider.DockerAuthProviderConfig{
-				AuthConfigProvider: authprovider.LoadAuthConfig(dockerConfig),
-			}),
-			secretsprovider.FromMap(opts.Secrets),
-		},
-		CacheExports: buildCache,
-		CacheImports: buildCache,
-		Exports: []client.ExportEntry{
-			{
-				Type: "oci",
-				Attrs: map[string]string{
-					"name":              opts.ImageName,
-					"rewrite-timestamp": "true",
-				},
-				Output: func(_ map[string]string) (io.WriteCloser, error) {
-					return os.Create(opts.TarFile)
-				},
-			},
-		},
+		Session:       attachables,
+		CacheExports:  toCacheOptions(cacheExports),
+		CacheImports:  toCacheOptions(cacheImports),
+		Exports:       exports,
 		LocalMounts:   localMounts,
 		Frontend:      opts.BuildContext.FrontendType(),
 		FrontendAttrs: frontendAttrs,
+		// entitlements must be allowed by the daemon as well, otherwise the solve fails
+		AllowedEntitlements: opts.Frontend.allowedEntitlements(),
 	}
 
 	statusUpdates := make(chan *client.SolveStatus)
@@ -120,10 +178,16 @@ func (c *Client) Build(ctx context.Context, opts *BuildOpts, statusUpdateHandler
 		return statusUpdateHandler(statusUpdates)
 	})
 
+	var resp *client.SolveResponse
 	eg.Go(func() error {
-		_, err = c.buildkit.Build(ctx, solveOpts, "ContainerHive", opts.BuildContext.RunBuild, statusUpdates)
+		resp, err = c.buildkit.Build(ctx, solveOpts, "ContainerHive", opts.BuildContext.RunBuild, statusUpdates)
 		return err
 	})
 
-	return eg.Wait()
+	if err := eg.Wait(); err != nil {
+		removePartialOutput(opts)
+		return nil, err
+	}
+
+	return &BuildResult{Digest: resp.ExporterResponse[exptypes.ExporterImageDigestKey]}, nil
 }
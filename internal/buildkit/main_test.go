@@ -115,7 +115,7 @@ func TestIntegrationBuild(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	bkClient, err := NewClient(ctx, fmt.Sprintf("tcp://%s:%s", buildkitHost, buildkitPort.Port()))
+	bkClient, err := NewClient(ctx, ConnectionConfig{Address: fmt.Sprintf("tcp://%s:%s", buildkitHost, buildkitPort.Port())})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -132,10 +132,21 @@ func TestIntegrationBuild(t *testing.T) {
 		t.Logf("BuildKit version: %s", version)
 	})
 
+	t.Run("health", func(t *testing.T) {
+		health, err := bkClient.Health(ctx)
+		if err != nil {
+			t.Fatal("failed to get buildkit health:", err)
+		}
+		if len(health.Workers) == 0 || len(health.Workers[0].Platforms) == 0 {
+			t.Fatalf("expected a worker with platforms, got %+v", health)
+		}
+		t.Log(health)
+	})
+
 	t.Run("without_cache", func(t *testing.T) {
 		tarFile := filepath.Join(t.TempDir(), "output.tar")
 
-		err := bkClient.Build(ctx, &BuildOpts{
+		_, err := bkClient.Build(ctx, &BuildOpts{
 			ImageName: "test-no-cache:latest",
 			TarFile:   tarFile,
 			BuildContext: &build_context.DockerfileBuildContext{
@@ -171,7 +182,7 @@ func TestIntegrationBuild(t *testing.T) {
 
 		// First build — populates the cache
 		tarFile1 := filepath.Join(tmpDir, "cached1.tar")
-		if err := bkClient.Build(ctx, &BuildOpts{
+		if _, err := bkClient.Build(ctx, &BuildOpts{
 			ImageName: "test-cached:latest",
 			TarFile:   tarFile1,
 			Cache:     s3Cache,
@@ -193,7 +204,7 @@ func TestIntegrationBuild(t *testing.T) {
 
 		// Second build — should use the cache
 		tarFile2 := filepath.Join(tmpDir, "cached2.tar")
-		if err := bkClient.Build(ctx, &BuildOpts{
+		if _, err := bkClient.Build(ctx, &BuildOpts{
 			ImageName: "test-cached-reuse:latest",
 			TarFile:   tarFile2,
 			Cache:     s3Cache,
@@ -252,7 +263,7 @@ func TestIntegrationBuild(t *testing.T) {
 
 		// First build — populates the cache
 		tarFile1 := filepath.Join(t.TempDir(), "registry-cached1.tar")
-		if err := bkClient.Build(ctx, &BuildOpts{
+		if _, err := bkClient.Build(ctx, &BuildOpts{
 			ImageName: "test-registry-cached:latest",
 			TarFile:   tarFile1,
 			Cache:     registryCache,
@@ -274,7 +285,7 @@ func TestIntegrationBuild(t *testing.T) {
 
 		// Second build — should use the cache
 		tarFile2 := filepath.Join(t.TempDir(), "registry-cached2.tar")
-		if err := bkClient.Build(ctx, &BuildOpts{
+		if _, err := bkClient.Build(ctx, &BuildOpts{
 			ImageName: "test-registry-cached-reuse:latest",
 			TarFile:   tarFile2,
 			Cache:     registryCache,
@@ -315,6 +326,67 @@ func TestIntegrationBuild(t *testing.T) {
 		}
 	})
 
+	t.Run("push_to_registry", func(t *testing.T) {
+		registryC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image:        "registry:2",
+				ExposedPorts: []string{"5000/tcp"},
+				Networks:     []string{net.Name},
+				NetworkAliases: map[string][]string{
+					net.Name: {"push-registry"},
+				},
+				WaitingFor: wait.ForHTTP("/v2/").WithPort("5000/tcp"),
+			},
+			Started: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { registryC.Terminate(ctx) })
+
+		result, err := bkClient.Build(ctx, &BuildOpts{
+			ImageName: "test-push:latest",
+			Push: &PushOpts{
+				Refs:     []string{"push-registry:5000/test-push:latest"},
+				Insecure: true,
+			},
+			BuildContext: &build_context.DockerfileBuildContext{
+				Root: buildCtxDir,
+			},
+			Platform: platform,
+		}, drainStatus)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(result.Digest, "sha256:") {
+			t.Fatalf("expected pushed digest, got %q", result.Digest)
+		}
+
+		registryHost, err := registryC.Host(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		registryPort, err := registryC.MappedPort(ctx, "5000/tcp")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest(http.MethodHead, fmt.Sprintf("http://%s:%s/v2/test-push/manifests/%s", registryHost, registryPort.Port(), result.Digest), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", "application/vnd.oci.image.manifest.v1+json, application/vnd.oci.image.index.v1+json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected pushed manifest %s in registry, got status: %d", result.Digest, resp.StatusCode)
+		}
+	})
+
 	t.Run("ignore_errors_default", func(t *testing.T) {
 		// Test that ignore-errors is set to true by default
 		s3Cache := &cache.S3BuildKitCache{
@@ -329,7 +401,7 @@ func TestIntegrationBuild(t *testing.T) {
 
 		// Build should succeed even if cache operations might fail
 		tarFile := filepath.Join(t.TempDir(), "ignore-errors.tar")
-		if err := bkClient.Build(ctx, &BuildOpts{
+		if _, err := bkClient.Build(ctx, &BuildOpts{
 			ImageName: "test-ignore-errors:latest",
 			TarFile:   tarFile,
 			Cache:     s3Cache,
@@ -376,12 +448,7 @@ func TestIgnoreErrorsHandling(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Simulate the logic in Build()
-			attributes := tt.cache.ToAttributes()
-			_, hasExplicitIgnoreErr := attributes["ignore-errors"]
-			if !hasExplicitIgnoreErr {
-				attributes["ignore-errors"] = "true"
-			}
+			attributes := toCacheOptions([]cache.BuildkitCache{tt.cache})[0].Attrs
 
 			if got := attributes["ignore-errors"]; got != tt.wantIgnoreErr {
 				t.Errorf("ignore-errors = %q, want %q", got, tt.wantIgnoreErr)
@@ -0,0 +1,214 @@
+package buildkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/moby/buildkit/client"
+	"github.com/timo-reymann/ContainerHive/internal/oci_layout"
+	"golang.org/x/sync/errgroup"
+)
+
+// Build builds the image for all platforms on their nodes. Platforms of the same node are built in a single solve,
+// when multiple nodes are involved their results are merged into one OCI image index before exporting it.
+func (n *Nodes) Build(ctx context.Context, opts *BuildOpts, buildPlatforms []string, statusUpdateHandler func(chan *client.SolveStatus) error) (*BuildResult, error) {
+	groups, err := n.group(buildPlatforms)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(groups) {
+	case 0:
+		return nil, errors.New("build requires at least one platform")
+	case 1:
+		nodeOpts := *opts
+		nodeOpts.Platform = groups[0].platform()
+		return groups[0].client.Build(ctx, &nodeOpts, statusUpdateHandler)
+	default:
+		return n.buildMultiNode(ctx, opts, groups, statusUpdateHandler)
+	}
+}
+
+// buildMultiNode runs one solve per node exporting to a temporary OCI tar, merges them and exports the index
+func (n *Nodes) buildMultiNode(ctx context.Context, opts *BuildOpts, groups []*nodeGroup, statusUpdateHandler func(chan *client.SolveStatus) error) (*BuildResult, error) {
+	if _, err := toExportEntries(opts); err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "multi-node-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// status updates of all nodes are shown in a single display
+	statusUpdates := make(chan *client.SolveStatus)
+	handlerDone := make(chan error, 1)
+	go func() {
+		handlerDone <- statusUpdateHandler(statusUpdates)
+	}()
+
+	tarFiles := make([]string, len(groups))
+	eg, egCtx := errgroup.WithContext(ctx)
+	for i, group := range groups {
+		tarFiles[i] = filepath.Join(tmpDir, fmt.Sprintf("node-%d.tar", i))
+		nodeOpts := *opts
+		nodeOpts.Platform = group.platform()
+		nodeOpts.TarFile = tarFiles[i]
+		nodeOpts.LayoutDir = ""
+		nodeOpts.Push = nil
+
+		eg.Go(func() error {
+			if _, err := group.client.Build(egCtx, &nodeOpts, forwardStatusUpdates(statusUpdates)); err != nil {
+				return errors.Join(fmt.Errorf("build for %s failed", nodeOpts.Platform), err)
+			}
+			return nil
+		})
+	}
+	buildErr := eg.Wait()
+	close(statusUpdates)
+	if err := errors.Join(buildErr, <-handlerDone); err != nil {
+		return nil, err
+	}
+
+	idx, cleanup, err := mergeIndex(tarFiles)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	if len(opts.Annotations) > 0 {
+		idx = mutate.Annotations(idx, opts.Annotations).(v1.ImageIndex)
+	}
+	if err := exportIndex(ctx, opts, idx); err != nil {
+		removePartialOutput(opts)
+		return nil, err
+	}
+
+	digest, err := idx.Digest()
+	if err != nil {
+		return nil, err
+	}
+	return &BuildResult{Digest: digest.String()}, nil
+}
+
+// forwardStatusUpdates returns a status handler passing the updates of a single solve on to the shared channel
+func forwardStatusUpdates(statusUpdates chan<- *client.SolveStatus) func(chan *client.SolveStatus) error {
+	return func(ch chan *client.SolveStatus) error {
+		for status := range ch {
+			statusUpdates <- status
+		}
+		return nil
+	}
+}
+
+// mergeIndex combines the images of the OCI tars into one index, skipping attestations.
+// The index is read lazily from the extracted tars, so it is only valid until cleanup is called.
+func mergeIndex(tarFiles []string) (v1.ImageIndex, func(), error) {
+	var cleanups []func()
+	cleanup := func() {
+		for _, c := range cleanups {
+			c()
+		}
+	}
+
+	var addenda []mutate.IndexAddendum
+	for _, tarFile := range tarFiles {
+		artifact, artifactCleanup, err := oci_layout.ArtifactFromTar(tarFile)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		cleanups = append(cleanups, artifactCleanup)
+
+		nodeAddenda, err := toIndexAddenda(artifact)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		addenda = append(addenda, nodeAddenda...)
+	}
+
+	idx := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex), addenda...)
+	return idx, cleanup, nil
+}
+
+// toIndexAddenda returns the platform images of a single node result
+func toIndexAddenda(artifact remote.Taggable) ([]mutate.IndexAddendum, error) {
+	switch artifact := artifact.(type) {
+	case v1.Image:
+		config, err := artifact.ConfigFile()
+		if err != nil {
+			return nil, err
+		}
+		return []mutate.IndexAddendum{{
+			Add:        artifact,
+			Descriptor: v1.Descriptor{Platform: config.Platform()},
+		}}, nil
+	case v1.ImageIndex:
+		manifest, err := artifact.IndexManifest()
+		if err != nil {
+			return nil, err
+		}
+		var addenda []mutate.IndexAddendum
+		for _, desc := range manifest.Manifests {
+			if !desc.MediaType.IsImage() || desc.Annotations["vnd.docker.reference.type"] != "" {
+				continue
+			}
+			img, err := artifact.Image(desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+			addenda = append(addenda, mutate.IndexAddendum{
+				Add:        img,
+				Descriptor: v1.Descriptor{Platform: desc.Platform, Annotations: desc.Annotations},
+			})
+		}
+		return addenda, nil
+	default:
+		return nil, fmt.Errorf("unsupported build result %T", artifact)
+	}
+}
+
+// exportIndex writes the merged index to the tar file and layout dir and pushes it to the registry
+func exportIndex(ctx context.Context, opts *BuildOpts, idx v1.ImageIndex) error {
+	if opts.TarFile != "" {
+		if err := oci_layout.WriteIndexTar(opts.TarFile, opts.ImageName, idx, opts.Annotations); err != nil {
+			return err
+		}
+	}
+
+	if opts.LayoutDir != "" {
+		if err := oci_layout.AppendIndex(opts.LayoutDir, opts.ImageName, idx, opts.Annotations); err != nil {
+			return err
+		}
+	}
+
+	if opts.Push != nil {
+		var nameOpts []name.Option
+		if opts.Push.Insecure {
+			nameOpts = append(nameOpts, name.Insecure)
+		}
+		for _, pushRef := range opts.Push.Refs {
+			ref, err := name.ParseReference(pushRef, nameOpts...)
+			if err != nil {
+				return errors.Join(fmt.Errorf("invalid image reference %s", pushRef), err)
+			}
+			if err := remote.Push(ref, idx, remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain)); err != nil {
+				return errors.Join(fmt.Errorf("failed to push image index to %s", pushRef), err)
+			}
+		}
+	}
+
+	return nil
+}
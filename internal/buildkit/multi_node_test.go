@@ -0,0 +1,130 @@
+package buildkit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/timo-reymann/ContainerHive/internal/oci_layout"
+	"github.com/timo-reymann/ContainerHive/internal/utils"
+)
+
+// writeNodeResult writes an OCI tar as a node building the platforms would
+func writeNodeResult(t *testing.T, dir, file string, platforms ...v1.Platform) string {
+	t.Helper()
+	tarFile := filepath.Join(dir, file)
+
+	var addenda []mutate.IndexAddendum
+	for _, platform := range platforms {
+		img, err := random.Image(64, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config, err := img.ConfigFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.OS, config.Architecture, config.Variant = platform.OS, platform.Architecture, platform.Variant
+		img, err = mutate.ConfigFile(img, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addenda = append(addenda, mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: &platform}})
+	}
+
+	if len(addenda) == 1 {
+		layoutDir := t.TempDir()
+		layoutPath, err := layout.Write(layoutDir, empty.Index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := layoutPath.AppendImage(addenda[0].Add.(v1.Image)); err != nil {
+			t.Fatal(err)
+		}
+		if err := utils.CreateTar(layoutDir, tarFile); err != nil {
+			t.Fatal(err)
+		}
+		return tarFile
+	}
+
+	idx := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex), addenda...)
+	if err := oci_layout.WriteIndexTar(tarFile, "python:3.13", idx, nil); err != nil {
+		t.Fatal(err)
+	}
+	return tarFile
+}
+
+func TestMergeIndex(t *testing.T) {
+	dir := t.TempDir()
+	amd64 := v1.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := v1.Platform{OS: "linux", Architecture: "arm64"}
+	armv7 := v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}
+
+	idx, cleanup, err := mergeIndex([]string{
+		writeNodeResult(t, dir, "amd64.tar", amd64),
+		writeNodeResult(t, dir, "arm.tar", arm64, armv7),
+	})
+	if err != nil {
+		t.Fatalf("mergeIndex() error = %v", err)
+	}
+	defer cleanup()
+
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.MediaType != types.OCIImageIndex {
+		t.Errorf("expected OCI index, got %s", manifest.MediaType)
+	}
+	var platforms []v1.Platform
+	for _, desc := range manifest.Manifests {
+		platforms = append(platforms, *desc.Platform)
+	}
+	if diff := cmp.Diff([]v1.Platform{amd64, arm64, armv7}, platforms); diff != "" {
+		t.Errorf("platforms mismatch (-expected +got):\n%s", diff)
+	}
+
+	t.Run("export to tar and layout", func(t *testing.T) {
+		opts := &BuildOpts{
+			ImageName:   "python:3.13",
+			TarFile:     filepath.Join(t.TempDir(), "image.tar"),
+			LayoutDir:   filepath.Join(t.TempDir(), "oci"),
+			Annotations: map[string]string{"org.opencontainers.image.version": "3.13"},
+		}
+		if err := exportIndex(t.Context(), opts, idx); err != nil {
+			t.Fatalf("exportIndex() error = %v", err)
+		}
+
+		expected, err := idx.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		store, err := oci_layout.NewStore(opts.LayoutDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc, err := store.Descriptor("python:3.13")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if desc.Digest != expected || desc.Annotations["org.opencontainers.image.version"] != "3.13" {
+			t.Errorf("unexpected layout entry %+v", desc)
+		}
+
+		artifact, artifactCleanup, err := oci_layout.ArtifactFromTar(opts.TarFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer artifactCleanup()
+		if digest, err := artifact.(v1.ImageIndex).Digest(); err != nil || digest != expected {
+			t.Errorf("expected tar to contain the merged index, got %v (%v)", digest, err)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package buildkit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/containerd/platforms"
+)
+
+// Nodes routes builds to the BuildKit endpoint responsible for a platform, similar to buildx multi-node builders.
+// Platforms without a dedicated node are built on the default client.
+type Nodes struct {
+	defaultClient *Client
+	byPlatform    map[string]*Client
+	clients       []*Client
+}
+
+// NewNodes creates the nodes with the client used for all platforms without a dedicated node
+func NewNodes(defaultClient *Client) *Nodes {
+	return &Nodes{
+		defaultClient: defaultClient,
+		byPlatform:    make(map[string]*Client),
+	}
+}
+
+// NormalizePlatform parses the platform, e.g. linux/arm64/v8 or arm64, and formats it as os/arch[/variant]
+func NormalizePlatform(platform string) (string, error) {
+	parsed, err := platforms.Parse(platform)
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("invalid platform %q", platform), err)
+	}
+	return platforms.Format(platforms.Normalize(parsed)), nil
+}
+
+// Add registers a node building the given platforms, the nodes take ownership of the client
+func (n *Nodes) Add(client *Client, nodePlatforms ...string) error {
+	n.clients = append(n.clients, client)
+	for _, platform := range nodePlatforms {
+		normalized, err := NormalizePlatform(platform)
+		if err != nil {
+			return err
+		}
+		if _, exists := n.byPlatform[normalized]; exists {
+			return fmt.Errorf("platform %s is assigned to multiple BuildKit nodes", normalized)
+		}
+		n.byPlatform[normalized] = client
+	}
+	return nil
+}
+
+// Default returns the client building all platforms without a dedicated node
+func (n *Nodes) Default() *Client {
+	return n.defaultClient
+}
+
+// ForPlatform returns the client building the platform
+func (n *Nodes) ForPlatform(platform string) (*Client, error) {
+	normalized, err := NormalizePlatform(platform)
+	if err != nil {
+		return nil, err
+	}
+	if client, ok := n.byPlatform[normalized]; ok {
+		return client, nil
+	}
+	return n.defaultClient, nil
+}
+
+// nodeGroup are the platforms built in a single solve on the same client
+type nodeGroup struct {
+	client    *Client
+	platforms []string
+}
+
+// group assigns the platforms to their nodes, keeping the order of the platforms
+func (n *Nodes) group(buildPlatforms []string) ([]*nodeGroup, error) {
+	var groups []*nodeGroup
+	byClient := make(map[*Client]*nodeGroup)
+	for _, platform := range buildPlatforms {
+		client, err := n.ForPlatform(platform)
+		if err != nil {
+			return nil, err
+		}
+		group, ok := byClient[client]
+		if !ok {
+			group = &nodeGroup{client: client}
+			byClient[client] = group
+			groups = append(groups, group)
+		}
+		group.platforms = append(group.platforms, platform)
+	}
+	return groups, nil
+}
+
+func (g *nodeGroup) platform() string {
+	return strings.Join(g.platforms, ",")
+}
+
+// Close closes the clients of all nodes, including the default client
+func (n *Nodes) Close() error {
+	errs := []error{n.defaultClient.Close()}
+	for _, client := range n.clients {
+		errs = append(errs, client.Close())
+	}
+	return errors.Join(errs...)
+}
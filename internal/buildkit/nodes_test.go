@@ -0,0 +1,85 @@
+package buildkit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNormalizePlatform(t *testing.T) {
+	tests := map[string]string{
+		"linux/amd64":    "linux/amd64",
+		"linux/arm64/v8": "linux/arm64",
+		"linux/aarch64":  "linux/arm64",
+		"linux/arm/v7":   "linux/arm/v7",
+	}
+
+	for platform, expected := range tests {
+		t.Run(platform, func(t *testing.T) {
+			got, err := NormalizePlatform(platform)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != expected {
+				t.Errorf("NormalizePlatform(%q) = %q, want %q", platform, got, expected)
+			}
+		})
+	}
+
+	if _, err := NormalizePlatform("linux/amd64/v2/extra"); err == nil {
+		t.Error("expected error for invalid platform")
+	}
+}
+
+func TestNodes(t *testing.T) {
+	defaultClient, amd64Node, arm64Node := &Client{}, &Client{}, &Client{}
+	nodes := NewNodes(defaultClient)
+	if err := nodes.Add(amd64Node, "linux/amd64"); err != nil {
+		t.Fatal(err)
+	}
+	if err := nodes.Add(arm64Node, "linux/arm64", "linux/arm/v7"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("duplicate platform", func(t *testing.T) {
+		err := nodes.Add(&Client{}, "linux/arm64/v8")
+		if err == nil || !strings.Contains(err.Error(), "assigned to multiple BuildKit nodes") {
+			t.Fatalf("expected duplicate platform error, got %v", err)
+		}
+	})
+
+	t.Run("platform routing", func(t *testing.T) {
+		for platform, expected := range map[string]*Client{
+			"linux/amd64":    amd64Node,
+			"linux/arm64/v8": arm64Node,
+			"linux/s390x":    defaultClient,
+		} {
+			client, err := nodes.ForPlatform(platform)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if client != expected {
+				t.Errorf("ForPlatform(%q) returned the wrong node", platform)
+			}
+		}
+	})
+
+	t.Run("grouping by node", func(t *testing.T) {
+		groups, err := nodes.group([]string{"linux/arm64", "linux/amd64", "linux/arm/v7", "linux/s390x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got [][]string
+		for _, group := range groups {
+			got = append(got, group.platforms)
+		}
+		expected := [][]string{{"linux/arm64", "linux/arm/v7"}, {"linux/amd64"}, {"linux/s390x"}}
+		if diff := cmp.Diff(expected, got); diff != "" {
+			t.Errorf("group() mismatch (-expected +got):\n%s", diff)
+		}
+		if groups[0].client != arm64Node || groups[0].platform() != "linux/arm64,linux/arm/v7" {
+			t.Errorf("unexpected first group %+v", groups[0])
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package buildkit
+
+import "github.com/moby/buildkit/client"
+
+// RedactStatusUpdates wraps a status update handler, masking all vertex names, errors, logs and warnings
+// using redact before passing them on.
+func RedactStatusUpdates(redact func(string) string, statusUpdateHandler func(chan *client.SolveStatus) error) func(chan *client.SolveStatus) error {
+	return func(statusUpdates chan *client.SolveStatus) error {
+		redacted := make(chan *client.SolveStatus)
+		go func() {
+			defer close(redacted)
+			for status := range statusUpdates {
+				redacted <- redactStatus(redact, status)
+			}
+		}()
+		err := statusUpdateHandler(redacted)
+		// Drain remaining updates in case the handler stopped early, so the solve is not blocked
+		for range redacted {
+		}
+		return err
+	}
+}
+
+func redactBytes(redact func(string) string, b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return []byte(redact(string(b)))
+}
+
+func redactStatus(redact func(string) string, status *client.SolveStatus) *client.SolveStatus {
+	redacted := &client.SolveStatus{
+		Vertexes: make([]*client.Vertex, 0, len(status.Vertexes)),
+		Statuses: make([]*client.VertexStatus, 0, len(status.Statuses)),
+		Logs:     make([]*client.VertexLog, 0, len(status.Logs)),
+		Warnings: make([]*client.VertexWarning, 0, len(status.Warnings)),
+	}
+
+	for _, v := range status.Vertexes {
+		vertex := *v
+		vertex.Name = redact(vertex.Name)
+		vertex.Error = redact(vertex.Error)
+		redacted.Vertexes = append(redacted.Vertexes, &vertex)
+	}
+
+	for _, s := range status.Statuses {
+		vertexStatus := *s
+		vertexStatus.ID = redact(vertexStatus.ID)
+		vertexStatus.Name = redact(vertexStatus.Name)
+		redacted.Statuses = append(redacted.Statuses, &vertexStatus)
+	}
+
+	for _, l := range status.Logs {
+		vertexLog := *l
+		vertexLog.Data = redactBytes(redact, vertexLog.Data)
+		redacted.Logs = append(redacted.Logs, &vertexLog)
+	}
+
+	for _, w := range status.Warnings {
+		warning := *w
+		warning.Short = redactBytes(redact, warning.Short)
+		warning.Detail = make([][]byte, 0, len(w.Detail))
+		for _, detail := range w.Detail {
+			warning.Detail = append(warning.Detail, redactBytes(redact, detail))
+		}
+		redacted.Warnings = append(redacted.Warnings, &warning)
+	}
+
+	return redacted
+}
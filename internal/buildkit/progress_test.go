@@ -0,0 +1,55 @@
+package buildkit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moby/buildkit/client"
+)
+
+func TestRedactStatusUpdates(t *testing.T) {
+	redact := func(s string) string {
+		return strings.ReplaceAll(s, "s3cr3t", "***")
+	}
+
+	var received []*client.SolveStatus
+	handler := RedactStatusUpdates(redact, func(ch chan *client.SolveStatus) error {
+		for status := range ch {
+			received = append(received, status)
+		}
+		return nil
+	})
+
+	statusUpdates := make(chan *client.SolveStatus, 1)
+	statusUpdates <- &client.SolveStatus{
+		Vertexes: []*client.Vertex{{Name: "RUN echo s3cr3t", Error: "failed with s3cr3t"}},
+		Statuses: []*client.VertexStatus{{ID: "s3cr3t", Name: "status s3cr3t"}},
+		Logs:     []*client.VertexLog{{Data: []byte("log s3cr3t\n")}},
+		Warnings: []*client.VertexWarning{{Short: []byte("warn s3cr3t"), Detail: [][]byte{[]byte("detail s3cr3t")}}},
+	}
+	close(statusUpdates)
+
+	if err := handler(statusUpdates); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("expected 1 status update, got %d", len(received))
+	}
+
+	status := received[0]
+	values := []string{
+		status.Vertexes[0].Name,
+		status.Vertexes[0].Error,
+		status.Statuses[0].ID,
+		status.Statuses[0].Name,
+		string(status.Logs[0].Data),
+		string(status.Warnings[0].Short),
+		string(status.Warnings[0].Detail[0]),
+	}
+	for _, v := range values {
+		if strings.Contains(v, "s3cr3t") {
+			t.Errorf("expected secret to be redacted in %q", v)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package buildkit
+
+import (
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/sshforward/sshprovider"
+)
+
+// SSHForward exposes an ssh agent or private keys to RUN --mount=type=ssh,id=<ID>
+type SSHForward struct {
+	// ID referenced by the mount, defaults to "default"
+	ID string
+	// Paths of agent sockets or private key files, defaults to the agent socket from SSH_AUTH_SOCK
+	Paths []string
+}
+
+func newSSHProvider(forwards []SSHForward) (session.Attachable, error) {
+	configs := make([]sshprovider.AgentConfig, 0, len(forwards))
+	for _, forward := range forwards {
+		configs = append(configs, sshprovider.AgentConfig{
+			ID:    forward.ID,
+			Paths: forward.Paths,
+		})
+	}
+	return sshprovider.NewSSHAgentProvider(configs)
+}
@@ -0,0 +1,82 @@
+package buildkit
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestSSHKey(t *testing.T) string {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewSSHProvider(t *testing.T) {
+	keyFile := writeTestSSHKey(t)
+
+	tests := []struct {
+		name          string
+		forwards      []SSHForward
+		errorContains string
+	}{
+		{
+			name:     "key file",
+			forwards: []SSHForward{{ID: "github", Paths: []string{keyFile}}},
+		},
+		{
+			name:     "default id",
+			forwards: []SSHForward{{Paths: []string{keyFile}}},
+		},
+		{
+			name: "duplicate id",
+			forwards: []SSHForward{
+				{Paths: []string{keyFile}},
+				{ID: "default", Paths: []string{keyFile}},
+			},
+			errorContains: "duplicate agent ID",
+		},
+		{
+			name:          "missing key file",
+			forwards:      []SSHForward{{ID: "github", Paths: []string{filepath.Join(t.TempDir(), "missing")}}},
+			errorContains: "missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := newSSHProvider(tt.forwards)
+			if tt.errorContains != "" {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("expected error containing %q, got %q", tt.errorContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if provider == nil {
+				t.Error("expected provider but got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package buildkitd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+const (
+	socketName  = "buildkitd.sock"
+	logFileName = "buildkitd.log"
+)
+
+// Daemon is a buildkitd started as local process for development builds,
+// running rootless via rootlesskit unless ContainerHive itself runs as root.
+type Daemon struct {
+	stateDir string
+	// insecureEntitlements builds are allowed to request
+	insecureEntitlements []string
+	cmd                  *exec.Cmd
+	exited               chan struct{}
+	// lookPath resolves the binaries, replaced in tests
+	lookPath func(file string) (string, error)
+}
+
+// NewDaemon creates a daemon keeping its state and socket in stateDir.
+// Only the given insecure entitlements are allowed, e.g. the ones requested by the images to build.
+func NewDaemon(stateDir string, insecureEntitlements ...string) *Daemon {
+	return &Daemon{stateDir: stateDir, insecureEntitlements: insecureEntitlements, lookPath: exec.LookPath}
+}
+
+// DefaultStateDir returns the per-user state directory, container-hive/buildkitd in the user cache directory
+func DefaultStateDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.Join(errors.New("failed to determine user cache directory"), err)
+	}
+	return filepath.Join(cacheDir, "container-hive", "buildkitd"), nil
+}
+
+func (d *Daemon) socketPath() string {
+	return filepath.Join(d.stateDir, socketName)
+}
+
+// Address of the unix socket the daemon listens on
+func (d *Daemon) Address() string {
+	return "unix://" + d.socketPath()
+}
+
+// command returns the command line to start buildkitd
+func (d *Daemon) command(rootless bool) ([]string, error) {
+	buildkitd, err := d.lookPath("buildkitd")
+	if err != nil {
+		return nil, errors.Join(errors.New("buildkitd not found in PATH, install BuildKit to start the daemon automatically"), err)
+	}
+
+	args := []string{buildkitd, "--addr", d.Address(), "--root", filepath.Join(d.stateDir, "root")}
+	// builds still have to request entitlements explicitly in their build settings
+	for _, entitlement := range d.insecureEntitlements {
+		args = append(args, "--allow-insecure-entitlement", entitlement)
+	}
+	if !rootless {
+		return args, nil
+	}
+
+	rootlesskit, err := d.lookPath("rootlesskit")
+	if err != nil {
+		return nil, errors.Join(errors.New("rootlesskit not found in PATH, it is required to start buildkitd rootless"), err)
+	}
+	// ROOTLESSKIT_STATE_DIR is set by rootlesskit, so buildkitd runs in rootless mode
+	return append([]string{rootlesskit, "--state-dir", filepath.Join(d.stateDir, "rootlesskit")}, append(args, "--oci-worker-no-process-sandbox")...), nil
+}
+
+// reachable reports if a daemon accepts connections on the socket
+func (d *Daemon) reachable() bool {
+	conn, err := net.DialTimeout("unix", d.socketPath(), time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Start launches buildkitd and waits until its socket accepts connections.
+// A daemon already listening on the socket, e.g. started by a concurrent run, is reused and not stopped,
+// even if it allows different insecure entitlements.
+func (d *Daemon) Start(ctx context.Context) error {
+	if err := os.MkdirAll(d.stateDir, 0700); err != nil {
+		return errors.Join(errors.New("failed to create buildkitd state directory"), err)
+	}
+	if d.reachable() {
+		return nil
+	}
+	// a socket left behind by a daemon that was not stopped properly
+	os.Remove(d.socketPath())
+
+	args, err := d.command(os.Geteuid() != 0)
+	if err != nil {
+		return err
+	}
+
+	logFile, err := os.OpenFile(filepath.Join(d.stateDir, logFileName), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Join(errors.New("failed to create buildkitd log file"), err)
+	}
+	defer logFile.Close()
+
+	// not bound to ctx, the daemon is stopped explicitly or by the interrupt sent to the whole process group
+	d.cmd = exec.Command(args[0], args[1:]...)
+	d.cmd.Stdout = logFile
+	d.cmd.Stderr = logFile
+	if err := d.cmd.Start(); err != nil {
+		return errors.Join(errors.New("failed to start buildkitd"), err)
+	}
+
+	d.exited = make(chan struct{})
+	go func() {
+		d.cmd.Wait()
+		close(d.exited)
+	}()
+
+	if err := d.waitForReady(ctx); err != nil {
+		d.Stop(ctx)
+		return errors.Join(fmt.Errorf("buildkitd failed to become ready, see %s", logFile.Name()), err)
+	}
+	return nil
+}
+
+func (d *Daemon) waitForReady(ctx context.Context) error {
+	deadline := time.After(30 * time.Second)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return errors.New("timeout waiting for buildkitd to start")
+		case <-d.exited:
+			return errors.New("buildkitd exited unexpectedly")
+		case <-ticker.C:
+			if d.reachable() {
+				return nil
+			}
+		}
+	}
+}
+
+// Stop terminates the daemon if it was started by Start, killing it if it does not exit within ten seconds
+func (d *Daemon) Stop(_ context.Context) error {
+	if d.cmd == nil || d.cmd.Process == nil {
+		return nil
+	}
+	select {
+	case <-d.exited:
+		return nil
+	default:
+	}
+
+	if err := d.cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		return errors.Join(errors.New("failed to stop buildkitd"), err)
+	}
+	select {
+	case <-d.exited:
+		return nil
+	case <-time.After(10 * time.Second):
+		return d.cmd.Process.Kill()
+	}
+}
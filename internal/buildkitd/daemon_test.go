@@ -0,0 +1,120 @@
+package buildkitd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func fakeLookPath(available ...string) func(string) (string, error) {
+	return func(file string) (string, error) {
+		for _, name := range available {
+			if name == file {
+				return "/usr/bin/" + file, nil
+			}
+		}
+		return "", errors.New("executable file not found in $PATH")
+	}
+}
+
+func TestDaemonCommand(t *testing.T) {
+	tests := []struct {
+		name          string
+		rootless      bool
+		entitlements  []string
+		available     []string
+		expected      []string
+		errorContains string
+	}{
+		{
+			name:      "root",
+			available: []string{"buildkitd"},
+			expected: []string{
+				"/usr/bin/buildkitd", "--addr", "unix:///state/buildkitd.sock", "--root", "/state/root",
+			},
+		},
+		{
+			name:         "root with entitlements",
+			entitlements: []string{"network.host", "security.insecure"},
+			available:    []string{"buildkitd"},
+			expected: []string{
+				"/usr/bin/buildkitd", "--addr", "unix:///state/buildkitd.sock", "--root", "/state/root",
+				"--allow-insecure-entitlement", "network.host", "--allow-insecure-entitlement", "security.insecure",
+			},
+		},
+		{
+			name:         "rootless",
+			rootless:     true,
+			entitlements: []string{"network.host"},
+			available:    []string{"buildkitd", "rootlesskit"},
+			expected: []string{
+				"/usr/bin/rootlesskit", "--state-dir", "/state/rootlesskit",
+				"/usr/bin/buildkitd", "--addr", "unix:///state/buildkitd.sock", "--root", "/state/root",
+				"--allow-insecure-entitlement", "network.host",
+				"--oci-worker-no-process-sandbox",
+			},
+		},
+		{
+			name:          "buildkitd missing",
+			errorContains: "buildkitd not found",
+		},
+		{
+			name:          "rootlesskit missing",
+			rootless:      true,
+			available:     []string{"buildkitd"},
+			errorContains: "rootlesskit not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			daemon := NewDaemon("/state", tt.entitlements...)
+			daemon.lookPath = fakeLookPath(tt.available...)
+
+			args, err := daemon.command(tt.rootless)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, args); diff != "" {
+				t.Errorf("command() mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDaemonReusesRunningDaemon(t *testing.T) {
+	stateDir := t.TempDir()
+	listener, err := net.Listen("unix", filepath.Join(stateDir, socketName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	daemon := NewDaemon(stateDir)
+	daemon.lookPath = fakeLookPath()
+	if err := daemon.Start(context.Background()); err != nil {
+		t.Fatalf("expected running daemon to be reused, got %v", err)
+	}
+	if err := daemon.Stop(context.Background()); err != nil {
+		t.Fatalf("stopping a reused daemon should be a no-op, got %v", err)
+	}
+}
+
+func TestDaemonStartWithoutBuildkitd(t *testing.T) {
+	daemon := NewDaemon(t.TempDir())
+	daemon.lookPath = fakeLookPath()
+	if err := daemon.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "buildkitd not found") {
+		t.Fatalf("expected missing buildkitd error, got %v", err)
+	}
+}
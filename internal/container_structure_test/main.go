@@ -2,6 +2,7 @@ package container_structure_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,14 +12,21 @@ import (
 	"github.com/GoogleContainerTools/container-structure-test/pkg/drivers"
 	"github.com/GoogleContainerTools/container-structure-test/pkg/types/unversioned"
 	"github.com/timo-reymann/ContainerHive/internal/docker"
+	"github.com/timo-reymann/ContainerHive/internal/oci_layout"
+	"github.com/timo-reymann/ContainerHive/internal/secrets"
 )
 
 type TestRunner struct {
 	TestDefinitionPaths []string
-	Image               string
-	Platform            string
-	ReportFile          string
-	DockerClient        *docker.Client
+	// Image is an OCI tar, a name in Store or an image known to Docker
+	Image string
+	// Store to load Image from, optional
+	Store        *oci_layout.Store
+	Platform     string
+	ReportFile   string
+	DockerClient *docker.Client
+	// Redactor masks secret values in the written report, optional
+	Redactor *secrets.Redactor
 }
 
 func (t *TestRunner) getOptions(output unversioned.OutputValue) *config.StructureTestOptions {
@@ -40,6 +48,9 @@ func (t *TestRunner) isTar() bool {
 }
 
 func (t *TestRunner) resolveImageName(ctx context.Context) (string, error) {
+	if t.Store != nil {
+		return t.DockerClient.LoadImageFromStore(ctx, t.Store, t.Image)
+	}
 	if t.isTar() {
 		return t.DockerClient.LoadImageFromTar(ctx, t.Image)
 	}
@@ -87,5 +98,11 @@ func (t *TestRunner) Run() error {
 	}
 	defer testReportFile.Close()
 
-	return test.ProcessResults(testReportFile, unversioned.Junit, opts.JunitSuiteName, channel)
+	if t.Redactor == nil {
+		return test.ProcessResults(testReportFile, unversioned.Junit, opts.JunitSuiteName, channel)
+	}
+
+	report := t.Redactor.Writer(testReportFile)
+	err = test.ProcessResults(report, unversioned.Junit, opts.JunitSuiteName, channel)
+	return errors.Join(err, report.Close())
 }
@@ -67,7 +67,7 @@ func TestIntegrationContainerStructureTest(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	bkClient, err := buildkit.NewClient(ctx, fmt.Sprintf("tcp://%s:%s", buildkitHost, buildkitPort.Port()))
+	bkClient, err := buildkit.NewClient(ctx, buildkit.ConnectionConfig{Address: fmt.Sprintf("tcp://%s:%s", buildkitHost, buildkitPort.Port())})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -120,7 +120,7 @@ func TestIntegrationContainerStructureTest(t *testing.T) {
 	}
 
 	tarFile := filepath.Join(t.TempDir(), "image.tar")
-	err = bkClient.Build(ctx, &buildkit.BuildOpts{
+	_, err = bkClient.Build(ctx, &buildkit.BuildOpts{
 		ImageName: "cst-test:latest",
 		TarFile:   tarFile,
 		BuildContext: &build_context.DockerfileBuildContext{
@@ -115,3 +115,53 @@ func (g *Graph) TopologicalSort() ([]string, error) {
 
 	return order, nil
 }
+
+// Expand returns the given images together with all images they transitively depend on and/or
+// all images transitively depending on them, sorted by name.
+func (g *Graph) Expand(names []string, withDependencies, withDependents bool) []string {
+	expanded := make(map[string]bool)
+	queue := slices.Clone(names)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if expanded[name] {
+			continue
+		}
+		expanded[name] = true
+
+		if withDependencies {
+			queue = append(queue, g.Dependencies(name)...)
+		}
+		if withDependents {
+			queue = append(queue, g.Dependents(name)...)
+		}
+	}
+
+	result := make([]string, 0, len(expanded))
+	for name := range expanded {
+		result = append(result, name)
+	}
+	slices.Sort(result)
+	return result
+}
+
+// Subgraph returns the graph restricted to the given images and the dependencies between them.
+func (g *Graph) Subgraph(names []string) *Graph {
+	sub := NewGraph()
+	for _, name := range names {
+		if g.nodes[name] {
+			sub.AddImage(name)
+		}
+	}
+	for from, deps := range g.edges {
+		if !sub.nodes[from] {
+			continue
+		}
+		for _, to := range deps {
+			if sub.nodes[to] {
+				sub.AddDependency(from, to)
+			}
+		}
+	}
+	return sub
+}
@@ -1,6 +1,7 @@
 package dependency
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -168,3 +169,52 @@ func TestGraph_HasDependencies(t *testing.T) {
 		}
 	})
 }
+
+// chainGraph creates base <- middle <- top with an unrelated standalone image
+func chainGraph() *Graph {
+	g := NewGraph()
+	for _, name := range []string{"base", "middle", "top", "standalone"} {
+		g.AddImage(name)
+	}
+	g.AddDependency("middle", "base")
+	g.AddDependency("top", "middle")
+	return g
+}
+
+func TestGraph_Expand(t *testing.T) {
+	t.Run("without expansion", func(t *testing.T) {
+		expanded := chainGraph().Expand([]string{"middle"}, false, false)
+		if !slices.Equal(expanded, []string{"middle"}) {
+			t.Errorf("unexpected images %v", expanded)
+		}
+	})
+
+	t.Run("with dependencies", func(t *testing.T) {
+		expanded := chainGraph().Expand([]string{"top"}, true, false)
+		if !slices.Equal(expanded, []string{"base", "middle", "top"}) {
+			t.Errorf("unexpected images %v", expanded)
+		}
+	})
+
+	t.Run("with dependents", func(t *testing.T) {
+		expanded := chainGraph().Expand([]string{"base", "standalone"}, false, true)
+		if !slices.Equal(expanded, []string{"base", "middle", "standalone", "top"}) {
+			t.Errorf("unexpected images %v", expanded)
+		}
+	})
+}
+
+func TestGraph_Subgraph(t *testing.T) {
+	sub := chainGraph().Subgraph([]string{"middle", "top", "unknown"})
+
+	order, err := sub.TopologicalSort()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(order, []string{"middle", "top"}) {
+		t.Errorf("unexpected order %v", order)
+	}
+	if len(sub.Dependencies("middle")) != 0 {
+		t.Errorf("dependency on unselected image should be dropped, got %v", sub.Dependencies("middle"))
+	}
+}
@@ -62,3 +62,33 @@ func TestIntegration_DependencyProject(t *testing.T) {
 		t.Errorf("ubuntu (idx=%d) must come before python (idx=%d)", indexOf("ubuntu"), indexOf("python"))
 	}
 }
+
+func TestIntegration_ScanProject(t *testing.T) {
+	tests := []struct {
+		name       string
+		project    string
+		dependent  string
+		dependency string
+	}{
+		{name: "plain Dockerfile", project: "dependency-project", dependent: "python", dependency: "ubuntu"},
+		{name: "resolve_base template", project: "dependency-template-project", dependent: "app", dependency: "ubuntu"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, err := discovery.DiscoverProject(t.Context(), mustAbs(t, filepath.Join("../../pkg/testdata", tt.project)))
+			if err != nil {
+				t.Fatalf("discovery failed: %v", err)
+			}
+
+			graph, err := ScanProject(project)
+			if err != nil {
+				t.Fatalf("scanning failed: %v", err)
+			}
+
+			if deps := graph.Dependencies(tt.dependent); len(deps) != 1 || deps[0] != tt.dependency {
+				t.Errorf("expected %s to depend on %s, got %v", tt.dependent, tt.dependency, deps)
+			}
+		})
+	}
+}
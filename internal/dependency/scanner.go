@@ -5,13 +5,21 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/timo-reymann/ContainerHive/internal/buildconfig_resolver"
+	"github.com/timo-reymann/ContainerHive/internal/file_resolver"
+	"github.com/timo-reymann/ContainerHive/internal/file_resolver/templating"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
 )
 
 const HivePrefix = "__hive__/"
 
 var hiveFromPattern = regexp.MustCompile(`(?i)^FROM\s+__hive__/([^:\s]+):([^\s]+)`)
 
+var fromPattern = regexp.MustCompile(`(?i)^FROM\s`)
+
 // HiveRef represents a reference to a project-local image via the __hive__/ prefix.
 type HiveRef struct {
 	ImageName string
@@ -24,7 +32,11 @@ func ScanDockerfileForHiveRefs(dockerfilePath string) ([]HiveRef, error) {
 	if err != nil {
 		return nil, err
 	}
+	return ScanHiveRefs(content), nil
+}
 
+// ScanHiveRefs scans the content of a Dockerfile for FROM __hive__/<name>:<tag> references.
+func ScanHiveRefs(content []byte) []HiveRef {
 	var refs []HiveRef
 	for _, line := range strings.Split(string(content), "\n") {
 		line = strings.TrimSpace(line)
@@ -36,7 +48,57 @@ func ScanDockerfileForHiveRefs(dockerfilePath string) ([]HiveRef, error) {
 			})
 		}
 	}
-	return refs, nil
+	return refs
+}
+
+// parseHiveRef parses a __hive__/<name>:<tag> image reference, nil for any other image
+func parseHiveRef(image string) *HiveRef {
+	name, tag, ok := strings.Cut(strings.TrimPrefix(image, HivePrefix), ":")
+	if !strings.HasPrefix(image, HivePrefix) || !ok || name == "" || tag == "" {
+		return nil
+	}
+	return &HiveRef{ImageName: name, Tag: tag}
+}
+
+// FinalStageHiveRef returns the __hive__/ reference the final stage of a Dockerfile is based on,
+// nil if the final stage uses any other base image. Flags like --platform are skipped and stages based on an
+// earlier stage resolve to the base of that stage.
+func FinalStageHiveRef(dockerfilePath string) (*HiveRef, error) {
+	content, err := os.ReadFile(dockerfilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var ref *HiveRef
+	stages := make(map[string]*HiveRef)
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if !fromPattern.MatchString(line) {
+			continue
+		}
+
+		// FROM [--flag=value ...] <image> [AS <name>]
+		var args []string
+		for _, field := range strings.Fields(line)[1:] {
+			if !strings.HasPrefix(field, "--") {
+				args = append(args, field)
+			}
+		}
+		if len(args) == 0 {
+			ref = nil
+			continue
+		}
+
+		if stageRef, isStage := stages[strings.ToLower(args[0])]; isStage {
+			ref = stageRef
+		} else {
+			ref = parseHiveRef(args[0])
+		}
+		if len(args) == 3 && strings.EqualFold(args[1], "AS") {
+			stages[strings.ToLower(args[2])] = ref
+		}
+	}
+	return ref, nil
 }
 
 // ScanRenderedProject scans all Dockerfiles in a rendered dist directory
@@ -94,3 +156,47 @@ func ScanRenderedProject(distPath string) (*Graph, error) {
 
 	return graph, nil
 }
+
+// ScanProject renders the Dockerfiles of all tags and variants in memory and builds a dependency graph based on
+// __hive__/ references, without writing a dist directory.
+func ScanProject(project *model.ContainerHiveProject) (*Graph, error) {
+	graph := NewGraph()
+	for name := range project.ImagesByName {
+		graph.AddImage(name)
+	}
+
+	scan := func(image *model.Image, values *buildconfig_resolver.ResolvedBuildValues, dockerfilePath string) error {
+		if dockerfilePath == "" {
+			return nil
+		}
+		rendered, err := file_resolver.RenderFile(&templating.TemplateContext{
+			ImageName: image.Name,
+			Versions:  values.Versions,
+			BuildArgs: values.BuildArgs,
+		}, dockerfilePath)
+		if err != nil {
+			return errors.Join(errors.New("failed to render "+dockerfilePath), err)
+		}
+		for _, ref := range ScanHiveRefs(rendered) {
+			if !slices.Contains(graph.Dependencies(image.Name), ref.ImageName) {
+				graph.AddDependency(image.Name, ref.ImageName)
+			}
+		}
+		return nil
+	}
+
+	for _, image := range project.ImagesByIdentifier {
+		for _, tag := range image.Tags {
+			if err := scan(image, buildconfig_resolver.ForTag(image, tag), image.BuildEntryPointPath); err != nil {
+				return nil, err
+			}
+			for _, variant := range image.Variants {
+				if err := scan(image, buildconfig_resolver.ForTagVariant(image, variant, tag), variant.BuildEntryPointPath); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return graph, nil
+}
@@ -71,6 +71,67 @@ func TestScanDockerfileForHiveRefs(t *testing.T) {
 	})
 }
 
+func TestFinalStageHiveRef(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected *HiveRef
+	}{
+		{
+			name:     "single stage",
+			content:  "FROM __hive__/ubuntu:22.04\nRUN echo hello",
+			expected: &HiveRef{ImageName: "ubuntu", Tag: "22.04"},
+		},
+		{
+			name:     "hive image in final stage",
+			content:  "FROM golang:1.25 AS builder\nFROM __hive__/base:1.0\nCOPY --from=builder /app /app",
+			expected: &HiveRef{ImageName: "base", Tag: "1.0"},
+		},
+		{
+			name:    "hive image only in build stage",
+			content: "FROM __hive__/base:1.0 AS builder\nFROM scratch\nCOPY --from=builder /app /app",
+		},
+		{
+			name:    "no hive image",
+			content: "FROM ubuntu:22.04",
+		},
+		{
+			name:     "platform flag",
+			content:  "FROM --platform=$BUILDPLATFORM __hive__/base:1.0\nRUN echo hello",
+			expected: &HiveRef{ImageName: "base", Tag: "1.0"},
+		},
+		{
+			name:     "final stage from hive stage alias",
+			content:  "FROM __hive__/base:1.0 AS runtime\nRUN echo hello\nFROM golang:1.25 AS builder\nFROM runtime\nCOPY --from=builder /app /app",
+			expected: &HiveRef{ImageName: "base", Tag: "1.0"},
+		},
+		{
+			name:     "final stage from chained aliases with flags",
+			content:  "FROM --platform=linux/amd64 __hive__/base:1.0 as base\nFROM base AS runtime\nFROM --platform=linux/amd64 Runtime",
+			expected: &HiveRef{ImageName: "base", Tag: "1.0"},
+		},
+		{
+			name:    "final stage from non hive stage alias",
+			content: "FROM __hive__/base:1.0 AS builder\nFROM ubuntu:22.04 AS runtime\nFROM runtime",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df := filepath.Join(t.TempDir(), "Dockerfile")
+			os.WriteFile(df, []byte(tt.content), 0644)
+
+			ref, err := FinalStageHiveRef(df)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (ref == nil) != (tt.expected == nil) || (ref != nil && *ref != *tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, ref)
+			}
+		})
+	}
+}
+
 func TestScanRenderedProject(t *testing.T) {
 	t.Run("builds graph from rendered dist directory", func(t *testing.T) {
 		dir := t.TempDir()
@@ -3,12 +3,12 @@ package docker
 import (
 	"context"
 	"errors"
-	"os"
 
 	dockerClient "github.com/docker/docker/client"
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/daemon"
-	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/timo-reymann/ContainerHive/internal/oci_layout"
 )
 
 type Client struct {
@@ -30,54 +30,40 @@ func NewClient() (*Client, error) {
 	}, nil
 }
 
+// LoadImageFromTar loads the image of an OCI tar into Docker and returns its name
 func (c *Client) LoadImageFromTar(_ context.Context, tarPath string) (string, error) {
-	tmpDir, err := os.MkdirTemp("", "oci-layout-*")
+	img, imageName, cleanup, err := oci_layout.ImageFromTar(tarPath)
 	if err != nil {
 		return "", err
 	}
-	defer os.RemoveAll(tmpDir)
+	defer cleanup()
 
-	if err := extractTar(tarPath, tmpDir); err != nil {
-		return "", errors.Join(errors.New("failed to extract OCI tar"), err)
-	}
-
-	layoutPath, err := layout.FromPath(tmpDir)
-	if err != nil {
-		return "", errors.Join(errors.New("failed to read OCI layout"), err)
+	if imageName == "" {
+		return "", errors.New("no image name annotation in OCI index")
 	}
 
-	idx, err := layoutPath.ImageIndex()
-	if err != nil {
-		return "", err
-	}
+	return imageName, c.load(imageName, img)
+}
 
-	idxManifest, err := idx.IndexManifest()
+// LoadImageFromStore loads the image with the given name from the OCI layout store into Docker
+func (c *Client) LoadImageFromStore(_ context.Context, store *oci_layout.Store, imageName string) (string, error) {
+	img, err := store.Image(imageName)
 	if err != nil {
 		return "", err
 	}
 
-	if len(idxManifest.Manifests) == 0 {
-		return "", errors.New("no manifests in OCI layout")
-	}
-
-	imageName, ok := idxManifest.Manifests[0].Annotations["io.containerd.image.name"]
-	if !ok || imageName == "" {
-		return "", errors.New("no image name annotation in OCI index")
-	}
-
-	img, err := layoutPath.Image(idxManifest.Manifests[0].Digest)
-	if err != nil {
-		return "", errors.Join(errors.New("failed to read image from layout"), err)
-	}
+	return imageName, c.load(imageName, img)
+}
 
+func (c *Client) load(imageName string, img v1.Image) error {
 	tag, err := name.NewTag(imageName)
 	if err != nil {
-		return "", errors.Join(errors.New("invalid image name"), err)
+		return errors.Join(errors.New("invalid image name"), err)
 	}
 
 	if _, err := daemon.Write(tag, img); err != nil {
-		return "", errors.Join(errors.New("failed to load image into Docker"), err)
+		return errors.Join(errors.New("failed to load image into Docker"), err)
 	}
 
-	return imageName, nil
+	return nil
 }
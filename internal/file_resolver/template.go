@@ -17,21 +17,30 @@ func CopyAndRenderFile(tmplCtx *templating.TemplateContext, src, target string)
 		return err
 	}
 
-	processor, ok := processorMapping[ext]
-	if !ok {
+	if _, ok := processorMapping[ext]; !ok {
 		_, err := fileutils.CopyFile(src, target)
 		return err
 
 	}
 
-	content, err := os.ReadFile(src)
+	rendered, err := RenderFile(tmplCtx, src)
 	if err != nil {
 		return err
 	}
+	return os.WriteFile(target, rendered, 0644)
+}
 
-	rendered, err := processor.Process(tmplCtx, src, content)
+// RenderFile returns the content of the file, rendered with the context when it has a template extension
+func RenderFile(tmplCtx *templating.TemplateContext, src string) ([]byte, error) {
+	content, err := os.ReadFile(src)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return os.WriteFile(target, rendered, 0644)
+
+	ext, _ := strings.CutPrefix(filepath.Ext(src), ".")
+	processor, ok := processorMapping[ext]
+	if !ok {
+		return content, nil
+	}
+	return processor.Process(tmplCtx, src, content)
 }
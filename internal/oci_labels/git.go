@@ -0,0 +1,59 @@
+package oci_labels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("failed to run git %s in %s", strings.Join(args, " "), dir), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitRevision returns the sha of the checked out commit
+func GitRevision(ctx context.Context, dir string) (string, error) {
+	return git(ctx, dir, "rev-parse", "HEAD")
+}
+
+// GitSourceURL returns the URL of the origin remote, converted to a browsable https URL
+func GitSourceURL(ctx context.Context, dir string) (string, error) {
+	remote, err := git(ctx, dir, "config", "--get", "remote.origin.url")
+	if err != nil {
+		return "", err
+	}
+	return normalizeRemoteURL(remote), nil
+}
+
+// normalizeRemoteURL converts scp-like and ssh remotes to https and strips credentials and the .git suffix
+func normalizeRemoteURL(remote string) string {
+	remote = strings.TrimSuffix(remote, ".git")
+
+	if !strings.Contains(remote, "://") {
+		// scp-like syntax, e.g. git@github.com:org/repo
+		host, path, found := strings.Cut(remote, ":")
+		if !found {
+			return remote
+		}
+		remote = "ssh://" + host + "/" + path
+	}
+
+	parsed, err := url.Parse(remote)
+	if err != nil {
+		return remote
+	}
+	parsed.User = nil
+	if parsed.Scheme == "ssh" || parsed.Scheme == "git" {
+		parsed.Scheme = "https"
+		parsed.Host = parsed.Hostname()
+	}
+	return parsed.String()
+}
@@ -0,0 +1,77 @@
+package oci_labels
+
+import (
+	"strings"
+	"time"
+
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// Prefix of the pre-defined annotation keys of the OCI image spec
+const Prefix = "org.opencontainers.image."
+
+const (
+	Title      = Prefix + "title"
+	Version    = Prefix + "version"
+	Revision   = Prefix + "revision"
+	Source     = Prefix + "source"
+	Created    = Prefix + "created"
+	BaseName   = Prefix + "base.name"
+	BaseDigest = Prefix + "base.digest"
+)
+
+// Metadata describes an image build, empty values are omitted from the labels
+type Metadata struct {
+	Title    string
+	Version  string
+	Revision string
+	Source   string
+	// Created is the unix timestamp of the image creation, usually the SOURCE_DATE_EPOCH
+	Created    int64
+	BaseName   string
+	BaseDigest string
+}
+
+// ToLabels converts the metadata to the standard OCI labels
+func (m *Metadata) ToLabels() model.Labels {
+	labels := make(model.Labels)
+	set := func(key, value string) {
+		if value != "" {
+			labels[key] = value
+		}
+	}
+
+	set(Title, m.Title)
+	set(Version, m.Version)
+	set(Revision, m.Revision)
+	set(Source, m.Source)
+	if m.Created != 0 {
+		labels[Created] = time.Unix(m.Created, 0).UTC().Format(time.RFC3339)
+	}
+	set(BaseName, m.BaseName)
+	set(BaseDigest, m.BaseDigest)
+
+	return labels
+}
+
+// Merge merges the automatic labels with the user defined ones, later ones overriding earlier ones with the same key
+func Merge(automatic model.Labels, user ...model.Labels) model.Labels {
+	merged := make(model.Labels, len(automatic))
+	for _, labels := range append([]model.Labels{automatic}, user...) {
+		for k, v := range labels {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// Annotations returns the labels using the OCI prefix, which are also added as manifest annotations
+func Annotations(labels model.Labels) map[string]string {
+	annotations := make(map[string]string)
+	for k, v := range labels {
+		if strings.HasPrefix(k, Prefix) {
+			annotations[k] = v
+		}
+	}
+	return annotations
+}
@@ -0,0 +1,94 @@
+package oci_labels
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+func TestMetadataToLabels(t *testing.T) {
+	tests := map[string]struct {
+		metadata *Metadata
+		expected model.Labels
+	}{
+		"empty": {
+			metadata: &Metadata{},
+			expected: model.Labels{},
+		},
+		"all values": {
+			metadata: &Metadata{
+				Title:      "python",
+				Version:    "3.13",
+				Revision:   "0123456789abcdef",
+				Source:     "https://github.com/org/images",
+				Created:    1700000000,
+				BaseName:   "localhost:5000/base:1.0",
+				BaseDigest: "sha256:abc",
+			},
+			expected: model.Labels{
+				"org.opencontainers.image.title":       "python",
+				"org.opencontainers.image.version":     "3.13",
+				"org.opencontainers.image.revision":    "0123456789abcdef",
+				"org.opencontainers.image.source":      "https://github.com/org/images",
+				"org.opencontainers.image.created":     "2023-11-14T22:13:20Z",
+				"org.opencontainers.image.base.name":   "localhost:5000/base:1.0",
+				"org.opencontainers.image.base.digest": "sha256:abc",
+			},
+		},
+		"without git": {
+			metadata: &Metadata{Title: "python", Version: "3.13", Created: 1700000000},
+			expected: model.Labels{
+				"org.opencontainers.image.title":   "python",
+				"org.opencontainers.image.version": "3.13",
+				"org.opencontainers.image.created": "2023-11-14T22:13:20Z",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, tc.metadata.ToLabels()); diff != "" {
+				t.Errorf("ToLabels() mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	tests := map[string]struct {
+		automatic model.Labels
+		user      []model.Labels
+		expected  model.Labels
+	}{
+		"automatic only": {
+			automatic: model.Labels{Title: "python"},
+			expected:  model.Labels{Title: "python"},
+		},
+		"user labels override automatic and earlier ones": {
+			automatic: model.Labels{Title: "python", Version: "3.13"},
+			user: []model.Labels{
+				{Title: "Python", "team": "platform"},
+				nil,
+				{"team": "data"},
+			},
+			expected: model.Labels{Title: "Python", Version: "3.13", "team": "data"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, Merge(tc.automatic, tc.user...)); diff != "" {
+				t.Errorf("Merge() mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	got := Annotations(model.Labels{Title: "python", "team": "platform", Version: "3.13"})
+	expected := map[string]string{Title: "python", Version: "3.13"}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Annotations() mismatch (-expected +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,57 @@
+package oci_layout
+
+import (
+	"errors"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/timo-reymann/ContainerHive/internal/utils"
+)
+
+// AppendIndex adds the multi-platform image index to the layout directory, annotated with the image name like BuildKit does.
+// Entries with the same image name are replaced, the layout is created if it does not exist yet.
+func AppendIndex(layoutDir, imageName string, idx v1.ImageIndex, annotations map[string]string) error {
+	wanted, err := name.ParseReference(imageName)
+	if err != nil {
+		return errors.Join(errors.New("invalid image name"), err)
+	}
+
+	layoutPath, err := layout.FromPath(layoutDir)
+	if err != nil {
+		layoutPath, err = layout.Write(layoutDir, empty.Index)
+		if err != nil {
+			return errors.Join(errors.New("failed to create OCI layout"), err)
+		}
+	}
+
+	descriptorAnnotations := map[string]string{ImageNameAnnotation: wanted.Name()}
+	for k, v := range annotations {
+		descriptorAnnotations[k] = v
+	}
+
+	sameName := func(desc v1.Descriptor) bool {
+		ref, err := name.ParseReference(desc.Annotations[ImageNameAnnotation])
+		return err == nil && ref.Name() == wanted.Name()
+	}
+	if err := layoutPath.ReplaceIndex(idx, sameName, layout.WithAnnotations(descriptorAnnotations)); err != nil {
+		return errors.Join(errors.New("failed to write image index to OCI layout"), err)
+	}
+	return nil
+}
+
+// WriteIndexTar writes the multi-platform image index as OCI tar, like the BuildKit oci exporter
+func WriteIndexTar(tarPath, imageName string, idx v1.ImageIndex, annotations map[string]string) error {
+	tmpDir, err := os.MkdirTemp("", "oci-layout-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := AppendIndex(tmpDir, imageName, idx, annotations); err != nil {
+		return err
+	}
+	return utils.CreateTar(tmpDir, tarPath)
+}
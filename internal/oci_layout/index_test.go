@@ -0,0 +1,116 @@
+package oci_layout
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// multiPlatformIndex creates an index with a foreign platform listed before the host platform
+func multiPlatformIndex(t *testing.T) (v1.ImageIndex, v1.Image) {
+	t.Helper()
+	foreignArch := "arm64"
+	if runtime.GOARCH == "arm64" {
+		foreignArch = "amd64"
+	}
+
+	var host v1.Image
+	idx := mutate.IndexMediaType(empty.Index, types.OCIImageIndex)
+	for _, arch := range []string{foreignArch, runtime.GOARCH} {
+		img, err := random.Image(64, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		idx = mutate.AppendManifests(idx, mutate.IndexAddendum{
+			Add:        img,
+			Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: arch}},
+		})
+		host = img
+	}
+	return idx, host
+}
+
+func TestWriteIndexTar(t *testing.T) {
+	idx, host := multiPlatformIndex(t)
+	tarPath := filepath.Join(t.TempDir(), "image.tar")
+	if err := WriteIndexTar(tarPath, "python:3.13", idx, map[string]string{"org.opencontainers.image.version": "3.13"}); err != nil {
+		t.Fatalf("WriteIndexTar() error = %v", err)
+	}
+
+	img, imageName, cleanup, err := ImageFromTar(tarPath)
+	if err != nil {
+		t.Fatalf("ImageFromTar() error = %v", err)
+	}
+	defer cleanup()
+	if imageName != "index.docker.io/library/python:3.13" {
+		t.Errorf("unexpected image name %q", imageName)
+	}
+	if mustDigest(t, img) != mustDigest(t, host) {
+		t.Error("expected the image of the host platform")
+	}
+
+	artifact, artifactCleanup, err := ArtifactFromTar(tarPath)
+	if err != nil {
+		t.Fatalf("ArtifactFromTar() error = %v", err)
+	}
+	defer artifactCleanup()
+	artifactIdx, ok := artifact.(v1.ImageIndex)
+	if !ok {
+		t.Fatalf("expected image index, got %T", artifact)
+	}
+	if mustIndexDigest(t, artifactIdx) != mustIndexDigest(t, idx) {
+		t.Error("expected the written index")
+	}
+}
+
+func TestAppendIndex(t *testing.T) {
+	store, images := writeStore(t, "dotnet:8.0")
+
+	first, _ := multiPlatformIndex(t)
+	if err := AppendIndex(store.Path(), "python:3.13", first, nil); err != nil {
+		t.Fatal(err)
+	}
+	idx, host := multiPlatformIndex(t)
+	if err := AppendIndex(store.Path(), "python:3.13", idx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := store.Image("python:3.13")
+	if err != nil {
+		t.Fatalf("Image() error = %v", err)
+	}
+	if mustDigest(t, img) != mustDigest(t, host) {
+		t.Error("expected the host platform image of the replaced index")
+	}
+
+	artifact, err := store.Artifact("python:3.13")
+	if err != nil {
+		t.Fatalf("Artifact() error = %v", err)
+	}
+	if artifactIdx, ok := artifact.(v1.ImageIndex); !ok || mustIndexDigest(t, artifactIdx) != mustIndexDigest(t, idx) {
+		t.Errorf("expected the replaced index, got %T", artifact)
+	}
+
+	dotnet, err := store.Artifact("dotnet:8.0")
+	if err != nil {
+		t.Fatalf("Artifact() error = %v", err)
+	}
+	if dotnetImg, ok := dotnet.(v1.Image); !ok || mustDigest(t, dotnetImg) != mustDigest(t, images["dotnet:8.0"]) {
+		t.Errorf("expected single platform image to stay untouched, got %T", dotnet)
+	}
+}
+
+func mustIndexDigest(t *testing.T, idx v1.ImageIndex) v1.Hash {
+	t.Helper()
+	digest, err := idx.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return digest
+}
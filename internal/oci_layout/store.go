@@ -0,0 +1,226 @@
+package oci_layout
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// ImageNameAnnotation is set by BuildKit on the index entries of exported images
+const ImageNameAnnotation = "io.containerd.image.name"
+
+// Store is an OCI layout directory shared by all images of a project, deduplicating blobs across images.
+// BuildKit exports to it with the oci exporter and tar=false, images are looked up by their name annotation.
+type Store struct {
+	path string
+}
+
+// NewStore creates the layout directory if it does not exist yet
+func NewStore(path string) (*Store, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(absPath, 0755); err != nil {
+		return nil, errors.Join(errors.New("failed to create OCI layout store"), err)
+	}
+	return &Store{path: absPath}, nil
+}
+
+// Path of the layout directory
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Descriptor returns the index entry of the image with the given name
+func (s *Store) Descriptor(imageName string) (*v1.Descriptor, error) {
+	wanted, err := name.ParseReference(imageName)
+	if err != nil {
+		return nil, errors.Join(errors.New("invalid image name"), err)
+	}
+
+	layoutPath, err := layout.FromPath(s.path)
+	if err != nil {
+		return nil, errors.Join(errors.New("failed to read OCI layout"), err)
+	}
+
+	idx, err := layoutPath.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	idxManifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, desc := range idxManifest.Manifests {
+		ref, err := name.ParseReference(desc.Annotations[ImageNameAnnotation])
+		if err != nil {
+			continue
+		}
+		// compare normalized names, so python:3.13 matches docker.io/library/python:3.13
+		if ref.Name() == wanted.Name() {
+			return &desc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("image %s not found in OCI layout store", imageName)
+}
+
+// Image returns the image with the given name
+func (s *Store) Image(imageName string) (v1.Image, error) {
+	desc, err := s.Descriptor(imageName)
+	if err != nil {
+		return nil, err
+	}
+	layoutPath, err := layout.FromPath(s.path)
+	if err != nil {
+		return nil, errors.Join(errors.New("failed to read OCI layout"), err)
+	}
+	return imageFromDescriptor(layoutPath, *desc)
+}
+
+// Artifact returns the image with the given name, as index if it was built for multiple platforms
+func (s *Store) Artifact(imageName string) (remote.Taggable, error) {
+	desc, err := s.Descriptor(imageName)
+	if err != nil {
+		return nil, err
+	}
+	layoutPath, err := layout.FromPath(s.path)
+	if err != nil {
+		return nil, errors.Join(errors.New("failed to read OCI layout"), err)
+	}
+	return artifactFromDescriptor(layoutPath, *desc)
+}
+
+// View creates a layout directory only containing the image with the given name, for tools expecting a single image.
+// Multi-platform images are resolved to the host platform.
+// The blobs are linked to the store instead of being copied, cleanup removes the view.
+func (s *Store) View(imageName string) (string, func(), error) {
+	desc, err := s.Descriptor(imageName)
+	if err != nil {
+		return "", nil, err
+	}
+	layoutPath, err := layout.FromPath(s.path)
+	if err != nil {
+		return "", nil, errors.Join(errors.New("failed to read OCI layout"), err)
+	}
+	resolved := *desc
+	if desc.MediaType.IsIndex() {
+		if _, resolved, err = resolveImageDescriptor(layoutPath, *desc); err != nil {
+			return "", nil, err
+		}
+	}
+
+	viewDir, err := os.MkdirTemp("", "oci-view-*")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(viewDir) }
+
+	index, err := json.Marshal(v1.IndexManifest{
+		SchemaVersion: 2,
+		MediaType:     "application/vnd.oci.image.index.v1+json",
+		Manifests:     []v1.Descriptor{resolved},
+	})
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	files := map[string][]byte{
+		"oci-layout": []byte(`{"imageLayoutVersion":"1.0.0"}`),
+		"index.json": index,
+	}
+	for file, content := range files {
+		if err := os.WriteFile(filepath.Join(viewDir, file), content, 0644); err != nil {
+			cleanup()
+			return "", nil, err
+		}
+	}
+
+	if err := os.Symlink(filepath.Join(s.path, "blobs"), filepath.Join(viewDir, "blobs")); err != nil {
+		cleanup()
+		return "", nil, errors.Join(errors.New("failed to link blobs of OCI layout store"), err)
+	}
+
+	return viewDir, cleanup, nil
+}
+
+// imageFromDescriptor reads the image, resolving indexes to the image manifest of the host platform
+func imageFromDescriptor(layoutPath layout.Path, desc v1.Descriptor) (v1.Image, error) {
+	if !desc.MediaType.IsIndex() {
+		img, err := layoutPath.Image(desc.Digest)
+		if err != nil {
+			return nil, errors.Join(errors.New("failed to read image from layout"), err)
+		}
+		return img, nil
+	}
+
+	child, resolved, err := resolveImageDescriptor(layoutPath, desc)
+	if err != nil {
+		return nil, err
+	}
+	return child.Image(resolved.Digest)
+}
+
+// resolveImageDescriptor resolves an index to the image manifest of the host platform, falling back to the first image manifest
+func resolveImageDescriptor(layoutPath layout.Path, desc v1.Descriptor) (v1.ImageIndex, v1.Descriptor, error) {
+	idx, err := layoutPath.ImageIndex()
+	if err != nil {
+		return nil, v1.Descriptor{}, err
+	}
+	child, err := idx.ImageIndex(desc.Digest)
+	if err != nil {
+		return nil, v1.Descriptor{}, errors.Join(errors.New("failed to read image index from layout"), err)
+	}
+	childManifest, err := child.IndexManifest()
+	if err != nil {
+		return nil, v1.Descriptor{}, err
+	}
+
+	var images []v1.Descriptor
+	for _, manifest := range childManifest.Manifests {
+		// attestation manifests are stored alongside the images
+		if manifest.MediaType.IsImage() && manifest.Annotations["vnd.docker.reference.type"] == "" {
+			images = append(images, manifest)
+		}
+	}
+	if len(images) == 0 {
+		return nil, v1.Descriptor{}, errors.New("no image manifest in image index")
+	}
+
+	host := v1.Platform{OS: "linux", Architecture: runtime.GOARCH}
+	for _, image := range images {
+		if image.Platform != nil && image.Platform.Satisfies(host) {
+			return child, image, nil
+		}
+	}
+	return child, images[0], nil
+}
+
+// artifactFromDescriptor reads the image or, for multi-platform images, the complete image index
+func artifactFromDescriptor(layoutPath layout.Path, desc v1.Descriptor) (remote.Taggable, error) {
+	if !desc.MediaType.IsIndex() {
+		return imageFromDescriptor(layoutPath, desc)
+	}
+
+	idx, err := layoutPath.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	child, err := idx.ImageIndex(desc.Digest)
+	if err != nil {
+		return nil, errors.Join(errors.New("failed to read image index from layout"), err)
+	}
+	return child, nil
+}
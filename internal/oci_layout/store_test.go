@@ -0,0 +1,128 @@
+package oci_layout
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+// writeStore writes a store as BuildKit would, with one image per name sharing the blobs directory
+func writeStore(t *testing.T, names ...string) (*Store, map[string]v1.Image) {
+	t.Helper()
+	store, err := NewStore(filepath.Join(t.TempDir(), "oci"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layoutPath, err := layout.Write(store.Path(), empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	images := make(map[string]v1.Image)
+	for _, imageName := range names {
+		img, err := random.Image(64, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := layoutPath.AppendImage(img, layout.WithAnnotations(map[string]string{ImageNameAnnotation: imageName})); err != nil {
+			t.Fatal(err)
+		}
+		images[imageName] = img
+	}
+	return store, images
+}
+
+func mustDigest(t *testing.T, img v1.Image) v1.Hash {
+	t.Helper()
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return digest
+}
+
+func TestStore_Image(t *testing.T) {
+	store, images := writeStore(t, "python:3.13", "docker.io/library/dotnet:8.0")
+
+	tests := []struct {
+		name          string
+		imageName     string
+		expected      v1.Image
+		errorContains string
+	}{
+		{name: "exact name", imageName: "python:3.13", expected: images["python:3.13"]},
+		{name: "normalized name", imageName: "dotnet:8.0", expected: images["docker.io/library/dotnet:8.0"]},
+		{name: "missing tag", imageName: "python:3.12", errorContains: "not found in OCI layout store"},
+		{name: "invalid name", imageName: "INVALID:!!!", errorContains: "invalid image name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := store.Image(tt.imageName)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got, want := mustDigest(t, img), mustDigest(t, tt.expected); got != want {
+				t.Errorf("expected digest %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestStore_View(t *testing.T) {
+	store, images := writeStore(t, "python:3.13", "dotnet:8.0")
+
+	viewDir, cleanup, err := store.View("dotnet:8.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	view, err := layout.ImageIndexFromPath(viewDir)
+	if err != nil {
+		t.Fatalf("failed to read view: %v", err)
+	}
+	manifest, err := view.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Manifests) != 1 {
+		t.Fatalf("expected view to contain a single image, got %d", len(manifest.Manifests))
+	}
+
+	img, err := view.Image(manifest.Manifests[0].Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// reading the layers requires the linked blobs
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := layers[0].Compressed(); err != nil {
+		t.Errorf("expected layer to be readable from view: %v", err)
+	}
+	if got, want := mustDigest(t, img), mustDigest(t, images["dotnet:8.0"]); got != want {
+		t.Errorf("expected digest %s, got %s", want, got)
+	}
+
+	cleanup()
+	if _, err := os.Stat(viewDir); !os.IsNotExist(err) {
+		t.Error("expected view to be removed by cleanup")
+	}
+	if _, err := os.Stat(filepath.Join(store.Path(), "blobs")); err != nil {
+		t.Error("expected cleanup to keep the blobs of the store")
+	}
+}
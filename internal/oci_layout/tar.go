@@ -0,0 +1,90 @@
+package oci_layout
+
+import (
+	"errors"
+	"os"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/timo-reymann/ContainerHive/internal/utils"
+)
+
+// ImageFromTar extracts an OCI tar and reads its first image and name annotation.
+// The image is read lazily from the extracted files, so it is only valid until cleanup is called.
+func ImageFromTar(tarPath string) (v1.Image, string, func(), error) {
+	tmpDir, err := os.MkdirTemp("", "oci-layout-*")
+	if err != nil {
+		return nil, "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	img, imageName, err := readFirstImage(tarPath, tmpDir)
+	if err != nil {
+		cleanup()
+		return nil, "", nil, err
+	}
+	return img, imageName, cleanup, nil
+}
+
+// ArtifactFromTar extracts an OCI tar and reads its first image, as index if it was built for multiple platforms.
+// The artifact is read lazily from the extracted files, so it is only valid until cleanup is called.
+func ArtifactFromTar(tarPath string) (remote.Taggable, func(), error) {
+	tmpDir, err := os.MkdirTemp("", "oci-layout-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	layoutPath, desc, err := readFirstDescriptor(tarPath, tmpDir)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	artifact, err := artifactFromDescriptor(layoutPath, desc)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return artifact, cleanup, nil
+}
+
+func readFirstImage(tarPath, dir string) (v1.Image, string, error) {
+	layoutPath, desc, err := readFirstDescriptor(tarPath, dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	img, err := imageFromDescriptor(layoutPath, desc)
+	if err != nil {
+		return nil, "", err
+	}
+	return img, desc.Annotations[ImageNameAnnotation], nil
+}
+
+// readFirstDescriptor extracts the OCI tar to dir and returns the first entry of its index
+func readFirstDescriptor(tarPath, dir string) (layout.Path, v1.Descriptor, error) {
+	if err := utils.ExtractTar(tarPath, dir); err != nil {
+		return "", v1.Descriptor{}, errors.Join(errors.New("failed to extract OCI tar"), err)
+	}
+
+	layoutPath, err := layout.FromPath(dir)
+	if err != nil {
+		return "", v1.Descriptor{}, errors.Join(errors.New("failed to read OCI layout"), err)
+	}
+
+	idx, err := layoutPath.ImageIndex()
+	if err != nil {
+		return "", v1.Descriptor{}, err
+	}
+
+	idxManifest, err := idx.IndexManifest()
+	if err != nil {
+		return "", v1.Descriptor{}, err
+	}
+
+	if len(idxManifest.Manifests) == 0 {
+		return "", v1.Descriptor{}, errors.New("no manifests in OCI layout")
+	}
+	return layoutPath, idxManifest.Manifests[0], nil
+}
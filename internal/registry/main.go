@@ -3,6 +3,8 @@ package registry
 import (
 	"context"
 	"os"
+
+	"github.com/timo-reymann/ContainerHive/internal/oci_layout"
 )
 
 // Registry manages an OCI registry for staging local base images.
@@ -11,6 +13,8 @@ type Registry interface {
 	Stop(ctx context.Context) error
 	Address() string
 	Push(ctx context.Context, imageName, tag, ociTarPath string) error
+	// PushFromStore pushes the image named imageName:tag from the OCI layout store
+	PushFromStore(ctx context.Context, store *oci_layout.Store, imageName, tag string) error
 	IsLocal() bool
 }
 
@@ -3,12 +3,10 @@ package registry
 import (
 	"context"
 	"errors"
-	"os"
 
 	"github.com/google/go-containerregistry/pkg/name"
-	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
-	"github.com/timo-reymann/ContainerHive/internal/utils"
+	"github.com/timo-reymann/ContainerHive/internal/oci_layout"
 )
 
 // RemoteRegistry is a passthrough registry for CI environments.
@@ -39,46 +37,31 @@ func (r *RemoteRegistry) IsLocal() bool {
 }
 
 func (r *RemoteRegistry) Push(_ context.Context, imageName, tag, ociTarPath string) error {
-	tmpDir, err := os.MkdirTemp("", "oci-push-*")
+	artifact, cleanup, err := oci_layout.ArtifactFromTar(ociTarPath)
 	if err != nil {
-		return err
-	}
-	defer os.RemoveAll(tmpDir)
-
-	if err := utils.ExtractTar(ociTarPath, tmpDir); err != nil {
-		return errors.Join(errors.New("failed to extract OCI tar for push"), err)
-	}
-
-	layoutPath, err := layout.FromPath(tmpDir)
-	if err != nil {
-		return errors.Join(errors.New("failed to read OCI layout"), err)
+		return errors.Join(errors.New("failed to read OCI tar for push"), err)
 	}
+	defer cleanup()
 
-	idx, err := layoutPath.ImageIndex()
-	if err != nil {
-		return err
-	}
+	return r.push(imageName, tag, artifact)
+}
 
-	idxManifest, err := idx.IndexManifest()
+func (r *RemoteRegistry) PushFromStore(_ context.Context, store *oci_layout.Store, imageName, tag string) error {
+	artifact, err := store.Artifact(imageName + ":" + tag)
 	if err != nil {
 		return err
 	}
 
-	if len(idxManifest.Manifests) == 0 {
-		return errors.New("no manifests in OCI layout")
-	}
-
-	img, err := layoutPath.Image(idxManifest.Manifests[0].Digest)
-	if err != nil {
-		return errors.Join(errors.New("failed to read image from layout"), err)
-	}
+	return r.push(imageName, tag, artifact)
+}
 
+func (r *RemoteRegistry) push(imageName, tag string, artifact remote.Taggable) error {
 	ref, err := name.NewTag(r.address + "/" + imageName + ":" + tag)
 	if err != nil {
 		return errors.Join(errors.New("invalid image reference"), err)
 	}
 
-	if err := remote.Write(ref, img); err != nil {
+	if err := remote.Push(ref, artifact); err != nil {
 		return errors.Join(errors.New("failed to push image to remote registry"), err)
 	}
 
@@ -9,9 +9,8 @@ import (
 	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
-	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
-	"github.com/timo-reymann/ContainerHive/internal/utils"
+	"github.com/timo-reymann/ContainerHive/internal/oci_layout"
 	"zotregistry.dev/zot/v2/pkg/api"
 	"zotregistry.dev/zot/v2/pkg/api/config"
 )
@@ -115,46 +114,31 @@ func (z *ZotRegistry) IsLocal() bool {
 }
 
 func (z *ZotRegistry) Push(_ context.Context, imageName, tag, ociTarPath string) error {
-	tmpDir, err := os.MkdirTemp("", "oci-push-*")
+	artifact, cleanup, err := oci_layout.ArtifactFromTar(ociTarPath)
 	if err != nil {
-		return err
-	}
-	defer os.RemoveAll(tmpDir)
-
-	if err := utils.ExtractTar(ociTarPath, tmpDir); err != nil {
-		return errors.Join(errors.New("failed to extract OCI tar for push"), err)
-	}
-
-	layoutPath, err := layout.FromPath(tmpDir)
-	if err != nil {
-		return errors.Join(errors.New("failed to read OCI layout"), err)
+		return errors.Join(errors.New("failed to read OCI tar for push"), err)
 	}
+	defer cleanup()
 
-	idx, err := layoutPath.ImageIndex()
-	if err != nil {
-		return err
-	}
+	return z.push(imageName, tag, artifact)
+}
 
-	idxManifest, err := idx.IndexManifest()
+func (z *ZotRegistry) PushFromStore(_ context.Context, store *oci_layout.Store, imageName, tag string) error {
+	artifact, err := store.Artifact(imageName + ":" + tag)
 	if err != nil {
 		return err
 	}
 
-	if len(idxManifest.Manifests) == 0 {
-		return errors.New("no manifests in OCI layout")
-	}
-
-	img, err := layoutPath.Image(idxManifest.Manifests[0].Digest)
-	if err != nil {
-		return errors.Join(errors.New("failed to read image from layout"), err)
-	}
+	return z.push(imageName, tag, artifact)
+}
 
+func (z *ZotRegistry) push(imageName, tag string, artifact remote.Taggable) error {
 	ref, err := name.NewTag(fmt.Sprintf("%s/%s:%s", z.Address(), imageName, tag), name.Insecure)
 	if err != nil {
 		return errors.Join(errors.New("invalid image reference"), err)
 	}
 
-	if err := remote.Write(ref, img); err != nil {
+	if err := remote.Push(ref, artifact); err != nil {
 		return errors.Join(errors.New("failed to push image to zot"), err)
 	}
 
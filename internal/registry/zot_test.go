@@ -10,6 +10,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/timo-reymann/ContainerHive/internal/oci_layout"
 )
 
 func buildOCITar(t *testing.T) string {
@@ -129,6 +134,44 @@ func TestZotRegistry(t *testing.T) {
 		}
 	})
 
+	t.Run("push image from layout store", func(t *testing.T) {
+		reg := NewZotRegistry()
+		if err := reg.Start(t.Context()); err != nil {
+			t.Fatalf("failed to start zot: %v", err)
+		}
+		t.Cleanup(func() { reg.Stop(t.Context()) })
+
+		store, err := oci_layout.NewStore(filepath.Join(t.TempDir(), "oci"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		layoutPath, err := layout.Write(store.Path(), empty.Index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := random.Image(64, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := layoutPath.AppendImage(img, layout.WithAnnotations(map[string]string{oci_layout.ImageNameAnnotation: "python:3.13"})); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := reg.PushFromStore(t.Context(), store, "python", "3.13"); err != nil {
+			t.Fatalf("push failed: %v", err)
+		}
+
+		resp, err := http.Get(fmt.Sprintf("http://%s/v2/python/tags/list", reg.Address()))
+		if err != nil {
+			t.Fatalf("tags request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("expected pushed image in registry, got status %d", resp.StatusCode)
+		}
+	})
+
 	t.Run("is local", func(t *testing.T) {
 		reg := NewZotRegistry()
 		if !reg.IsLocal() {
@@ -0,0 +1,87 @@
+package reproducible
+
+import (
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// LayerDifference is a layer that differs between two builds, a missing layer has an empty digest
+type LayerDifference struct {
+	Index   int
+	DigestA string
+	DigestB string
+}
+
+type Comparison struct {
+	DigestA       string
+	DigestB       string
+	ConfigDigestA string
+	ConfigDigestB string
+	Layers        []LayerDifference
+}
+
+// Reproducible reports if both builds produced the same manifest
+func (c *Comparison) Reproducible() bool {
+	return c.DigestA == c.DigestB
+}
+
+// Compare compares the manifests of two builds of the same image layer by layer
+func Compare(a, b v1.Image) (*Comparison, error) {
+	comparison := &Comparison{}
+
+	for _, image := range []struct {
+		img    v1.Image
+		digest *string
+		config *string
+	}{
+		{a, &comparison.DigestA, &comparison.ConfigDigestA},
+		{b, &comparison.DigestB, &comparison.ConfigDigestB},
+	} {
+		digest, err := image.img.Digest()
+		if err != nil {
+			return nil, err
+		}
+		*image.digest = digest.String()
+
+		config, err := image.img.ConfigName()
+		if err != nil {
+			return nil, err
+		}
+		*image.config = config.String()
+	}
+
+	layersA, err := layerDigests(a)
+	if err != nil {
+		return nil, err
+	}
+	layersB, err := layerDigests(b)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < max(len(layersA), len(layersB)); i++ {
+		var digestA, digestB string
+		if i < len(layersA) {
+			digestA = layersA[i]
+		}
+		if i < len(layersB) {
+			digestB = layersB[i]
+		}
+		if digestA != digestB {
+			comparison.Layers = append(comparison.Layers, LayerDifference{Index: i, DigestA: digestA, DigestB: digestB})
+		}
+	}
+
+	return comparison, nil
+}
+
+func layerDigests(img v1.Image) ([]string, error) {
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	digests := make([]string, len(manifest.Layers))
+	for i, layer := range manifest.Layers {
+		digests[i] = layer.Digest.String()
+	}
+	return digests, nil
+}
@@ -0,0 +1,87 @@
+package reproducible
+
+import (
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func mustLayers(t *testing.T, count int) []v1.Layer {
+	t.Helper()
+	layers := make([]v1.Layer, count)
+	for i := range layers {
+		layer, err := random.Layer(64, "application/vnd.oci.image.layer.v1.tar+gzip")
+		if err != nil {
+			t.Fatal(err)
+		}
+		layers[i] = layer
+	}
+	return layers
+}
+
+func mustImage(t *testing.T, layers ...v1.Layer) v1.Image {
+	t.Helper()
+	img, err := random.Image(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err = mutate.AppendLayers(img, layers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func mustLayerDigest(t *testing.T, layer v1.Layer) string {
+	t.Helper()
+	digest, err := layer.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return digest.String()
+}
+
+func TestCompare(t *testing.T) {
+	layers := mustLayers(t, 4)
+	base := mustImage(t, layers[0], layers[1])
+
+	t.Run("identical builds", func(t *testing.T) {
+		comparison, err := Compare(base, base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !comparison.Reproducible() {
+			t.Error("expected identical images to be reproducible")
+		}
+		if len(comparison.Layers) != 0 {
+			t.Errorf("expected no differing layers, got %v", comparison.Layers)
+		}
+	})
+
+	t.Run("differing layer", func(t *testing.T) {
+		comparison, err := Compare(base, mustImage(t, layers[0], layers[2]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if comparison.Reproducible() {
+			t.Error("expected differing images not to be reproducible")
+		}
+		expected := []LayerDifference{{Index: 1, DigestA: mustLayerDigest(t, layers[1]), DigestB: mustLayerDigest(t, layers[2])}}
+		if len(comparison.Layers) != 1 || comparison.Layers[0] != expected[0] {
+			t.Errorf("expected %v, got %v", expected, comparison.Layers)
+		}
+	})
+
+	t.Run("additional layer", func(t *testing.T) {
+		comparison, err := Compare(base, mustImage(t, layers[0], layers[1], layers[3]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := LayerDifference{Index: 2, DigestB: mustLayerDigest(t, layers[3])}
+		if len(comparison.Layers) != 1 || comparison.Layers[0] != expected {
+			t.Errorf("expected %v, got %v", expected, comparison.Layers)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package reproducible
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// DefaultSourceDateEpoch is used when the epoch is neither configured nor derivable from git.
+// It is 2026-02-06T00:00:00Z, the fixed SOURCE_DATE_EPOCH all builds used before it was derived per image,
+// so images built without git history stay identical to builds of earlier versions.
+const DefaultSourceDateEpoch int64 = 1770336000
+
+const (
+	SourceConfig      = "config"
+	SourceEnvironment = "environment"
+	SourceGit         = "git"
+	SourceDefault     = "default"
+)
+
+// GitCommitTime returns the commit time of the last commit touching the directory
+func GitCommitTime(ctx context.Context, dir string) (int64, error) {
+	cmd := exec.CommandContext(ctx, "git", "log", "-1", "--format=%ct", "--", ".")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, errors.Join(fmt.Errorf("failed to read last commit of %s", dir), err)
+	}
+
+	value := strings.TrimSpace(string(out))
+	if value == "" {
+		return 0, fmt.Errorf("no commit touches %s", dir)
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// SourceDateEpoch resolves the SOURCE_DATE_EPOCH for an image directory and reports where it was taken from.
+// The first configured non-zero value wins, followed by the SOURCE_DATE_EPOCH environment variable
+// and the last git commit touching the directory. The configuration is preferred over the environment
+// so a SOURCE_DATE_EPOCH exported by CI for other tools does not change images with a pinned epoch. Without any of them DefaultSourceDateEpoch is returned with
+// SourceDefault, callers should warn about it as the date is unrelated to the image.
+func SourceDateEpoch(ctx context.Context, dir string, configured ...int64) (int64, string, error) {
+	for _, epoch := range configured {
+		if epoch != 0 {
+			return epoch, SourceConfig, nil
+		}
+	}
+
+	if env := os.Getenv("SOURCE_DATE_EPOCH"); env != "" {
+		epoch, err := strconv.ParseInt(env, 10, 64)
+		if err != nil {
+			return 0, "", fmt.Errorf("invalid SOURCE_DATE_EPOCH '%s'", env)
+		}
+		return epoch, SourceEnvironment, nil
+	}
+
+	if epoch, err := GitCommitTime(ctx, dir); err == nil {
+		return epoch, SourceGit, nil
+	}
+
+	return DefaultSourceDateEpoch, SourceDefault, nil
+}
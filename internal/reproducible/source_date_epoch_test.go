@@ -0,0 +1,135 @@
+package reproducible
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func runGit(t *testing.T, dir, date string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+// setupRepo creates a repository where python was last changed after dotnet
+func setupRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := t.TempDir()
+	runGit(t, repo, "@1700000000 +0000", "init", "-q")
+	for _, image := range []string{"python", "dotnet", "unversioned"} {
+		if err := os.MkdirAll(filepath.Join(repo, "images", image), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commit := func(image, date string) {
+		if err := os.WriteFile(filepath.Join(repo, "images", image, "Dockerfile"), []byte("FROM scratch\n# "+date+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, repo, date, "add", ".")
+		runGit(t, repo, date, "commit", "-q", "-m", "update "+image)
+	}
+	commit("dotnet", "@1700000000 +0000")
+	commit("python", "@1700001000 +0000")
+	commit("dotnet", "@1700002000 +0000")
+	// written after the last commit, so git has no history for it
+	if err := os.WriteFile(filepath.Join(repo, "images", "unversioned", "Dockerfile"), []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return repo
+}
+
+func TestSourceDateEpoch(t *testing.T) {
+	repo := setupRepo(t)
+
+	tests := []struct {
+		name           string
+		dir            string
+		configured     []int64
+		env            string
+		expectedEpoch  int64
+		expectedSource string
+		expectError    bool
+	}{
+		{
+			name:           "last commit touching the image",
+			dir:            filepath.Join(repo, "images", "python"),
+			expectedEpoch:  1700001000,
+			expectedSource: SourceGit,
+		},
+		{
+			name:           "other image changed later",
+			dir:            filepath.Join(repo, "images", "dotnet"),
+			expectedEpoch:  1700002000,
+			expectedSource: SourceGit,
+		},
+		{
+			name:           "image config takes precedence",
+			dir:            filepath.Join(repo, "images", "python"),
+			configured:     []int64{1600000000, 1500000000},
+			expectedEpoch:  1600000000,
+			expectedSource: SourceConfig,
+		},
+		{
+			name:           "unset image config falls back to project config",
+			dir:            filepath.Join(repo, "images", "python"),
+			configured:     []int64{0, 1500000000},
+			expectedEpoch:  1500000000,
+			expectedSource: SourceConfig,
+		},
+		{
+			name:           "environment takes precedence over git",
+			dir:            filepath.Join(repo, "images", "python"),
+			env:            "1650000000",
+			expectedEpoch:  1650000000,
+			expectedSource: SourceEnvironment,
+		},
+		{
+			name:        "invalid environment",
+			dir:         filepath.Join(repo, "images", "python"),
+			env:         "yesterday",
+			expectError: true,
+		},
+		{
+			name:           "uncommitted image",
+			dir:            filepath.Join(repo, "images", "unversioned"),
+			expectedEpoch:  DefaultSourceDateEpoch,
+			expectedSource: SourceDefault,
+		},
+		{
+			name:           "outside of a repository",
+			dir:            t.TempDir(),
+			expectedEpoch:  DefaultSourceDateEpoch,
+			expectedSource: SourceDefault,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SOURCE_DATE_EPOCH", tt.env)
+
+			epoch, source, err := SourceDateEpoch(t.Context(), tt.dir, tt.configured...)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("SourceDateEpoch() error = %v, expectError %v", err, tt.expectError)
+			}
+			if epoch != tt.expectedEpoch || source != tt.expectedSource {
+				t.Errorf("SourceDateEpoch() = (%d, %q), want (%d, %q)", epoch, source, tt.expectedEpoch, tt.expectedSource)
+			}
+		})
+	}
+}
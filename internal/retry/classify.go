@@ -0,0 +1,81 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// transientMessages are matched against errors that lost their type, e.g. when BuildKit reports a failed
+// image pull or cache export over gRPC
+var transientMessages = []string{
+	"connection reset by peer",
+	"connection refused",
+	"broken pipe",
+	"i/o timeout",
+	"tls handshake timeout",
+	"unexpected eof",
+	"temporary failure in name resolution",
+	"transport is closing",
+	"error reading from server: eof",
+	"429 too many requests",
+	"500 internal server error",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+}
+
+// IsTransient classifies errors caused by network issues, overloaded or failing registries and a lost BuildKit
+// connection, which are likely to succeed when retried. Build errors like a failing RUN instruction are not transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ETIMEDOUT) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && (dnsErr.IsTemporary || dnsErr.IsTimeout) {
+		return true
+	}
+
+	var registryErr *transport.Error
+	if errors.As(err, &registryErr) {
+		return registryErr.Temporary()
+	}
+
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		switch grpcErr.GRPCStatus().Code() {
+		case codes.Unavailable, codes.Aborted:
+			return true
+		}
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, transientMsg := range transientMessages {
+		if strings.Contains(msg, transientMsg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"syscall"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "cancelled",
+			err:  fmt.Errorf("build failed: %w", context.Canceled),
+		},
+		{
+			name:     "connection reset",
+			err:      fmt.Errorf("push failed: %w", syscall.ECONNRESET),
+			expected: true,
+		},
+		{
+			name:     "joined timeout",
+			err:      errors.Join(errors.New("failed to push"), context.DeadlineExceeded),
+			expected: true,
+		},
+		{
+			name:     "registry 503",
+			err:      &transport.Error{StatusCode: http.StatusServiceUnavailable},
+			expected: true,
+		},
+		{
+			name: "registry unauthorized",
+			err:  &transport.Error{StatusCode: http.StatusUnauthorized},
+		},
+		{
+			name:     "buildkit unavailable",
+			err:      errors.Join(errors.New("build failed"), status.Error(codes.Unavailable, "connection lost")),
+			expected: true,
+		},
+		{
+			name: "buildkit invalid argument",
+			err:  status.Error(codes.InvalidArgument, "failed to parse Dockerfile"),
+		},
+		{
+			name:     "base image pull with gateway error",
+			err:      errors.New(`failed to resolve source metadata for docker.io/library/alpine:3: unexpected status from HEAD request: 502 Bad Gateway`),
+			expected: true,
+		},
+		{
+			name: "failing RUN instruction",
+			err:  errors.New(`process "/bin/sh -c make" did not complete successfully: exit code: 2`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.expected {
+				t.Errorf("IsTransient() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// Policy controls how often and how fast an operation failing with transient errors is retried
+type Policy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultPolicy is used for all settings not configured in hive.yml
+var DefaultPolicy = Policy{
+	MaxAttempts:    3,
+	InitialBackoff: 2 * time.Second,
+	MaxBackoff:     30 * time.Second,
+}
+
+// Attempt is the outcome of a single try, recorded in the run report
+type Attempt struct {
+	Attempt  int    `json:"attempt"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+	// Transient is set for failures that were classified as worth retrying
+	Transient bool `json:"transient,omitempty"`
+}
+
+// NewPolicy creates the policy from the hive.yml config, falling back to DefaultPolicy for unset values
+func NewPolicy(config *model.RetryPolicyConfig) (Policy, error) {
+	policy := DefaultPolicy
+	if config == nil {
+		return policy, nil
+	}
+
+	if config.MaxAttempts < 0 {
+		return policy, fmt.Errorf("max attempts must be at least 1, got %d", config.MaxAttempts)
+	}
+	if config.MaxAttempts > 0 {
+		policy.MaxAttempts = config.MaxAttempts
+	}
+
+	backoffs := map[*time.Duration]string{
+		&policy.InitialBackoff: config.InitialBackoff,
+		&policy.MaxBackoff:     config.MaxBackoff,
+	}
+	for target, val := range backoffs {
+		if val == "" {
+			continue
+		}
+		backoff, err := time.ParseDuration(val)
+		if err != nil || backoff < 0 {
+			return policy, fmt.Errorf("invalid backoff %q", val)
+		}
+		*target = backoff
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		return policy, fmt.Errorf("max backoff %s must not be lower than the initial backoff %s", policy.MaxBackoff, policy.InitialBackoff)
+	}
+
+	return policy, nil
+}
+
+// backoff returns the wait time before the given retry, doubling the initial backoff for every retry
+func (p Policy) backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < retry && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, p.MaxBackoff)
+}
+
+// Do runs fn until it succeeds, fails with an error that is not transient or the attempts are exhausted.
+// All attempts are returned, also when the operation failed in the end.
+func (p Policy) Do(ctx context.Context, operation string, fn func(ctx context.Context) error) ([]Attempt, error) {
+	var attempts []Attempt
+	for i := 1; ; i++ {
+		start := time.Now()
+		err := fn(ctx)
+		attempt := Attempt{Attempt: i, Duration: time.Since(start).Round(time.Millisecond).String()}
+		if err == nil {
+			return append(attempts, attempt), nil
+		}
+
+		attempt.Error = err.Error()
+		// a cancelled run is never retried, even if the operation failed with a transient error first
+		attempt.Transient = ctx.Err() == nil && IsTransient(err)
+		attempts = append(attempts, attempt)
+		if !attempt.Transient || i >= p.MaxAttempts {
+			return attempts, err
+		}
+
+		backoff := p.backoff(i)
+		log.Printf("%s failed with transient error (attempt %d/%d), retrying in %s: %v", operation, i, p.MaxAttempts, backoff, err)
+		select {
+		case <-ctx.Done():
+			return attempts, errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+	}
+}
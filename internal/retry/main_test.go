@@ -0,0 +1,153 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        *model.RetryPolicyConfig
+		expected      Policy
+		errorContains string
+	}{
+		{
+			name:     "not configured",
+			expected: DefaultPolicy,
+		},
+		{
+			name:     "partially configured",
+			config:   &model.RetryPolicyConfig{MaxAttempts: 5, MaxBackoff: "1m"},
+			expected: Policy{MaxAttempts: 5, InitialBackoff: 2 * time.Second, MaxBackoff: time.Minute},
+		},
+		{
+			name:          "negative attempts",
+			config:        &model.RetryPolicyConfig{MaxAttempts: -1},
+			errorContains: "max attempts must be at least 1",
+		},
+		{
+			name:          "invalid backoff",
+			config:        &model.RetryPolicyConfig{InitialBackoff: "soon"},
+			errorContains: `invalid backoff "soon"`,
+		},
+		{
+			name:          "max lower than initial",
+			config:        &model.RetryPolicyConfig{InitialBackoff: "1m"},
+			errorContains: "must not be lower than the initial backoff",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewPolicy(tt.config)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, policy); diff != "" {
+				t.Errorf("NewPolicy() mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPolicy_backoff(t *testing.T) {
+	policy := Policy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for retry, expected := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := policy.backoff(retry); got != expected {
+			t.Errorf("backoff(%d) = %s, expected %s", retry, got, expected)
+		}
+	}
+}
+
+func TestPolicy_Do(t *testing.T) {
+	policy := Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	transientErr := syscall.ECONNRESET
+	permanentErr := errors.New("exit code: 1")
+
+	tests := []struct {
+		name             string
+		errs             []error
+		expectedAttempts int
+		expectedErr      error
+	}{
+		{
+			name:             "succeeds first",
+			expectedAttempts: 1,
+		},
+		{
+			name:             "succeeds after transient errors",
+			errs:             []error{transientErr, transientErr},
+			expectedAttempts: 3,
+		},
+		{
+			name:             "attempts exhausted",
+			errs:             []error{transientErr, transientErr, transientErr},
+			expectedAttempts: 3,
+			expectedErr:      transientErr,
+		},
+		{
+			name:             "permanent error is not retried",
+			errs:             []error{transientErr, permanentErr},
+			expectedAttempts: 2,
+			expectedErr:      permanentErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			attempts, err := policy.Do(t.Context(), "test", func(ctx context.Context) error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.expectedErr) || (tt.expectedErr == nil && err != nil) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if len(attempts) != tt.expectedAttempts || calls != tt.expectedAttempts {
+				t.Fatalf("expected %d attempts, got %d attempts and %d calls", tt.expectedAttempts, len(attempts), calls)
+			}
+			last := attempts[len(attempts)-1]
+			if (last.Error != "") != (tt.expectedErr != nil) {
+				t.Errorf("unexpected error recorded for last attempt: %+v", last)
+			}
+			for i, attempt := range attempts[:len(attempts)-1] {
+				if !attempt.Transient {
+					t.Errorf("attempt %d should be recorded as transient: %+v", i+1, attempt)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicy_Do_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	policy := Policy{MaxAttempts: 3, InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+
+	attempts, err := policy.Do(ctx, "test", func(ctx context.Context) error {
+		cancel()
+		return syscall.ECONNRESET
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(attempts) != 1 || attempts[0].Transient {
+		t.Errorf("expected a single non transient attempt, got %+v", attempts)
+	}
+}
@@ -0,0 +1,95 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// SourceChecker is implemented by resolvers that can verify a secret source without fetching the secret
+type SourceChecker interface {
+	// Check returns an error if the value is malformed or its source is not available
+	Check(value string) error
+}
+
+// Check verifies that a secret can be resolved without fetching its value, e.g. that an environment variable is
+// set or a secret file exists. Commands of exec secrets are not run.
+func Check(secretType, value string) error {
+	if secretType == "" {
+		if value == "" {
+			return errors.New("secret value is empty, its source cannot be detected")
+		}
+		secretType = detectType(value)
+	}
+
+	resolver, ok := resolvers[secretType]
+	if !ok {
+		return fmt.Errorf("unknown secret source %s", secretType)
+	}
+	if checker, ok := resolver.(SourceChecker); ok {
+		return checker.Check(normalizeValue(secretType, value))
+	}
+	return nil
+}
+
+func (r *EnvVarResolver) Check(value string) error {
+	matches := envVarRegex.FindStringSubmatch(value)
+	if len(matches) < 2 {
+		return fmt.Errorf("malformed environment variable secret spec '%s', should be in format '${VAR}'", value)
+	}
+	if _, ok := os.LookupEnv(matches[1]); !ok {
+		return fmt.Errorf("environment variable %q not found", matches[1])
+	}
+	return nil
+}
+
+func (r *FileResolver) Check(value string) error {
+	path := strings.TrimSpace(strings.TrimPrefix(value, fileScheme))
+	if path == "" {
+		return fmt.Errorf("malformed file secret spec '%s', path cannot be empty", value)
+	}
+	return checkFileExists(r.BaseDir, path)
+}
+
+func (r *ExecResolver) Check(value string) error {
+	fields := strings.Fields(strings.TrimPrefix(value, execScheme))
+	if len(fields) == 0 {
+		return fmt.Errorf("malformed exec secret spec '%s', command cannot be empty", value)
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		return fmt.Errorf("secret command %q requires sh, which is not available", fields[0])
+	}
+	if _, err := exec.LookPath(fields[0]); err != nil {
+		return fmt.Errorf("secret command %q not found", fields[0])
+	}
+	return nil
+}
+
+func (r *SopsResolver) Check(value string) error {
+	path, keyPath, _ := strings.Cut(strings.TrimPrefix(value, sopsScheme), "#")
+	path = strings.TrimSpace(path)
+	if path == "" || strings.TrimSpace(keyPath) == "" {
+		return fmt.Errorf("malformed sops secret spec '%s', should be in format 'sops://<file>#<key path>'", value)
+	}
+	return checkFileExists(r.BaseDir, path)
+}
+
+func checkFileExists(baseDir, path string) error {
+	path, err := expandPath(baseDir, path)
+	if err != nil {
+		return err
+	}
+
+	stat, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("secret file %q does not exist", path)
+	} else if err != nil {
+		return fmt.Errorf("failed to stat secret file %q: %w", path, err)
+	}
+	if stat.IsDir() {
+		return fmt.Errorf("secret file %q is a directory", path)
+	}
+	return nil
+}
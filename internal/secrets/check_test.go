@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/timo-reymann/ContainerHive/internal/vault"
+)
+
+func TestCheck(t *testing.T) {
+	baseDir := t.TempDir()
+	SetProjectRoot(baseDir)
+	t.Cleanup(func() { SetProjectRoot("") })
+	if err := os.WriteFile(filepath.Join(baseDir, "token"), []byte("value"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(baseDir, "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_CHECK_SECRET", "value")
+	t.Setenv("VAULT_ADDR", "")
+	vault.Configure(vault.Config{})
+
+	tests := []struct {
+		name          string
+		secretType    string
+		value         string
+		errorContains string
+	}{
+		{name: "plain text", value: "plain"},
+		{name: "env var set", value: "${TEST_CHECK_SECRET}"},
+		{name: "env var missing", value: "$TEST_CHECK_MISSING", errorContains: `environment variable "TEST_CHECK_MISSING" not found`},
+		{name: "env var name with explicit type", secretType: "env", value: "TEST_CHECK_SECRET"},
+		{name: "env var malformed", secretType: "env", value: "TEST CHECK", errorContains: "malformed environment variable secret spec"},
+		{name: "relative file", value: "file://token"},
+		{name: "file without scheme", secretType: "file", value: "token"},
+		{name: "missing file", value: "file://missing", errorContains: "does not exist"},
+		{name: "file is directory", value: "file://dir", errorContains: "is a directory"},
+		{name: "exec command exists", value: "exec://sh -c 'echo secret'"},
+		{name: "exec command missing", value: "exec://hive-missing-command --token", errorContains: `secret command "hive-missing-command" not found`},
+		{name: "sops file exists", value: "sops://token#registry.token"},
+		{name: "sops without key path", value: "sops://token", errorContains: "malformed sops secret spec"},
+		{name: "vault spec malformed", value: "vault://secret/data/app", errorContains: "malformed vault secret spec"},
+		{name: "vault not configured", value: "vault://secret/data/app#token", errorContains: "VAULT_ADDR not set"},
+		{name: "unknown source", secretType: "keychain", value: "token", errorContains: "unknown secret source keychain"},
+		{name: "empty value", errorContains: "secret value is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.secretType, tt.value)
+			if tt.errorContains == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errorContains, err)
+			}
+		})
+	}
+}
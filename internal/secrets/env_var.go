@@ -16,7 +16,7 @@ type EnvVarResolver struct{}
 func (r *EnvVarResolver) Resolve(value string) (resolvedValue string, err error) {
 	matches := envVarRegex.FindStringSubmatch(value)
 	if len(matches) < 2 {
-		return "", nil
+		return "", fmt.Errorf("malformed environment variable secret spec '%s', should be in format '${VAR}'", value)
 	}
 	name := matches[1] // Use the captured group, not the full match
 	val, ok := os.LookupEnv(name)
@@ -0,0 +1,69 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const execResolver = "exec"
+
+const execScheme = "exec://"
+
+// DefaultExecTimeout is the maximum time a secret command may run when no timeout is configured
+const DefaultExecTimeout = 30 * time.Second
+
+// ExecResolver resolves secrets by running a command and using its stdout (e.g., exec://pass show ci/npm-token)
+// The command is run using sh, a single trailing newline is stripped from the output.
+type ExecResolver struct {
+	Timeout time.Duration
+}
+
+func (r *ExecResolver) Resolve(value string) (resolvedValue string, err error) {
+	if !strings.HasPrefix(value, execScheme) {
+		return "", nil
+	}
+
+	command := strings.TrimSpace(strings.TrimPrefix(value, execScheme))
+	if command == "" {
+		return "", fmt.Errorf("malformed exec secret spec '%s', command cannot be empty", value)
+	}
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultExecTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Do not wait for child processes still holding the output pipes after the command was killed
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("secret command %q timed out after %s", command, timeout)
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("secret command %q failed with exit code %d: %s", command, exitErr.ExitCode(), strings.TrimSpace(stderr.String()))
+		}
+		return "", errors.Join(fmt.Errorf("failed to run secret command %q", command), err)
+	}
+
+	output := strings.TrimSuffix(strings.TrimSuffix(stdout.String(), "\n"), "\r")
+	if output == "" {
+		return "", fmt.Errorf("secret command %q produced no output", command)
+	}
+
+	return output, nil
+}
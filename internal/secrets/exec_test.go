@@ -0,0 +1,119 @@
+package secrets
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExecResolver_Resolve(t *testing.T) {
+	resolver := &ExecResolver{Timeout: 2 * time.Second}
+
+	tests := []struct {
+		name          string
+		value         string
+		expectedValue string
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:          "non-exec value",
+			value:         "plain-text-secret",
+			expectedValue: "",
+			expectError:   false,
+		},
+		{
+			name:          "command output with trailing newline stripped",
+			value:         "exec://echo command-value",
+			expectedValue: "command-value",
+			expectError:   false,
+		},
+		{
+			name:          "command with pipe",
+			value:         "exec://printf 'first\\nsecond\\n' | head -n 1",
+			expectedValue: "first",
+			expectError:   false,
+		},
+		{
+			name:          "multi-line output keeps inner newlines",
+			value:         "exec://printf 'line1\\nline2'",
+			expectedValue: "line1\nline2",
+			expectError:   false,
+		},
+		{
+			name:          "empty command",
+			value:         "exec://",
+			expectedValue: "",
+			expectError:   true,
+			errorContains: "command cannot be empty",
+		},
+		{
+			name:          "failing command",
+			value:         "exec://echo not found >&2; exit 3",
+			expectedValue: "",
+			expectError:   true,
+			errorContains: "failed with exit code 3: not found",
+		},
+		{
+			name:          "command without output",
+			value:         "exec://true",
+			expectedValue: "",
+			expectError:   true,
+			errorContains: "produced no output",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := resolver.Resolve(tt.value)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				} else if tt.errorContains != "" && !containsError(err, tt.errorContains) {
+					t.Errorf("expected error containing %q, got %q", tt.errorContains, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if value != tt.expectedValue {
+				t.Errorf("expected value %q, got %q", tt.expectedValue, value)
+			}
+		})
+	}
+}
+
+func TestExecResolver_Timeout(t *testing.T) {
+	resolver := &ExecResolver{Timeout: 100 * time.Millisecond}
+
+	_, err := resolver.Resolve("exec://sleep 5")
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+	if !containsError(err, "timed out after 100ms") {
+		t.Errorf("expected timeout error, got %q", err.Error())
+	}
+}
+
+func TestResolve_ExecWithoutScheme(t *testing.T) {
+	value, err := Resolve("exec", "echo explicit")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != "explicit" {
+		t.Errorf("expected value %q, got %q", "explicit", value)
+	}
+}
+
+func TestSetExecTimeout(t *testing.T) {
+	SetExecTimeout(100 * time.Millisecond)
+	t.Cleanup(func() { SetExecTimeout(0) })
+
+	_, err := Resolve("exec", "sleep 5")
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+	if !containsError(err, "timed out after 100ms") {
+		t.Errorf("expected timeout error, got %q", err.Error())
+	}
+}
@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const fileResolver = "file"
+
+const fileScheme = "file://"
+
+// FileResolver resolves secrets from files (e.g., file://~/.npmrc or file://secrets/token)
+// Relative paths are resolved against BaseDir, which is usually the project root.
+type FileResolver struct {
+	BaseDir string
+}
+
+func (r *FileResolver) Resolve(value string) (resolvedValue string, err error) {
+	if !strings.HasPrefix(value, fileScheme) {
+		return "", nil
+	}
+
+	path := strings.TrimSpace(strings.TrimPrefix(value, fileScheme))
+	if path == "" {
+		return "", fmt.Errorf("malformed file secret spec '%s', path cannot be empty", value)
+	}
+
+	path, err = expandPath(r.BaseDir, path)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("secret file %q does not exist", path)
+		}
+		return "", errors.Join(fmt.Errorf("failed to read secret file %q", path), err)
+	}
+
+	if len(content) == 0 {
+		return "", fmt.Errorf("secret file %q is empty", path)
+	}
+
+	return string(content), nil
+}
+
+// expandPath expands ~ to the home directory and resolves relative paths against baseDir
+func expandPath(baseDir, path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", errors.Join(errors.New("failed to determine home directory for secret file"), err)
+		}
+		return filepath.Join(home, path[1:]), nil
+	}
+
+	if filepath.IsAbs(path) || baseDir == "" {
+		return path, nil
+	}
+
+	return filepath.Join(baseDir, path), nil
+}
@@ -0,0 +1,124 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileResolver_Resolve(t *testing.T) {
+	baseDir := t.TempDir()
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+
+	absoluteFile := filepath.Join(t.TempDir(), "absolute")
+	if err := os.WriteFile(absoluteFile, []byte("absolute-value"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(baseDir, "secrets"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "secrets", "token"), []byte("relative-value\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(homeDir, ".npmrc"), []byte("home-value"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "empty"), []byte{}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := &FileResolver{BaseDir: baseDir}
+
+	tests := []struct {
+		name          string
+		value         string
+		expectedValue string
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:          "non-file value",
+			value:         "plain-text-secret",
+			expectedValue: "",
+			expectError:   false,
+		},
+		{
+			name:          "absolute path",
+			value:         "file://" + absoluteFile,
+			expectedValue: "absolute-value",
+			expectError:   false,
+		},
+		{
+			name:          "path relative to base dir keeps content as-is",
+			value:         "file://secrets/token",
+			expectedValue: "relative-value\n",
+			expectError:   false,
+		},
+		{
+			name:          "path relative to home dir",
+			value:         "file://~/.npmrc",
+			expectedValue: "home-value",
+			expectError:   false,
+		},
+		{
+			name:          "empty path",
+			value:         "file://",
+			expectedValue: "",
+			expectError:   true,
+			errorContains: "path cannot be empty",
+		},
+		{
+			name:          "missing file",
+			value:         "file://secrets/missing",
+			expectedValue: "",
+			expectError:   true,
+			errorContains: "does not exist",
+		},
+		{
+			name:          "empty file",
+			value:         "file://empty",
+			expectedValue: "",
+			expectError:   true,
+			errorContains: "is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := resolver.Resolve(tt.value)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				} else if tt.errorContains != "" && !containsError(err, tt.errorContains) {
+					t.Errorf("expected error containing %q, got %q", tt.errorContains, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if value != tt.expectedValue {
+				t.Errorf("expected value %q, got %q", tt.expectedValue, value)
+			}
+		})
+	}
+}
+
+func TestResolve_FileWithoutScheme(t *testing.T) {
+	baseDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(baseDir, "token"), []byte("file-value"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	SetProjectRoot(baseDir)
+	t.Cleanup(func() { SetProjectRoot("") })
+
+	value, err := Resolve("file", "token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != "file-value" {
+		t.Errorf("expected value %q, got %q", "file-value", value)
+	}
+}
@@ -2,14 +2,14 @@ package secrets
 
 import (
 	"fmt"
+	"strings"
+	"time"
 )
 
 // SecretResolver interface defines the method for resolving secrets
 type SecretResolver interface {
-	// Resolve takes a secret value and returns the resolved secret or an error
-	// Implementations should only return an error if the value is invalid.
-	// When it does not return an error and an empty string is returned, the secret is marked as not being
-	// handled by this resolver.
+	// Resolve takes a secret value normalized by normalizeValue and returns the resolved secret or an error
+	// if the value is malformed or its source is not available.
 	Resolve(value string) (resolvedValue string, err error)
 }
 
@@ -19,40 +19,73 @@ type Secret struct {
 	Value string
 }
 
-var resolverOrder = []string{
-	envVarResolver,
-	plainTextResolver,
-	vaultResolver,
-}
-
 var resolvers = map[string]SecretResolver{
 	plainTextResolver: &PlainTextResolver{},
 	envVarResolver:    &EnvVarResolver{},
 	vaultResolver:     &VaultSecretResolver{},
+	fileResolver:      &FileResolver{},
+	execResolver:      &ExecResolver{},
+	sopsResolver:      &SopsResolver{},
 }
 
-// Resolve resolves a secret value using the registered resolvers in priority order
+// schemes of resolvers that are detected by a prefix of the value,
+// when the type is set explicitly the prefix can be omitted
+var schemes = map[string]string{
+	vaultResolver: vaultScheme,
+	fileResolver:  fileScheme,
+	execResolver:  execScheme,
+	sopsResolver:  sopsScheme,
+}
+
+// SetProjectRoot configures the directory relative file and sops secrets are resolved against
+func SetProjectRoot(root string) {
+	resolvers[fileResolver].(*FileResolver).BaseDir = root
+	resolvers[sopsResolver].(*SopsResolver).BaseDir = root
+}
+
+// SetExecTimeout configures the maximum time exec secret commands may run, zero restores the default
+func SetExecTimeout(timeout time.Duration) {
+	resolvers[execResolver].(*ExecResolver).Timeout = timeout
+}
+
+// Resolve resolves a secret value using the resolver of the given type, detecting it from the value if empty
 func Resolve(secretType, value string) (string, error) {
-	if secretType != "" {
-		resolver, ok := resolvers[secretType]
-		if !ok {
+	if secretType == "" {
+		if value == "" {
+			// If no resolvers could handle this, return a generic error, the value itself might be sensitive
 			return "", fmt.Errorf("no resolver could handle secret of type %s", secretType)
 		}
-		return resolver.Resolve(value)
+		secretType = detectType(value)
 	}
 
-	for _, rtype := range resolverOrder {
-		resolver := resolvers[rtype]
-		resolved, err := resolver.Resolve(value)
-		if resolved != "" {
-			return resolved, nil
-		}
+	resolver, ok := resolvers[secretType]
+	if !ok {
+		return "", fmt.Errorf("no resolver could handle secret of type %s", secretType)
+	}
+	return resolver.Resolve(normalizeValue(secretType, value))
+}
 
-		if err != nil {
-			return "", err
-		}
+// normalizeValue adds the prefix of the resolver to values that omit it, which is allowed when the type is set
+// explicitly. Environment variables given by name are turned into ${NAME}.
+func normalizeValue(secretType, value string) string {
+	if scheme, ok := schemes[secretType]; ok && !strings.HasPrefix(value, scheme) {
+		return scheme + value
+	}
+	if secretType == envVarResolver && !strings.HasPrefix(value, "$") {
+		return "${" + value + "}"
 	}
+	return value
+}
 
-	// If no resolvers could handle this, return a generic error
-	return "", fmt.Errorf("no resolver could handle secret of type %s with value: %s", secretType, value)
+// detectType returns the resolver of a value without an explicit type
+func detectType(value string) string {
+	if envVarRegex.MatchString(value) {
+		return envVarResolver
+	}
+	for resolverType, scheme := range schemes {
+		if strings.HasPrefix(value, scheme) {
+			return resolverType
+		}
+	}
+	return plainTextResolver
 }
@@ -112,13 +112,15 @@ func TestEnvVarResolver_Resolve(t *testing.T) {
 			name:          "invalid env var format",
 			value:         "not-an-env-var",
 			expectedValue: "",
-			expectError:   false, // Should return empty string for non-matching format
+			expectError:   true,
+			errorContains: "malformed environment variable secret spec",
 		},
 		{
 			name:          "env var with invalid name",
 			value:         "${123INVALID}",
 			expectedValue: "",
-			expectError:   false, // Should return empty string for invalid names
+			expectError:   true,
+			errorContains: "malformed environment variable secret spec",
 		},
 	}
 
@@ -171,6 +173,29 @@ func TestResolve(t *testing.T) {
 			expectedValue: "env-secret-value",
 			expectError:   false,
 		},
+		{
+			name:          "env var name with explicit type",
+			secretType:    "env",
+			value:         "TEST_ENV_SECRET",
+			expectedValue: "env-secret-value",
+			expectError:   false,
+		},
+		{
+			name:          "non-existent env var name with explicit type",
+			secretType:    "env",
+			value:         "NON_EXISTENT_VAR",
+			expectedValue: "",
+			expectError:   true,
+			errorContains: "not found",
+		},
+		{
+			name:          "invalid env var name with explicit type",
+			secretType:    "env",
+			value:         "not-an-env-var",
+			expectedValue: "",
+			expectError:   true,
+			errorContains: "malformed environment variable secret spec",
+		},
 		{
 			name:          "plain text secret auto-detected",
 			secretType:    "",
@@ -0,0 +1,140 @@
+package secrets
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RedactedPlaceholder replaces secret values in redacted output
+const RedactedPlaceholder = "***"
+
+// Redactor masks registered secret values in output.
+// It is safe for concurrent use, values can be added while output is already being redacted.
+type Redactor struct {
+	mu       sync.RWMutex
+	values   map[string]struct{}
+	replacer *strings.Replacer
+}
+
+func NewRedactor() *Redactor {
+	return &Redactor{
+		values: make(map[string]struct{}),
+	}
+}
+
+// Add registers a secret value to be masked. For multi-line values every line is registered as well,
+// as line based output would otherwise leak them.
+func (r *Redactor) Add(value string) {
+	candidates := []string{value}
+	if strings.Contains(value, "\n") {
+		candidates = append(candidates, strings.Split(value, "\n")...)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changed := false
+	for _, candidate := range candidates {
+		candidate = strings.TrimRight(candidate, "\r")
+		if strings.TrimSpace(candidate) == "" {
+			continue
+		}
+		if _, ok := r.values[candidate]; ok {
+			continue
+		}
+		r.values[candidate] = struct{}{}
+		changed = true
+	}
+
+	if changed {
+		r.replacer = r.buildReplacer()
+	}
+}
+
+// buildReplacer creates a replacer matching longer values first, so secrets containing other secrets are fully masked
+func (r *Redactor) buildReplacer() *strings.Replacer {
+	values := make([]string, 0, len(r.values))
+	for value := range r.values {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+
+	oldnew := make([]string, 0, len(values)*2)
+	for _, value := range values {
+		oldnew = append(oldnew, value, RedactedPlaceholder)
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+// Redact masks all registered secret values in s
+func (r *Redactor) Redact(s string) string {
+	r.mu.RLock()
+	replacer := r.replacer
+	r.mu.RUnlock()
+
+	if replacer == nil {
+		return s
+	}
+	return replacer.Replace(s)
+}
+
+// RedactBytes masks all registered secret values in b
+func (r *Redactor) RedactBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return []byte(r.Redact(string(b)))
+}
+
+// Writer returns a writer masking all registered secret values before passing output on to w.
+// Output is forwarded line by line, so values split across multiple writes are masked as well.
+// Close must be called to flush a trailing incomplete line, it does not close w.
+func (r *Redactor) Writer(w io.Writer) io.WriteCloser {
+	return &redactingWriter{redactor: r, out: w}
+}
+
+type redactingWriter struct {
+	mu       sync.Mutex
+	redactor *Redactor
+	out      io.Writer
+	buf      []byte
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	end := bytes.LastIndexByte(w.buf, '\n')
+	if end < 0 {
+		return len(p), nil
+	}
+
+	complete := w.buf[:end+1]
+	if _, err := io.WriteString(w.out, w.redactor.Redact(string(complete))); err != nil {
+		return 0, err
+	}
+	w.buf = append(w.buf[:0], w.buf[end+1:]...)
+
+	return len(p), nil
+}
+
+func (w *redactingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(w.out, w.redactor.Redact(string(w.buf)))
+	w.buf = nil
+	return err
+}
@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		input    string
+		expected string
+	}{
+		{
+			name:     "no registered values",
+			input:    "token=abc",
+			expected: "token=abc",
+		},
+		{
+			name:     "single value",
+			values:   []string{"s3cr3t"},
+			input:    "using s3cr3t to login, s3cr3t again",
+			expected: "using *** to login, *** again",
+		},
+		{
+			name:     "longer value containing shorter value",
+			values:   []string{"abc", "abcdef"},
+			input:    "value abcdef",
+			expected: "value ***",
+		},
+		{
+			name:     "multi-line value lines are masked individually",
+			values:   []string{"line-one\nline-two\n"},
+			input:    "#5 0.1 line-two",
+			expected: "#5 0.1 ***",
+		},
+		{
+			name:     "whitespace only values are ignored",
+			values:   []string{"", "  ", "\n"},
+			input:    "a b c",
+			expected: "a b c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRedactor()
+			for _, v := range tt.values {
+				r.Add(v)
+			}
+
+			if got := r.Redact(tt.input); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRedactor_Writer(t *testing.T) {
+	r := NewRedactor()
+	r.Add("s3cr3t")
+
+	var out bytes.Buffer
+	w := r.Writer(&out)
+	for _, chunk := range []string{"first s3", "cr3t line\nsecond ", "s3cr3t"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if got := out.String(); got != "first *** line\n" {
+		t.Errorf("expected only the complete line to be written, got %q", got)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := out.String(); got != "first *** line\nsecond ***" {
+		t.Errorf("expected %q, got %q", "first *** line\nsecond ***", got)
+	}
+}
+
+func TestRunResolver_RegistersResolvedValues(t *testing.T) {
+	resolver := NewRunResolver()
+	if _, err := resolver.Resolve("plain", "my-token"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := resolver.Redactor().Redact("token is my-token"); got != "token is ***" {
+		t.Errorf("expected resolved value to be redacted, got %q", got)
+	}
+}
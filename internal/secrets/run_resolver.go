@@ -0,0 +1,57 @@
+package secrets
+
+import "sync"
+
+type runResolverKey struct {
+	secretType string
+	value      string
+}
+
+type runResolverEntry struct {
+	once  sync.Once
+	value string
+	err   error
+}
+
+// RunResolver resolves each unique combination of secret type and value at most once for the duration of a run.
+// It is safe for concurrent use, concurrent requests for the same secret wait for the first resolution.
+// Every resolved value is registered with the redactor of the run.
+type RunResolver struct {
+	mu       sync.Mutex
+	entries  map[runResolverKey]*runResolverEntry
+	redactor *Redactor
+}
+
+func NewRunResolver() *RunResolver {
+	return &RunResolver{
+		entries:  make(map[runResolverKey]*runResolverEntry),
+		redactor: NewRedactor(),
+	}
+}
+
+// Redactor returns the redactor masking all secrets resolved during the run
+func (r *RunResolver) Redactor() *Redactor {
+	return r.redactor
+}
+
+// Resolve works like the package level Resolve, but returns the cached result for secrets resolved before
+func (r *RunResolver) Resolve(secretType, value string) (string, error) {
+	key := runResolverKey{secretType: secretType, value: value}
+
+	r.mu.Lock()
+	entry, ok := r.entries[key]
+	if !ok {
+		entry = &runResolverEntry{}
+		r.entries[key] = entry
+	}
+	r.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.value, entry.err = Resolve(secretType, value)
+		if entry.err == nil {
+			r.redactor.Add(entry.value)
+		}
+	})
+
+	return entry.value, entry.err
+}
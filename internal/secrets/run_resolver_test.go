@@ -0,0 +1,72 @@
+package secrets
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type countingResolver struct {
+	calls atomic.Int32
+}
+
+func (r *countingResolver) Resolve(value string) (string, error) {
+	r.calls.Add(1)
+	if value == "fail" {
+		return "", errors.New("resolution failed")
+	}
+	return "resolved-" + value, nil
+}
+
+func registerCountingResolver(t *testing.T) *countingResolver {
+	t.Helper()
+	resolver := &countingResolver{}
+	resolvers["counting"] = resolver
+	t.Cleanup(func() { delete(resolvers, "counting") })
+	return resolver
+}
+
+func TestRunResolver_ResolvesOncePerSecret(t *testing.T) {
+	counting := registerCountingResolver(t)
+	resolver := NewRunResolver()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := resolver.Resolve("counting", "token")
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if value != "resolved-token" {
+				t.Errorf("expected value %q, got %q", "resolved-token", value)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := resolver.Resolve("counting", "other"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := counting.calls.Load(); got != 2 {
+		t.Errorf("expected 2 resolutions, got %d", got)
+	}
+}
+
+func TestRunResolver_CachesErrors(t *testing.T) {
+	counting := registerCountingResolver(t)
+	resolver := NewRunResolver()
+
+	for i := 0; i < 3; i++ {
+		if _, err := resolver.Resolve("counting", "fail"); err == nil {
+			t.Fatal("expected error but got none")
+		}
+	}
+
+	if got := counting.calls.Load(); got != 1 {
+		t.Errorf("expected 1 resolution, got %d", got)
+	}
+}
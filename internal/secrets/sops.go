@@ -0,0 +1,48 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/timo-reymann/ContainerHive/internal/sops"
+)
+
+const sopsResolver = "sops"
+
+const sopsScheme = "sops://"
+
+// SopsResolver resolves secrets from sops encrypted files (e.g., sops://secrets.enc.yaml#registry.token)
+// Files are decrypted locally using age identities, relative paths are resolved against BaseDir.
+type SopsResolver struct {
+	BaseDir string
+}
+
+func (r *SopsResolver) Resolve(value string) (resolvedValue string, err error) {
+	if !strings.HasPrefix(value, sopsScheme) {
+		return "", nil
+	}
+
+	// Expected format: sops://<file>#<key path>
+	path, keyPath, _ := strings.Cut(strings.TrimPrefix(value, sopsScheme), "#")
+	path = strings.TrimSpace(path)
+	keyPath = strings.TrimSpace(keyPath)
+	if path == "" || keyPath == "" {
+		return "", fmt.Errorf("malformed sops secret spec '%s', should be in format 'sops://<file>#<key path>'", value)
+	}
+
+	path, err = expandPath(r.BaseDir, path)
+	if err != nil {
+		return "", err
+	}
+
+	resolvedValue, err = sops.GetSecret(path, keyPath)
+	if err != nil {
+		return "", err
+	}
+
+	if resolvedValue == "" {
+		return "", fmt.Errorf("value for '%s' in sops file %q is empty", keyPath, path)
+	}
+
+	return resolvedValue, nil
+}
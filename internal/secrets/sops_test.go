@@ -0,0 +1,112 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const sopsTestdata = "../sops/testdata"
+
+func setupSopsIdentity(t *testing.T) {
+	t.Helper()
+	// registers the restore of a key configured outside the test before unsetting it
+	t.Setenv("SOPS_AGE_KEY", "")
+	os.Unsetenv("SOPS_AGE_KEY")
+	t.Setenv("SOPS_AGE_KEY_FILE", filepath.Join(sopsTestdata, "age.key"))
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+}
+
+func TestSopsResolver_Resolve(t *testing.T) {
+	setupSopsIdentity(t)
+	resolver := &SopsResolver{BaseDir: sopsTestdata}
+
+	tests := []struct {
+		name          string
+		value         string
+		expectedValue string
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:          "non-sops value",
+			value:         "plain-text-secret",
+			expectedValue: "",
+			expectError:   false,
+		},
+		{
+			name:          "nested key",
+			value:         "sops://secrets.enc.yaml#registry.token",
+			expectedValue: "registry-s3cr3t",
+			expectError:   false,
+		},
+		{
+			name:          "json file",
+			value:         "sops://secrets.enc.json#database.password",
+			expectedValue: "db-s3cr3t",
+			expectError:   false,
+		},
+		{
+			name:          "missing key path",
+			value:         "sops://secrets.enc.yaml",
+			expectedValue: "",
+			expectError:   true,
+			errorContains: "malformed sops secret spec",
+		},
+		{
+			name:          "missing file",
+			value:         "sops://#registry.token",
+			expectedValue: "",
+			expectError:   true,
+			errorContains: "malformed sops secret spec",
+		},
+		{
+			name:          "unknown key",
+			value:         "sops://secrets.enc.yaml#registry.password",
+			expectedValue: "",
+			expectError:   true,
+			errorContains: "no key 'password'",
+		},
+		{
+			name:          "non-existent file",
+			value:         "sops://missing.enc.yaml#token",
+			expectedValue: "",
+			expectError:   true,
+			errorContains: "failed to decrypt sops file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := resolver.Resolve(tt.value)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				} else if tt.errorContains != "" && !containsError(err, tt.errorContains) {
+					t.Errorf("expected error containing %q, got %q", tt.errorContains, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if value != tt.expectedValue {
+				t.Errorf("expected value %q, got %q", tt.expectedValue, value)
+			}
+		})
+	}
+}
+
+func TestResolve_SopsWithoutScheme(t *testing.T) {
+	setupSopsIdentity(t)
+	SetProjectRoot(sopsTestdata)
+	t.Cleanup(func() { SetProjectRoot("") })
+
+	value, err := Resolve("sops", "secrets.enc.yaml#npm.tokens.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != "first-token" {
+		t.Errorf("expected value %q, got %q", "first-token", value)
+	}
+}
@@ -2,6 +2,8 @@ package secrets
 
 import (
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/timo-reymann/ContainerHive/internal/vault"
@@ -9,37 +11,84 @@ import (
 
 const vaultResolver = "vault"
 
+const vaultScheme = "vault://"
+
 type VaultSecretResolver struct {
 }
 
 func (v VaultSecretResolver) Resolve(value string) (resolvedValue string, err error) {
-	if !strings.HasPrefix(value, "vault://") {
+	if !strings.HasPrefix(value, vaultScheme) {
 		return "", nil
 	}
 
-	// Parse the vault secret specification
-	// Expected format: vault://<path>#<field>
-	spec := value[8:] // Remove "vault://" prefix
+	path, field, version, err := parseVaultSpec(value)
+	if err != nil {
+		return "", err
+	}
+
+	// Use the vault client to get the secret
+	return vault.GetSecretVersionWithDefaultConfiguration(path, field, version)
+}
+
+// Check verifies the secret spec and that vault is configured, without connecting to it
+func (v VaultSecretResolver) Check(value string) error {
+	if _, _, _, err := parseVaultSpec(value); err != nil {
+		return err
+	}
+	return vault.CheckConfiguration()
+}
+
+// parseVaultSpec parses a secret specification in the format vault://<path>#<field>[?version=<version>]
+func parseVaultSpec(value string) (path string, field string, version int, err error) {
+	spec := strings.TrimPrefix(value, vaultScheme)
 	if spec == "" {
-		return "", fmt.Errorf("malformed vault secret spec '%s', missing path and field", value)
+		return "", "", 0, fmt.Errorf("malformed vault secret spec '%s', missing path and field", value)
 	}
 
 	specParts := strings.SplitN(spec, "#", 2)
 	if len(specParts) != 2 {
-		return "", fmt.Errorf("malformed vault secret spec '%s', should be in format 'vault://<path>#<field>'", value)
+		return "", "", 0, fmt.Errorf("malformed vault secret spec '%s', should be in format 'vault://<path>#<field>'", value)
 	}
 
-	path := strings.TrimSpace(specParts[0])
-	field := strings.TrimSpace(specParts[1])
+	path = strings.TrimSpace(specParts[0])
+	field, rawQuery, _ := strings.Cut(specParts[1], "?")
+	field = strings.TrimSpace(field)
 
 	if path == "" {
-		return "", fmt.Errorf("malformed vault secret spec '%s', path cannot be empty", value)
+		return "", "", 0, fmt.Errorf("malformed vault secret spec '%s', path cannot be empty", value)
 	}
 
 	if field == "" {
-		return "", fmt.Errorf("malformed vault secret spec '%s', field cannot be empty", value)
+		return "", "", 0, fmt.Errorf("malformed vault secret spec '%s', field cannot be empty", value)
 	}
 
-	// Use the vault client to get the secret
-	return vault.GetSecretWithDefaultConfiguration(path, field)
+	version, err = parseVaultVersion(value, rawQuery)
+	if err != nil {
+		return "", "", 0, err
+	}
+	return path, field, version, nil
+}
+
+func parseVaultVersion(value, rawQuery string) (int, error) {
+	if rawQuery == "" {
+		return 0, nil
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return 0, fmt.Errorf("malformed vault secret spec '%s', invalid options: %w", value, err)
+	}
+
+	for key := range query {
+		if key != "version" {
+			return 0, fmt.Errorf("malformed vault secret spec '%s', unknown option '%s'", value, key)
+		}
+	}
+
+	version, err := strconv.Atoi(query.Get("version"))
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("malformed vault secret spec '%s', version must be a positive number", value)
+	}
+
+	return version, nil
 }
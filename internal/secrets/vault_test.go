@@ -75,6 +75,20 @@ func TestVaultResolver_Resolve(t *testing.T) {
 			expectError:   true,
 			errorContains: "$HOME is not defined",
 		},
+		{
+			name:          "vault value with invalid version",
+			value:         "vault://secret/myapp#password?version=latest",
+			expectedValue: "",
+			expectError:   true,
+			errorContains: "version must be a positive number",
+		},
+		{
+			name:          "vault value with unknown option",
+			value:         "vault://secret/myapp#password?mount=kv",
+			expectedValue: "",
+			expectError:   true,
+			errorContains: "unknown option 'mount'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,188 @@
+package selection
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/timo-reymann/ContainerHive/internal/dependency"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// Selector restricts a run to a subset of the project. Empty filters match everything.
+type Selector struct {
+	// Images are glob patterns matched against the image names
+	Images []string
+	// Tags are glob patterns matched against the tag names of the selected images
+	Tags []string
+	// Variants are glob patterns matched against the variant names of the selected tags
+	Variants []string
+	// Labels are selectors like key=value, key!=value or key, all of them must match the image labels
+	Labels []string
+	// WithDependencies adds all images the selected images are based on
+	WithDependencies bool
+	// WithDependents adds all images based on the selected images
+	WithDependents bool
+}
+
+// IsEmpty reports if the selector selects the whole project
+func (s *Selector) IsEmpty() bool {
+	return len(s.Images) == 0 && len(s.Tags) == 0 && len(s.Variants) == 0 && len(s.Labels) == 0
+}
+
+// Validate checks the glob patterns and label selectors
+func (s *Selector) Validate() error {
+	for _, pattern := range slices.Concat(s.Images, s.Tags, s.Variants) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q", pattern)
+		}
+	}
+	for _, selector := range s.Labels {
+		if _, err := parseLabelSelector(selector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labelSelector matches a label by key, optionally requiring or excluding a value
+type labelSelector struct {
+	key      string
+	value    string
+	hasValue bool
+	negated  bool
+}
+
+// parseLabelSelector parses selectors like key=value, key!=value or key
+func parseLabelSelector(selector string) (*labelSelector, error) {
+	parsed := &labelSelector{}
+	key, value, negated := strings.Cut(selector, "!=")
+	if !negated {
+		key, value, parsed.hasValue = strings.Cut(selector, "=")
+	}
+	parsed.key = strings.TrimSpace(key)
+	parsed.value = strings.TrimSpace(value)
+	parsed.negated = negated
+	parsed.hasValue = parsed.hasValue || negated
+	if parsed.key == "" {
+		return nil, fmt.Errorf("invalid label selector %q", selector)
+	}
+	return parsed, nil
+}
+
+func (l *labelSelector) matches(labels model.Labels) bool {
+	actual, exists := labels[l.key]
+	switch {
+	case l.negated:
+		return !exists || actual != l.value
+	case l.hasValue:
+		return exists && actual == l.value
+	default:
+		return exists
+	}
+}
+
+// matchesAny reports if the name matches any of the patterns, no patterns match every name
+func matchesAny(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesLabels reports if the labels fulfill all selectors
+func (s *Selector) matchesLabels(labels model.Labels) bool {
+	for _, selector := range s.Labels {
+		parsed, err := parseLabelSelector(selector)
+		if err != nil || !parsed.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns a copy of the project containing only the selected images, tags and variants.
+// Images required as base of selected images are included with all their tags and variants,
+// as the selected images may be based on any of them.
+func (s *Selector) Apply(project *model.ContainerHiveProject, graph *dependency.Graph) (*model.ContainerHiveProject, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	selectedIdentifiers := make(map[string]bool)
+	var selectedNames []string
+	for identifier, image := range project.ImagesByIdentifier {
+		labels := model.Labels{}
+		maps.Copy(labels, project.Config.Labels)
+		maps.Copy(labels, image.Labels)
+		if matchesAny(s.Images, image.Name) && s.matchesLabels(labels) {
+			selectedIdentifiers[identifier] = true
+			selectedNames = append(selectedNames, image.Name)
+		}
+	}
+	if len(selectedNames) == 0 {
+		return nil, errors.New("no images match the selection")
+	}
+
+	var expandedNames, requiredNames []string
+	if graph != nil && (s.WithDependencies || s.WithDependents) {
+		expandedNames = graph.Expand(selectedNames, s.WithDependencies, s.WithDependents)
+	}
+	if graph != nil && s.WithDependencies {
+		// base images are needed with all tags, even if they are selected with a tag filter themselves
+		var dependencies []string
+		for _, name := range selectedNames {
+			dependencies = append(dependencies, graph.Dependencies(name)...)
+		}
+		requiredNames = graph.Expand(dependencies, true, false)
+	}
+
+	selected := *project
+	selected.ImagesByName = make(map[string][]*model.Image)
+	selected.ImagesByIdentifier = make(map[string]*model.Image)
+	for identifier, image := range project.ImagesByIdentifier {
+		switch {
+		case slices.Contains(requiredNames, image.Name):
+		case selectedIdentifiers[identifier]:
+			image = s.filterImage(image)
+			if len(image.Tags) == 0 {
+				continue
+			}
+		case !slices.Contains(expandedNames, image.Name):
+			continue
+		}
+		selected.ImagesByIdentifier[identifier] = image
+		selected.ImagesByName[image.Name] = append(selected.ImagesByName[image.Name], image)
+	}
+	if len(selected.ImagesByIdentifier) == 0 {
+		return nil, errors.New("no tags match the selection")
+	}
+
+	return &selected, nil
+}
+
+// filterImage returns a copy of the image with only the selected tags and variants
+func (s *Selector) filterImage(image *model.Image) *model.Image {
+	filtered := *image
+	filtered.Tags = make(map[string]*model.Tag)
+	for name, tag := range image.Tags {
+		if matchesAny(s.Tags, name) {
+			filtered.Tags[name] = tag
+		}
+	}
+	filtered.Variants = make(map[string]*model.ImageVariant)
+	for name, variant := range image.Variants {
+		if matchesAny(s.Variants, name) {
+			filtered.Variants[name] = variant
+		}
+	}
+	return &filtered
+}
@@ -0,0 +1,176 @@
+package selection
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timo-reymann/ContainerHive/internal/dependency"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+func testImage(name string, labels model.Labels, tags []string, variants ...string) *model.Image {
+	image := &model.Image{
+		Identifier: name,
+		Name:       name,
+		Labels:     labels,
+		Tags:       map[string]*model.Tag{},
+		Variants:   map[string]*model.ImageVariant{},
+	}
+	for _, tag := range tags {
+		image.Tags[tag] = &model.Tag{Name: tag}
+	}
+	for _, variant := range variants {
+		image.Variants[variant] = &model.ImageVariant{Name: variant}
+	}
+	return image
+}
+
+// testProject has python based on base and python-web based on python, tools is standalone
+func testProject() (*model.ContainerHiveProject, *dependency.Graph) {
+	project := &model.ContainerHiveProject{
+		Config:             &model.HiveProjectConfig{Labels: model.Labels{"team": "platform"}},
+		ImagesByName:       map[string][]*model.Image{},
+		ImagesByIdentifier: map[string]*model.Image{},
+	}
+	for _, image := range []*model.Image{
+		testImage("base", model.Labels{"tier": "base"}, []string{"3.20", "3.21"}),
+		testImage("python", model.Labels{"tier": "runtime"}, []string{"3.12", "3.13"}, "dev", "slim"),
+		testImage("python-web", model.Labels{"tier": "app", "team": "web"}, []string{"3.13"}),
+		testImage("tools", nil, []string{"latest"}),
+	} {
+		project.ImagesByName[image.Name] = []*model.Image{image}
+		project.ImagesByIdentifier[image.Identifier] = image
+	}
+
+	graph := dependency.NewGraph()
+	for name := range project.ImagesByName {
+		graph.AddImage(name)
+	}
+	graph.AddDependency("python", "base")
+	graph.AddDependency("python-web", "python")
+	return project, graph
+}
+
+// summarize returns the selected images with their tags and variants, e.g. python:3.13[dev]
+func summarize(project *model.ContainerHiveProject) []string {
+	var summary []string
+	for _, image := range project.ImagesByIdentifier {
+		for tag := range image.Tags {
+			entry := image.Name + ":" + tag
+			var variants []string
+			for variant := range image.Variants {
+				variants = append(variants, variant)
+			}
+			if len(variants) > 0 {
+				slices.Sort(variants)
+				entry += "[" + strings.Join(variants, ",") + "]"
+			}
+			summary = append(summary, entry)
+		}
+	}
+	slices.Sort(summary)
+	return summary
+}
+
+func TestSelector_Apply(t *testing.T) {
+	tests := []struct {
+		name          string
+		selector      Selector
+		expected      []string
+		errorContains string
+	}{
+		{
+			name:     "image glob",
+			selector: Selector{Images: []string{"python*"}},
+			expected: []string{"python-web:3.13", "python:3.12[dev,slim]", "python:3.13[dev,slim]"},
+		},
+		{
+			name:     "tag and variant globs",
+			selector: Selector{Images: []string{"python"}, Tags: []string{"*.13"}, Variants: []string{"sl*"}},
+			expected: []string{"python:3.13[slim]"},
+		},
+		{
+			name:     "images without matching tags are skipped",
+			selector: Selector{Tags: []string{"latest"}},
+			expected: []string{"tools:latest"},
+		},
+		{
+			name:     "label equals",
+			selector: Selector{Labels: []string{"tier=runtime"}},
+			expected: []string{"python:3.12[dev,slim]", "python:3.13[dev,slim]"},
+		},
+		{
+			name:     "image labels override project labels",
+			selector: Selector{Labels: []string{"team!=platform"}},
+			expected: []string{"python-web:3.13"},
+		},
+		{
+			name:     "label exists",
+			selector: Selector{Labels: []string{"tier", "tier!=base"}, Images: []string{"*web", "base"}},
+			expected: []string{"python-web:3.13"},
+		},
+		{
+			name:     "with dependencies includes all tags of base images",
+			selector: Selector{Images: []string{"python-web"}, Tags: []string{"3.13"}, WithDependencies: true},
+			expected: []string{"base:3.20", "base:3.21", "python-web:3.13", "python:3.12[dev,slim]", "python:3.13[dev,slim]"},
+		},
+		{
+			name:     "with dependents",
+			selector: Selector{Images: []string{"python"}, Tags: []string{"3.12"}, WithDependents: true},
+			expected: []string{"python-web:3.13", "python:3.12[dev,slim]"},
+		},
+		{
+			name:          "nothing selected",
+			selector:      Selector{Images: []string{"node*"}},
+			errorContains: "no images match the selection",
+		},
+		{
+			name:          "no matching tags",
+			selector:      Selector{Images: []string{"tools"}, Tags: []string{"1.0"}},
+			errorContains: "no tags match the selection",
+		},
+		{
+			name:          "invalid glob",
+			selector:      Selector{Images: []string{"python["}},
+			errorContains: `invalid pattern "python["`,
+		},
+		{
+			name:          "invalid label selector",
+			selector:      Selector{Labels: []string{"=runtime"}},
+			errorContains: `invalid label selector "=runtime"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, graph := testProject()
+			selected, err := tt.selector.Apply(project, graph)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, summarize(selected)); diff != "" {
+				t.Errorf("Apply() mismatch (-expected +got):\n%s", diff)
+			}
+			if len(project.ImagesByIdentifier["python"].Tags) != 2 {
+				t.Error("Apply() must not modify the original project")
+			}
+		})
+	}
+}
+
+func TestSelector_IsEmpty(t *testing.T) {
+	if !(&Selector{WithDependencies: true}).IsEmpty() {
+		t.Error("expansion without filters should select the whole project")
+	}
+	if (&Selector{Labels: []string{"tier"}}).IsEmpty() {
+		t.Error("label selector should not be empty")
+	}
+}
@@ -0,0 +1,71 @@
+package sops
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/getsops/sops/v3/decrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// Lookup returns the scalar value at the dot separated key path of a decrypted document.
+// Numeric path segments index into sequences.
+func Lookup(doc *yaml.Node, keyPath string) (string, error) {
+	node := doc
+	if node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		node = node.Content[0]
+	}
+
+	for _, segment := range strings.Split(keyPath, ".") {
+		next, err := child(node, segment)
+		if err != nil {
+			return "", fmt.Errorf("failed to lookup '%s': %w", keyPath, err)
+		}
+		node = next
+	}
+
+	if node.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("value at '%s' is not a scalar", keyPath)
+	}
+
+	return node.Value, nil
+}
+
+func child(node *yaml.Node, segment string) (*yaml.Node, error) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segment {
+				return node.Content[i+1], nil
+			}
+		}
+		return nil, fmt.Errorf("no key '%s'", segment)
+	case yaml.SequenceNode:
+		idx, err := strconv.Atoi(segment)
+		if err != nil || idx < 0 || idx >= len(node.Content) {
+			return nil, fmt.Errorf("invalid index '%s' for sequence of length %d", segment, len(node.Content))
+		}
+		return node.Content[idx], nil
+	default:
+		return nil, fmt.Errorf("cannot lookup '%s' in scalar value", segment)
+	}
+}
+
+// GetSecret decrypts the sops file at path and returns the value at keyPath.
+// Decryption and identity lookup (SOPS_AGE_KEY, SOPS_AGE_KEY_FILE, ...) are done by sops itself,
+// the format is derived from the file extension.
+func GetSecret(path, keyPath string) (string, error) {
+	cleartext, err := decrypt.File(path, "")
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("failed to decrypt sops file %q", path), err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(cleartext, &doc); err != nil {
+		return "", errors.Join(fmt.Errorf("failed to parse decrypted sops file %q", path), err)
+	}
+
+	return Lookup(&doc, keyPath)
+}
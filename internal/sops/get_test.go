@@ -0,0 +1,148 @@
+package sops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func setupTestIdentity(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv("SOPS_AGE_KEY", key)
+	// registers the restore of a key file configured outside the test before unsetting it
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	os.Unsetenv("SOPS_AGE_KEY_FILE")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+}
+
+func readTestFile(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGetSecret(t *testing.T) {
+	setupTestIdentity(t, readTestFile(t, "age.key"))
+
+	tests := []struct {
+		name     string
+		file     string
+		keyPath  string
+		expected string
+	}{
+		{name: "yaml nested string", file: "secrets.enc.yaml", keyPath: "registry.token", expected: "registry-s3cr3t"},
+		{name: "yaml unencrypted suffix", file: "secrets.enc.yaml", keyPath: "registry.user_unencrypted", expected: "builder"},
+		{name: "yaml sequence item", file: "secrets.enc.yaml", keyPath: "npm.tokens.1", expected: "second-token"},
+		{name: "yaml int", file: "secrets.enc.yaml", keyPath: "port", expected: "8080"},
+		{name: "yaml bool", file: "secrets.enc.yaml", keyPath: "enabled", expected: "true"},
+		{name: "yaml float", file: "secrets.enc.yaml", keyPath: "ratio", expected: "1.5"},
+		{name: "yaml multiline", file: "secrets.enc.yaml", keyPath: "multiline", expected: "line one\nline two\n"},
+		{name: "yaml mac only encrypted", file: "mac-only-encrypted.enc.yaml", keyPath: "registry.token", expected: "registry-s3cr3t"},
+		{name: "json", file: "secrets.enc.json", keyPath: "database.password", expected: "db-s3cr3t"},
+		{name: "json int", file: "secrets.enc.json", keyPath: "database.port", expected: "5432"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := GetSecret(filepath.Join("testdata", tt.file), tt.keyPath)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if value != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, value)
+			}
+		})
+	}
+}
+
+func TestGetSecret_Errors(t *testing.T) {
+	key := readTestFile(t, "age.key")
+	encrypted := readTestFile(t, "secrets.enc.yaml")
+
+	tests := []struct {
+		name          string
+		data          string
+		key           string
+		errorContains string
+	}{
+		{
+			name:          "tampered unencrypted value",
+			data:          strings.Replace(encrypted, "user_unencrypted: builder", "user_unencrypted: attacker", 1),
+			key:           key,
+			errorContains: "Failed to verify data integrity",
+		},
+		{
+			name:          "value moved to other key",
+			data:          strings.Replace(encrypted, "    token: ENC", "    other: ENC", 1),
+			key:           key,
+			errorContains: "failed to decrypt sops file",
+		},
+		{
+			name:          "wrong identity",
+			data:          encrypted,
+			key:           readTestFile(t, "other-age.key"),
+			errorContains: "failed to decrypt sops file",
+		},
+		{
+			name:          "not a sops file",
+			data:          "registry:\n  token: plain\n",
+			key:           key,
+			errorContains: "sops metadata not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestIdentity(t, tt.key)
+			path := filepath.Join(t.TempDir(), "secrets.enc.yaml")
+			if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := GetSecret(path, "registry.token")
+			if err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("expected error containing %q, got %q", tt.errorContains, err.Error())
+			}
+		})
+	}
+}
+
+func TestLookup_Errors(t *testing.T) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte("registry:\n  token: a\nnpm:\n  tokens: [a, b]\nport: 8080\n"), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		keyPath       string
+		errorContains string
+	}{
+		{name: "missing key", keyPath: "registry.password", errorContains: "no key 'password'"},
+		{name: "mapping value", keyPath: "registry", errorContains: "is not a scalar"},
+		{name: "index out of range", keyPath: "npm.tokens.5", errorContains: "invalid index '5'"},
+		{name: "lookup in scalar", keyPath: "port.value", errorContains: "cannot lookup 'value' in scalar value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Lookup(&doc, tt.keyPath)
+			if err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("expected error containing %q, got %q", tt.errorContains, err.Error())
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/anchore/syft/syft"
 	"github.com/anchore/syft/syft/format"
 	"github.com/anchore/syft/syft/sbom"
+	"github.com/timo-reymann/ContainerHive/internal/oci_layout"
 
 	_ "modernc.org/sqlite" // required for rpmdb and other features
 )
@@ -36,6 +37,17 @@ func (s *SBOMImageTool) GenerateSBOM(ctx context.Context, tarPath string) (*sbom
 	return syft.CreateSBOM(ctx, src, nil)
 }
 
+// GenerateSBOMFromStore generates the SBOM for the image with the given name in the OCI layout store
+func (s *SBOMImageTool) GenerateSBOMFromStore(ctx context.Context, store *oci_layout.Store, imageName string) (*sbom.SBOM, error) {
+	viewDir, cleanup, err := store.View(imageName)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	return s.GenerateSBOM(ctx, "oci-dir:"+viewDir)
+}
+
 func (s *SBOMImageTool) SerializeSBOM(sbom *sbom.SBOM, outputFormat string) ([]byte, error) {
 	encoder := s.encoders.GetByString(outputFormat)
 	if encoder == nil {
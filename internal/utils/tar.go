@@ -60,3 +60,54 @@ func ExtractTar(tarPath, destDir string) error {
 	}
 	return nil
 }
+
+// CreateTar writes the regular files and directories of srcDir to a tar archive, with paths relative to srcDir.
+func CreateTar(srcDir, tarPath string) error {
+	f, err := os.Create(tarPath)
+	if err != nil {
+		return errors.Join(errors.New("failed to create tar"), err)
+	}
+	defer f.Close()
+
+	tw := tar.NewWriter(f)
+	err = filepath.WalkDir(srcDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err != nil {
+		return errors.Join(errors.New("failed to write tar"), err)
+	}
+	return tw.Close()
+}
@@ -176,4 +176,38 @@ func verifyFileContent(t *testing.T, path, expectedContent string) {
 	if string(content) != expectedContent {
 		t.Errorf("file %s: expected content %q, got %q", path, expectedContent, string(content))
 	}
-}
\ No newline at end of file
+}
+func TestCreateTar(t *testing.T) {
+	srcDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(srcDir, "blobs", "sha256"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"index.json":          "{}",
+		"blobs/sha256/abc123": "blob",
+	}
+	for file, content := range files {
+		if err := os.WriteFile(filepath.Join(srcDir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tarPath := filepath.Join(t.TempDir(), "layout.tar")
+	if err := CreateTar(srcDir, tarPath); err != nil {
+		t.Fatalf("CreateTar failed: %v", err)
+	}
+
+	destDir := t.TempDir()
+	if err := ExtractTar(tarPath, destDir); err != nil {
+		t.Fatalf("ExtractTar failed: %v", err)
+	}
+	for file, expected := range files {
+		content, err := os.ReadFile(filepath.Join(destDir, file))
+		if err != nil {
+			t.Fatalf("expected file %s not found: %v", file, err)
+		}
+		if string(content) != expected {
+			t.Errorf("file %s = %q, want %q", file, content, expected)
+		}
+	}
+}
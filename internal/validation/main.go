@@ -0,0 +1,93 @@
+package validation
+
+import (
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/timo-reymann/ContainerHive/internal/secrets"
+	"github.com/timo-reymann/ContainerHive/pkg/discovery"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Validate checks the project config and the definitions of the selected images against the JSON schemas and runs
+// semantic checks, which would otherwise only fail during the build. References to other images are resolved against
+// the whole project. Secrets are checked for resolvable sources without fetching them.
+func Validate(project, selected *model.ContainerHiveProject) (discovery.Problems, error) {
+	problems, err := validateSchemas(project.ConfigFilePath, selected)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, image := range selected.ImagesByIdentifier {
+		document := loadDocument(image.DefinitionFilePath)
+		problems = append(problems, checkDependsOn(project, image, document)...)
+		problems = append(problems, checkSecrets(image, document)...)
+	}
+	problems = append(problems, checkTemplates(project, selected)...)
+
+	problems.Sort()
+	return problems, nil
+}
+
+// loadDocument parses the YAML file to locate problems, nil if it can not be parsed
+func loadDocument(path string) *yaml.Node {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil
+	}
+	return &document
+}
+
+// sequenceIndex returns the index of the sequence item with the given name, -1 if it does not exist
+func sequenceIndex(document *yaml.Node, key, name string) int {
+	items := discovery.NodeAt(document, key)
+	if items == nil {
+		return -1
+	}
+	for idx := range items.Content {
+		if nameNode := discovery.NodeAt(document, key, idx, "name"); nameNode != nil && nameNode.Value == name {
+			return idx
+		}
+	}
+	return -1
+}
+
+func checkDependsOn(project *model.ContainerHiveProject, image *model.Image, document *yaml.Node) discovery.Problems {
+	var problems discovery.Problems
+	for idx, name := range image.DependsOn {
+		if _, ok := project.ImagesByName[name]; !ok {
+			node := discovery.NodeAt(document, "depends_on", idx)
+			problems = append(problems, discovery.ProblemAt(image.DefinitionFilePath, node, "depends_on refers to image "+name+", which does not exist in the project"))
+		}
+	}
+	return problems
+}
+
+func checkSecrets(image *model.Image, document *yaml.Node) discovery.Problems {
+	var problems discovery.Problems
+	check := func(definitions model.Secrets, path ...any) {
+		for _, name := range slices.Sorted(maps.Keys(definitions)) {
+			secret := definitions[name]
+			if err := secrets.Check(secret.SourceType, secret.Value); err != nil {
+				node := discovery.NodeAt(document, append(path, "secrets", name)...)
+				problems = append(problems, discovery.ProblemAt(image.DefinitionFilePath, node, "secret "+name+": "+err.Error()))
+			}
+		}
+	}
+
+	check(image.Secrets)
+	for name, tag := range image.Tags {
+		check(tag.Secrets, "tags", sequenceIndex(document, "tags", name))
+	}
+	for name, variant := range image.Variants {
+		check(variant.Secrets, "variants", sequenceIndex(document, "variants", name))
+	}
+	return problems
+}
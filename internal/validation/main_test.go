@@ -0,0 +1,124 @@
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timo-reymann/ContainerHive/internal/secrets"
+	"github.com/timo-reymann/ContainerHive/pkg/discovery"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("VALIDATE_NPM_TOKEN", "token")
+	writeFiles(t, root, map[string]string{
+		"hive.yml":                    "cache:\n  s3:\n    region: eu-central-1\n",
+		"images/base/Dockerfile":      "FROM alpine:3.21\n",
+		"images/base/image.yml":       "tags:\n  - name: \"3.21\"\nvariants:\n  - name: slim\n    tag_suffix: -slim\n",
+		"images/base/slim/Dockerfile": "FROM alpine:3.21\n",
+		"images/app/Dockerfile.gotpl": `FROM {{ resolve_base "base" "3.21-slim" }} AS build
+FROM {{ resolve_base "base" .Versions.base }}
+`,
+		"images/app/image.yml": `tags:
+  - name: "1.0"
+    versions:
+      base: "3.20"
+    secrets:
+      npm_token:
+        value: ${VALIDATE_NPM_TOKEN}
+      registry_token:
+        value: ${VALIDATE_MISSING_TOKEN}
+depends_on:
+  - base
+  - tools
+`,
+		"images/web/Dockerfile.gotpl": "FROM {{ resolve_base \"node\" \"24\" }}\nRUN {{ .Versions.node\n",
+		"images/web/image.yml":        "tags:\n  - name: latest\nbuild:\n  network: bridge\n  entitlements:\n    - network.host\n    - root\n",
+	})
+
+	project, err := discovery.DiscoverProject(t.Context(), root)
+	if err != nil {
+		t.Fatalf("unexpected discovery error: %v", err)
+	}
+	secrets.SetProjectRoot(project.RootDir)
+
+	problems, err := Validate(project, project)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, problem := range problems {
+		got = append(got, strings.TrimPrefix(problem.Error(), root+string(os.PathSeparator)))
+	}
+	expected := []string{
+		`hive.yml:2:3: schema: cache: required: missing properties: ["type"]`,
+		`hive.yml:3:5: schema: cache.s3: required: missing properties: ["bucket"]`,
+		"images/app/Dockerfile.gotpl:2: base image base has no tag 3.20",
+		`images/app/image.yml:9:9: secret registry_token: environment variable "VALIDATE_MISSING_TOKEN" not found`,
+		"images/app/image.yml:12:5: depends_on refers to image tools, which does not exist in the project",
+		"images/web/Dockerfile.gotpl:3: unclosed action started at " + filepath.Join(root, "images/web/Dockerfile.gotpl") + ":2",
+		"images/web/image.yml:4:12: schema: build.network: enum: bridge does not equal any of: [default none host]",
+		"images/web/image.yml:7:7: schema: build.entitlements[1]: enum: root does not equal any of: [network.host security.insecure]",
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Validate() mismatch (-expected +got):\n%s", diff)
+	}
+
+	selected := *project
+	selected.ImagesByName = map[string][]*model.Image{"app": project.ImagesByName["app"]}
+	selected.ImagesByIdentifier = map[string]*model.Image{}
+	for _, image := range selected.ImagesByName["app"] {
+		selected.ImagesByIdentifier[image.Identifier] = image
+	}
+	problems, err = Validate(project, &selected)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got = nil
+	for _, problem := range problems {
+		got = append(got, strings.TrimPrefix(problem.Error(), root+string(os.PathSeparator)))
+	}
+	if diff := cmp.Diff(expected[:5], got); diff != "" {
+		t.Errorf("Validate() of selection mismatch (-expected +got):\n%s", diff)
+	}
+}
+
+func TestValidate_TestdataProjects(t *testing.T) {
+	t.Setenv("API_KEY", "key")
+	for _, name := range []string{"simple-project", "multi-variant-project", "dependency-template-project", "ignore-project"} {
+		t.Run(name, func(t *testing.T) {
+			project, err := discovery.DiscoverProject(t.Context(), filepath.Join("../../pkg/testdata", name))
+			if err != nil {
+				t.Fatalf("unexpected discovery error: %v", err)
+			}
+			secrets.SetProjectRoot(project.RootDir)
+
+			problems, err := Validate(project, project)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(problems) > 0 {
+				t.Errorf("expected project to be valid, got %v", problems)
+			}
+		})
+	}
+}
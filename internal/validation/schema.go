@@ -0,0 +1,188 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/timo-reymann/ContainerHive/pkg/discovery"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+	"github.com/timo-reymann/ContainerHive/schemas"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaPathPattern matches the schema locations the validator prefixes its errors with
+var schemaPathPattern = regexp.MustCompile(`^(?:validating (\S+): )+`)
+
+func parseSchema(raw []byte) (*jsonschema.Schema, error) {
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, errors.Join(errors.New("failed to parse schema"), err)
+	}
+	if _, err := schema.Resolve(nil); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// schemaViolation is a violation of the schema by a value of the validated file
+type schemaViolation struct {
+	// keys of the value in the file, strings for properties and ints for list items
+	keys    []any
+	message string
+}
+
+// validateSchema validates the YAML file against the JSON schema, reporting all violations
+func validateSchema(schema *jsonschema.Schema, path string) discovery.Problems {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return discovery.Problems{{File: path, Message: err.Error()}}
+	}
+
+	var instance any
+	if err := yaml.Unmarshal(content, &instance); err != nil {
+		return discovery.Problems{{File: path, Message: err.Error()}}
+	}
+	if instance == nil {
+		instance = map[string]any{}
+	}
+
+	// convert to JSON types, e.g. YAML integers to float64
+	converted, err := json.Marshal(instance)
+	if err != nil {
+		return discovery.Problems{{File: path, Message: "failed to convert to JSON: " + err.Error()}}
+	}
+	if err := json.Unmarshal(converted, &instance); err != nil {
+		return discovery.Problems{{File: path, Message: "failed to convert to JSON: " + err.Error()}}
+	}
+
+	violations, err := collectViolations(schema, instance, nil)
+	if err != nil {
+		return discovery.Problems{{File: path, Message: "failed to validate against schema: " + err.Error()}}
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return discovery.Problems{{File: path, Message: err.Error()}}
+	}
+	var problems discovery.Problems
+	for _, violation := range violations {
+		problems = append(problems, schemaProblem(path, &document, violation))
+	}
+	return problems
+}
+
+// collectViolations validates the instance one level at a time and descends into properties and list items itself,
+// as the validator stops at the first violation
+func collectViolations(schema *jsonschema.Schema, instance any, keys []any) ([]schemaViolation, error) {
+	// the children are replaced by schemas accepting everything, so only this level is checked
+	local := *schema
+	local.Items = nil
+	local.Properties = make(map[string]*jsonschema.Schema, len(schema.Properties))
+	for name := range schema.Properties {
+		local.Properties[name] = &jsonschema.Schema{}
+	}
+	additional := schema.AdditionalProperties
+	if forbidsAll(additional) {
+		local.AdditionalProperties = &jsonschema.Schema{Not: &jsonschema.Schema{}}
+	} else {
+		local.AdditionalProperties = nil
+	}
+
+	resolved, err := local.Resolve(nil)
+	if err != nil {
+		return nil, err
+	}
+	var violations []schemaViolation
+	if err := resolved.Validate(instance); err != nil {
+		violations = append(violations, schemaViolation{keys: keys, message: schemaPathPattern.ReplaceAllString(err.Error(), "")})
+	}
+
+	descend := func(schema *jsonschema.Schema, instance any, key any) error {
+		found, err := collectViolations(schema, instance, append(slices.Clone(keys), key))
+		violations = append(violations, found...)
+		return err
+	}
+	switch value := instance.(type) {
+	case map[string]any:
+		for _, name := range slices.Sorted(maps.Keys(value)) {
+			property, ok := schema.Properties[name]
+			if !ok {
+				if additional == nil || forbidsAll(additional) {
+					continue
+				}
+				property = additional
+			}
+			if err := descend(property, value[name], name); err != nil {
+				return nil, err
+			}
+		}
+	case []any:
+		if schema.Items == nil {
+			break
+		}
+		for idx, item := range value {
+			if err := descend(schema.Items, item, idx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return violations, nil
+}
+
+// forbidsAll reports whether the schema is false, which is how the schemas forbid additional properties
+func forbidsAll(schema *jsonschema.Schema) bool {
+	return schema != nil && schema.Not != nil && reflect.ValueOf(*schema.Not).IsZero()
+}
+
+// schemaProblem converts a violation to a problem at the location of the value,
+// e.g. build.entitlements[1]: enum: ... for the second entry of the entitlements list
+func schemaProblem(path string, document *yaml.Node, violation schemaViolation) *discovery.Problem {
+	if len(violation.keys) == 0 {
+		return &discovery.Problem{File: path, Message: "schema: " + violation.message}
+	}
+
+	var property strings.Builder
+	for _, key := range violation.keys {
+		switch key := key.(type) {
+		case string:
+			if property.Len() > 0 {
+				property.WriteString(".")
+			}
+			property.WriteString(key)
+		case int:
+			property.WriteString("[" + strconv.Itoa(key) + "]")
+		}
+	}
+
+	problem := &discovery.Problem{File: path, Message: "schema: " + property.String() + ": " + violation.message}
+	if node := discovery.NodeAt(document, violation.keys...); node != nil {
+		problem.Line, problem.Column = node.Line, node.Column
+	}
+	return problem
+}
+
+// validateSchemas validates the project config and the image definitions against their schemas
+func validateSchemas(configFilePath string, project *model.ContainerHiveProject) (discovery.Problems, error) {
+	projectSchema, err := parseSchema(schemas.Project)
+	if err != nil {
+		return nil, errors.Join(errors.New("invalid project schema"), err)
+	}
+	imageSchema, err := parseSchema(schemas.Image)
+	if err != nil {
+		return nil, errors.Join(errors.New("invalid image schema"), err)
+	}
+
+	problems := validateSchema(projectSchema, configFilePath)
+	for _, image := range project.ImagesByIdentifier {
+		problems = append(problems, validateSchema(imageSchema, image.DefinitionFilePath)...)
+	}
+	return problems, nil
+}
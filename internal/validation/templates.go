@@ -0,0 +1,148 @@
+package validation
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/timo-reymann/ContainerHive/internal/buildconfig_resolver"
+	"github.com/timo-reymann/ContainerHive/internal/dependency"
+	"github.com/timo-reymann/ContainerHive/internal/file_resolver"
+	"github.com/timo-reymann/ContainerHive/internal/file_resolver/templating"
+	"github.com/timo-reymann/ContainerHive/pkg/discovery"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+// templateErrorPattern matches the location text/template prefixes parse and execution errors with
+var templateErrorPattern = regexp.MustCompile(`^template: .+?:(\d+):(?:(\d+):)? (.*)$`)
+
+// projectTags returns the tags that can be referenced with resolve_base per image name, including the variant tags
+func projectTags(project *model.ContainerHiveProject) map[string]map[string]bool {
+	tags := make(map[string]map[string]bool)
+	for name, images := range project.ImagesByName {
+		tags[name] = make(map[string]bool)
+		for _, image := range images {
+			for tag := range image.Tags {
+				tags[name][tag] = true
+				for _, variant := range image.Variants {
+					tags[name][tag+variant.TagSuffix] = true
+				}
+			}
+		}
+	}
+	return tags
+}
+
+// templateChecker renders the templates of all tags and variants like the build does and checks the results
+type templateChecker struct {
+	tags     map[string]map[string]bool
+	problems discovery.Problems
+	reported map[string]bool
+}
+
+func (c *templateChecker) report(problem *discovery.Problem) {
+	// templates are rendered once per tag, so the same problem is usually found multiple times
+	if key := problem.Error(); !c.reported[key] {
+		c.reported[key] = true
+		c.problems = append(c.problems, problem)
+	}
+}
+
+// render renders the template and reports errors, the content is nil if rendering failed
+func (c *templateChecker) render(tmplCtx *templating.TemplateContext, path string) []byte {
+	if path == "" {
+		return nil
+	}
+
+	rendered, err := file_resolver.RenderFile(tmplCtx, path)
+	if err != nil {
+		problem := &discovery.Problem{File: path, Message: err.Error()}
+		if match := templateErrorPattern.FindStringSubmatch(err.Error()); match != nil {
+			problem.Line, _ = strconv.Atoi(match[1])
+			problem.Column, _ = strconv.Atoi(match[2])
+			problem.Message = match[3]
+		}
+		c.report(problem)
+		return nil
+	}
+	return rendered
+}
+
+// checkDockerfile renders the Dockerfile and verifies that the referenced project images and tags exist
+func (c *templateChecker) checkDockerfile(tmplCtx *templating.TemplateContext, path string) {
+	rendered := c.render(tmplCtx, path)
+	for _, ref := range dependency.ScanHiveRefs(rendered) {
+		tags, imageExists := c.tags[ref.ImageName]
+		switch {
+		case !imageExists:
+			c.report(hiveRefProblem(path, rendered, ref, fmt.Sprintf("base image %s does not exist in the project", ref.ImageName)))
+		case !tags[ref.Tag]:
+			c.report(hiveRefProblem(path, rendered, ref, fmt.Sprintf("base image %s has no tag %s", ref.ImageName, ref.Tag)))
+		}
+	}
+}
+
+// hiveRefProblem locates the reference in the Dockerfile source. Templates are located by the rendered line if
+// rendering kept the line count, otherwise by the first resolve_base call for the image.
+func hiveRefProblem(path string, rendered []byte, ref dependency.HiveRef, message string) *discovery.Problem {
+	problem := &discovery.Problem{File: path, Message: message}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return problem
+	}
+
+	sourceLines := strings.Split(string(content), "\n")
+	renderedLines := strings.Split(string(rendered), "\n")
+	if len(sourceLines) == len(renderedLines) {
+		reference := dependency.HivePrefix + ref.ImageName + ":" + ref.Tag
+		for idx, line := range renderedLines {
+			if strings.Contains(line, reference) {
+				problem.Line = idx + 1
+				return problem
+			}
+		}
+	}
+
+	quotedName := strconv.Quote(ref.ImageName)
+	for idx, line := range sourceLines {
+		if strings.Contains(line, "resolve_base") && strings.Contains(line, quotedName) {
+			problem.Line = idx + 1
+			break
+		}
+	}
+	return problem
+}
+
+// checkTemplates renders the Dockerfiles and test configs of the selected tags and variants,
+// base images are looked up in the whole project
+func checkTemplates(project, selected *model.ContainerHiveProject) discovery.Problems {
+	checker := &templateChecker{
+		tags:     projectTags(project),
+		reported: make(map[string]bool),
+	}
+
+	for _, image := range selected.ImagesByIdentifier {
+		for _, tag := range image.Tags {
+			tmplCtx := newTemplateContext(image, buildconfig_resolver.ForTag(image, tag))
+			checker.checkDockerfile(tmplCtx, image.BuildEntryPointPath)
+			checker.render(tmplCtx, image.TestConfigFilePath)
+
+			for _, variant := range image.Variants {
+				tmplCtx := newTemplateContext(image, buildconfig_resolver.ForTagVariant(image, variant, tag))
+				checker.checkDockerfile(tmplCtx, variant.BuildEntryPointPath)
+				checker.render(tmplCtx, variant.TestConfigFilePath)
+			}
+		}
+	}
+	return checker.problems
+}
+
+func newTemplateContext(image *model.Image, values *buildconfig_resolver.ResolvedBuildValues) *templating.TemplateContext {
+	return &templating.TemplateContext{
+		ImageName: image.Name,
+		Versions:  values.Versions,
+		BuildArgs: values.BuildArgs,
+	}
+}
@@ -6,50 +6,196 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 )
 
-type vaultResponse struct {
+// mount of a secrets engine as reported by vault
+type mount struct {
+	Path    string
+	Version int
+}
+
+type apiClient struct {
+	addr       string
+	token      string
+	namespace  string
+	httpClient *http.Client
+
+	mountsMu sync.Mutex
+	mounts   []mount
+}
+
+type mountResponse struct {
+	Data struct {
+		Path    string            `json:"path"`
+		Type    string            `json:"type"`
+		Options map[string]string `json:"options"`
+	} `json:"data"`
+}
+
+type kvV1Response struct {
+	Data map[string]json.RawMessage `json:"data"`
+}
+
+type kvV2Response struct {
 	Data struct {
-		Data map[string]string `json:"data"`
+		Data map[string]json.RawMessage `json:"data"`
 	} `json:"data"`
 }
 
-func getSecret(addr string, token string, path string, field string) (string, error) {
-	pathParts := strings.SplitN(path, "/", 2)
-	engine, secret := pathParts[0], pathParts[1]
+func (c *apiClient) request(method, path string, query url.Values, body []byte) (int, []byte, error) {
+	endpoint := fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(c.addr, "/"), strings.TrimPrefix(path, "/"))
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
 
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimSuffix(addr, "/"), engine, secret), bytes.NewBuffer([]byte{}))
+	req, err := http.NewRequest(method, endpoint, bytes.NewBuffer(body))
 	if err != nil {
-		return "", err
+		return 0, nil, err
+	}
+
+	if c.token != "" {
+		req.Header.Add("X-Vault-Token", c.token)
+	}
+	if c.namespace != "" {
+		req.Header.Add("X-Vault-Namespace", c.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
-	req.Header.Add("X-Vault-Token", token)
-	res, err := http.DefaultClient.Do(req)
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
 	if err != nil {
-		return "", err
+		return 0, nil, err
 	}
 
 	defer res.Body.Close()
 	content, err := io.ReadAll(res.Body)
 	if err != nil {
-		return "", err
+		return 0, nil, err
+	}
+
+	return res.StatusCode, content, nil
+}
+
+// detectMount looks up the secrets engine mount containing the given path.
+// Of the detected mounts the most specific one is used, so nested mounts are not shadowed by their parent.
+// When the mount can not be detected, e.g. because the token is not allowed to read mount information,
+// the first path segment is assumed to be a KV v2 mount. The assumed mount is cached like a detected one,
+// as the lookup would fail again for every further secret below it.
+func (c *apiClient) detectMount(path string) mount {
+	c.mountsMu.Lock()
+	defer c.mountsMu.Unlock()
+
+	var cached *mount
+	for idx, m := range c.mounts {
+		if strings.HasPrefix(path, m.Path) && (cached == nil || len(m.Path) > len(cached.Path)) {
+			cached = &c.mounts[idx]
+		}
+	}
+	if cached != nil {
+		return *cached
+	}
+
+	status, content, err := c.request(http.MethodGet, "sys/internal/ui/mounts/"+path, nil, nil)
+	if err == nil && status == http.StatusOK {
+		var decoded mountResponse
+		if json.Unmarshal(content, &decoded) == nil && decoded.Data.Path != "" {
+			m := mount{Path: decoded.Data.Path, Version: 1}
+			if decoded.Data.Options["version"] == "2" {
+				m.Version = 2
+			}
+			c.mounts = append(c.mounts, m)
+			return m
+		}
 	}
 
-	if res.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("invalid HTTP status: %d - %s", res.StatusCode, string(content))
+	engine, _, _ := strings.Cut(path, "/")
+	m := mount{Path: engine + "/", Version: 2}
+	c.mounts = append(c.mounts, m)
+	return m
+}
+
+// getSecret reads a field of a KV secret, a version of 0 reads the latest version.
+// Versions are only supported for KV v2 engines.
+func (c *apiClient) getSecret(path string, field string, version int) (string, error) {
+	path = strings.Trim(path, "/")
+	if !strings.Contains(path, "/") {
+		return "", fmt.Errorf("malformed vault secret path '%s', expected format '<mount>/<secret>'", path)
+	}
+
+	m := c.detectMount(path)
+
+	secret := strings.TrimPrefix(path, m.Path)
+	if secret == "" {
+		return "", fmt.Errorf("malformed vault secret path '%s', secret name cannot be empty", path)
 	}
 
-	var decoded vaultResponse
-	err = json.Unmarshal(content, &decoded)
+	var requestPath string
+	var query url.Values
+	switch m.Version {
+	case 2:
+		requestPath = m.Path + "data/" + secret
+		if version > 0 {
+			query = url.Values{"version": []string{strconv.Itoa(version)}}
+		}
+	default:
+		if version > 0 {
+			return "", fmt.Errorf("secret version requested for path '%s', but mount '%s' is not a KV v2 engine", path, m.Path)
+		}
+		requestPath = m.Path + secret
+	}
+
+	status, content, err := c.request(http.MethodGet, requestPath, query, nil)
 	if err != nil {
 		return "", err
 	}
 
-	val, ok := decoded.Data.Data[field]
+	if status != http.StatusOK {
+		return "", fmt.Errorf("invalid HTTP status: %d - %s", status, string(content))
+	}
+
+	var data map[string]json.RawMessage
+	if m.Version == 2 {
+		var decoded kvV2Response
+		if err := json.Unmarshal(content, &decoded); err != nil {
+			return "", err
+		}
+		data = decoded.Data.Data
+	} else {
+		var decoded kvV1Response
+		if err := json.Unmarshal(content, &decoded); err != nil {
+			return "", err
+		}
+		data = decoded.Data
+	}
+
+	val, ok := data[field]
 	if !ok {
 		return "", fmt.Errorf("no field '%s' in secret for path '%s'", field, path)
 	}
 
-	return val, nil
+	return decodeFieldValue(val)
+}
+
+// decodeFieldValue returns strings as-is and all other JSON values in their compact JSON representation
+func decodeFieldValue(raw json.RawMessage) (string, error) {
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str, nil
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, raw); err != nil {
+		return "", err
+	}
+	return compacted.String(), nil
 }
@@ -3,6 +3,7 @@ package vault
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -10,7 +11,7 @@ func TestGetSecret(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		writer.Write([]byte(`{ "data": {"data": { "password": "password-val" }} }`))
 	}))
-	secret, err := getSecret(srv.URL, "token", "path/to/secret", "password")
+	secret, err := (&apiClient{addr: srv.URL, token: "token"}).getSecret("path/to/secret", "password", 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -58,7 +59,7 @@ func TestGetSecret_Success(t *testing.T) {
 			}))
 			defer srv.Close()
 
-			secret, err := getSecret(srv.URL, "test-token", tt.path, tt.field)
+			secret, err := (&apiClient{addr: srv.URL, token: "test-token"}).getSecret(tt.path, tt.field, 0)
 			if err != nil {
 				t.Fatalf("getSecret() error = %v, want nil", err)
 			}
@@ -135,7 +136,7 @@ func TestGetSecret_ErrorCases(t *testing.T) {
 			}))
 			defer srv.Close()
 
-			secret, err := getSecret(srv.URL, "test-token", tt.path, tt.field)
+			secret, err := (&apiClient{addr: srv.URL, token: "test-token"}).getSecret(tt.path, tt.field, 0)
 
 			if tt.expectError {
 				if err == nil {
@@ -161,22 +162,23 @@ func TestGetSecret_RequestValidation(t *testing.T) {
 		name          string
 		path          string
 		field         string
-		expectPanic   bool
 		expectError   bool
 		errorContains string
 		useMockServer bool
 	}{
 		{
-			name:        "empty path causes panic",
-			path:        "",
-			field:       "password",
-			expectPanic: true,
+			name:          "empty path",
+			path:          "",
+			field:         "password",
+			expectError:   true,
+			errorContains: "malformed vault secret path ''",
 		},
 		{
-			name:        "path without slash causes panic",
-			path:        "single",
-			field:       "password",
-			expectPanic: true,
+			name:          "path without slash",
+			path:          "single",
+			field:         "password",
+			expectError:   true,
+			errorContains: "malformed vault secret path 'single'",
 		},
 		{
 			name:          "empty field",
@@ -190,15 +192,6 @@ func TestGetSecret_RequestValidation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.expectPanic {
-				// Test that this causes a panic
-				defer func() {
-					if r := recover(); r == nil {
-						t.Errorf("getSecret() did not panic as expected")
-					}
-				}()
-			}
-
 			var secret string
 			var err error
 
@@ -209,15 +202,13 @@ func TestGetSecret_RequestValidation(t *testing.T) {
 				}))
 				defer srv.Close()
 
-				secret, err = getSecret(srv.URL, "test-token", tt.path, tt.field)
+				secret, err = (&apiClient{addr: srv.URL, token: "test-token"}).getSecret(tt.path, tt.field, 0)
 			} else {
 				// This will fail before making HTTP request due to path parsing
-				secret, err = getSecret("http://example.com", "test-token", tt.path, tt.field)
+				secret, err = (&apiClient{addr: "http://example.com", token: "test-token"}).getSecret(tt.path, tt.field, 0)
 			}
 
-			if tt.expectPanic {
-				t.Errorf("getSecret() should have panicked but completed")
-			} else if tt.expectError {
+			if tt.expectError {
 				if err == nil {
 					t.Errorf("getSecret() error = nil, want non-nil")
 				} else if tt.errorContains != "" && !containsError(err, tt.errorContains) {
@@ -229,7 +220,7 @@ func TestGetSecret_RequestValidation(t *testing.T) {
 				}
 			}
 
-			if !tt.expectPanic && !tt.expectError && secret != "" {
+			if !tt.expectError && secret != "" {
 				t.Errorf("getSecret() = %v, want empty string", secret)
 			}
 		})
@@ -245,3 +236,191 @@ func containsError(err error, substring string) bool {
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || containsString(s[1:], substr)))
 }
+
+func TestGetSecret_MountDetection(t *testing.T) {
+	tests := []struct {
+		name          string
+		mounts        string
+		path          string
+		version       int
+		expectedPath  string
+		expectedQuery string
+		responseBody  string
+		expectedValue string
+	}{
+		{
+			name:          "kv v2 mount",
+			mounts:        `{"data": {"path": "secret/", "type": "kv", "options": {"version": "2"}}}`,
+			path:          "secret/myapp",
+			expectedPath:  "/v1/secret/data/myapp",
+			responseBody:  `{"data": {"data": {"password": "v2-value"}}}`,
+			expectedValue: "v2-value",
+		},
+		{
+			name:          "nested kv v2 mount",
+			mounts:        `{"data": {"path": "kv/team/", "type": "kv", "options": {"version": "2"}}}`,
+			path:          "kv/team/x",
+			expectedPath:  "/v1/kv/team/data/x",
+			responseBody:  `{"data": {"data": {"password": "nested-value"}}}`,
+			expectedValue: "nested-value",
+		},
+		{
+			name:          "pinned version",
+			mounts:        `{"data": {"path": "secret/", "type": "kv", "options": {"version": "2"}}}`,
+			path:          "secret/myapp",
+			version:       3,
+			expectedPath:  "/v1/secret/data/myapp",
+			expectedQuery: "version=3",
+			responseBody:  `{"data": {"data": {"password": "v3-value"}}}`,
+			expectedValue: "v3-value",
+		},
+		{
+			name:          "kv v1 mount",
+			mounts:        `{"data": {"path": "legacy/", "type": "kv", "options": {"version": "1"}}}`,
+			path:          "legacy/team/myapp",
+			expectedPath:  "/v1/legacy/team/myapp",
+			responseBody:  `{"data": {"password": "v1-value"}}`,
+			expectedValue: "v1-value",
+		},
+		{
+			name:          "kv v1 mount without options",
+			mounts:        `{"data": {"path": "generic/", "type": "generic", "options": null}}`,
+			path:          "generic/myapp",
+			expectedPath:  "/v1/generic/myapp",
+			responseBody:  `{"data": {"password": "generic-value"}}`,
+			expectedValue: "generic-value",
+		},
+		{
+			name:          "non-string field value",
+			mounts:        `{"data": {"path": "secret/", "type": "kv", "options": {"version": "2"}}}`,
+			path:          "secret/myapp",
+			expectedPath:  "/v1/secret/data/myapp",
+			responseBody:  `{"data": {"data": {"password": {"user": "admin", "port": 5432}}}}`,
+			expectedValue: `{"user":"admin","port":5432}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				if request.URL.Path == "/v1/sys/internal/ui/mounts/"+tt.path {
+					writer.Write([]byte(tt.mounts))
+					return
+				}
+				if request.URL.Path != tt.expectedPath || request.URL.RawQuery != tt.expectedQuery {
+					writer.WriteHeader(http.StatusNotFound)
+					writer.Write([]byte(`{"errors": ["unexpected request ` + request.URL.String() + `"]}`))
+					return
+				}
+				writer.Write([]byte(tt.responseBody))
+			}))
+			defer srv.Close()
+
+			secret, err := (&apiClient{addr: srv.URL, token: "test-token"}).getSecret(tt.path, "password", tt.version)
+			if err != nil {
+				t.Fatalf("getSecret() error = %v, want nil", err)
+			}
+
+			if secret != tt.expectedValue {
+				t.Errorf("getSecret() = %v, want %v", secret, tt.expectedValue)
+			}
+		})
+	}
+}
+
+func TestGetSecret_VersionOnKVv1(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(`{"data": {"path": "legacy/", "type": "kv", "options": {"version": "1"}}}`))
+	}))
+	defer srv.Close()
+
+	_, err := (&apiClient{addr: srv.URL, token: "test-token"}).getSecret("legacy/myapp", "password", 2)
+	if err == nil || !containsError(err, "is not a KV v2 engine") {
+		t.Errorf("getSecret() error = %v, want error containing %q", err, "is not a KV v2 engine")
+	}
+}
+
+func TestGetSecret_MountDetectionCached(t *testing.T) {
+	var mountLookups int
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if strings.HasPrefix(request.URL.Path, "/v1/sys/internal/ui/mounts/") {
+			mountLookups++
+			writer.Write([]byte(`{"data": {"path": "secret/", "type": "kv", "options": {"version": "2"}}}`))
+			return
+		}
+		writer.Write([]byte(`{"data": {"data": {"password": "value"}}}`))
+	}))
+	defer srv.Close()
+
+	client := &apiClient{addr: srv.URL, token: "test-token"}
+	for _, path := range []string{"secret/a", "secret/b", "secret/c"} {
+		if _, err := client.getSecret(path, "password", 0); err != nil {
+			t.Fatalf("getSecret() error = %v, want nil", err)
+		}
+	}
+
+	if mountLookups != 1 {
+		t.Errorf("expected one mount lookup, got %d", mountLookups)
+	}
+}
+
+func TestGetSecret_MountFallbackCached(t *testing.T) {
+	var mountLookups int
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if strings.HasPrefix(request.URL.Path, "/v1/sys/internal/ui/mounts/") {
+			mountLookups++
+			writer.WriteHeader(http.StatusForbidden)
+			writer.Write([]byte(`{"errors": ["permission denied"]}`))
+			return
+		}
+		writer.Write([]byte(`{"data": {"data": {"password": "value"}}}`))
+	}))
+	defer srv.Close()
+
+	client := &apiClient{addr: srv.URL, token: "test-token"}
+	for _, path := range []string{"secret/a", "secret/b", "secret/c"} {
+		if _, err := client.getSecret(path, "password", 0); err != nil {
+			t.Fatalf("getSecret() error = %v, want nil", err)
+		}
+	}
+
+	if mountLookups != 1 {
+		t.Errorf("expected one mount lookup, got %d", mountLookups)
+	}
+}
+
+func TestGetSecret_Namespace(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("X-Vault-Namespace") != "team-a" {
+			writer.WriteHeader(http.StatusForbidden)
+			writer.Write([]byte(`{"errors": ["missing namespace"]}`))
+			return
+		}
+		writer.Write([]byte(`{"data": {"data": {"password": "namespaced-value"}}}`))
+	}))
+	defer srv.Close()
+
+	secret, err := (&apiClient{addr: srv.URL, token: "test-token", namespace: "team-a"}).getSecret("secret/myapp", "password", 0)
+	if err != nil {
+		t.Fatalf("getSecret() error = %v, want nil", err)
+	}
+
+	if secret != "namespaced-value" {
+		t.Errorf("getSecret() = %v, want %v", secret, "namespaced-value")
+	}
+}
+
+func TestDetectMount_PrefersMostSpecificMount(t *testing.T) {
+	mounts := []mount{{Path: "secret/team/", Version: 1}, {Path: "secret/", Version: 2}}
+
+	for _, order := range [][]mount{mounts, {mounts[1], mounts[0]}} {
+		client := &apiClient{mounts: order}
+
+		if got := client.detectMount("secret/team/app"); got != mounts[0] {
+			t.Errorf("detectMount(secret/team/app) = %v, want %v", got, mounts[0])
+		}
+		if got := client.detectMount("secret/app"); got != mounts[1] {
+			t.Errorf("detectMount(secret/app) = %v, want %v", got, mounts[1])
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package vault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	AuthMethodToken      = "token"
+	AuthMethodAppRole    = "approle"
+	AuthMethodJWT        = "jwt"
+	AuthMethodKubernetes = "kubernetes"
+)
+
+const defaultJwtEnv = "VAULT_JWT"
+
+const defaultKubernetesTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// AuthConfig describes how to authenticate against vault
+type AuthConfig struct {
+	// Method to authenticate with, defaults to AuthMethodToken
+	Method string
+	// Mount path of the auth method, defaults to the method name
+	Mount string
+	// Role for jwt and kubernetes auth
+	Role string
+	// RoleId for approle auth
+	RoleId string
+	// JwtEnv is the environment variable containing the JWT for jwt auth
+	JwtEnv string
+	// TokenFile containing the JWT for jwt or kubernetes auth
+	TokenFile string
+}
+
+type loginResponse struct {
+	Auth struct {
+		ClientToken string `json:"client_token"`
+	} `json:"auth"`
+}
+
+// withEnvOverrides returns a copy of the config with values set via VAULT_AUTH_METHOD,
+// VAULT_AUTH_MOUNT, VAULT_AUTH_ROLE and VAULT_ROLE_ID taking precedence
+func (c AuthConfig) withEnvOverrides() AuthConfig {
+	overrides := map[string]*string{
+		"VAULT_AUTH_METHOD": &c.Method,
+		"VAULT_AUTH_MOUNT":  &c.Mount,
+		"VAULT_AUTH_ROLE":   &c.Role,
+		"VAULT_ROLE_ID":     &c.RoleId,
+	}
+	for env, target := range overrides {
+		if val := os.Getenv(env); val != "" {
+			*target = val
+		}
+	}
+
+	if c.Method == "" {
+		c.Method = AuthMethodToken
+	}
+	if c.Mount == "" {
+		c.Mount = c.Method
+	}
+	return c
+}
+
+func (c AuthConfig) loginPayload() (map[string]string, error) {
+	switch c.Method {
+	case AuthMethodAppRole:
+		if c.RoleId == "" {
+			return nil, errors.New("approle auth requires a role id, set VAULT_ROLE_ID or configure role_id")
+		}
+		secretId := os.Getenv("VAULT_SECRET_ID")
+		if secretId == "" {
+			return nil, errors.New("approle auth requires environment variable VAULT_SECRET_ID to be set")
+		}
+		return map[string]string{"role_id": c.RoleId, "secret_id": secretId}, nil
+	case AuthMethodJWT:
+		if c.Role == "" {
+			return nil, errors.New("jwt auth requires a role, set VAULT_AUTH_ROLE or configure role")
+		}
+		jwt, err := c.lookupJwt()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"role": c.Role, "jwt": jwt}, nil
+	case AuthMethodKubernetes:
+		if c.Role == "" {
+			return nil, errors.New("kubernetes auth requires a role, set VAULT_AUTH_ROLE or configure role")
+		}
+		tokenFile := c.TokenFile
+		if tokenFile == "" {
+			tokenFile = defaultKubernetesTokenFile
+		}
+		jwt, err := readTokenFile(tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"role": c.Role, "jwt": jwt}, nil
+	default:
+		return nil, fmt.Errorf("unsupported vault auth method %q", c.Method)
+	}
+}
+
+func (c AuthConfig) lookupJwt() (string, error) {
+	if c.TokenFile != "" {
+		return readTokenFile(c.TokenFile)
+	}
+
+	jwtEnv := c.JwtEnv
+	if jwtEnv == "" {
+		jwtEnv = defaultJwtEnv
+	}
+	jwt := os.Getenv(jwtEnv)
+	if jwt == "" {
+		return "", fmt.Errorf("jwt auth requires environment variable %s to contain the JWT", jwtEnv)
+	}
+	return jwt, nil
+}
+
+func readTokenFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("failed to read token file %s", path), err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// login authenticates using the configured auth method and returns the client token
+func login(client *apiClient, c AuthConfig) (string, error) {
+	payload, err := c.loginPayload()
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	status, content, err := client.request(http.MethodPost, "auth/"+strings.Trim(c.Mount, "/")+"/login", nil, body)
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("vault login using %s auth failed", c.Method), err)
+	}
+
+	if status != http.StatusOK {
+		return "", fmt.Errorf("vault login using %s auth failed with HTTP status: %d - %s", c.Method, status, string(content))
+	}
+
+	var decoded loginResponse
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		return "", err
+	}
+
+	if decoded.Auth.ClientToken == "" {
+		return "", fmt.Errorf("vault login using %s auth returned no client token", c.Method)
+	}
+
+	return decoded.Auth.ClientToken, nil
+}
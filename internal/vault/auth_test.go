@@ -0,0 +1,177 @@
+package vault
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newLoginServer returns a vault stand-in accepting logins on the given mount and serving the
+// secret field "password" for the issued token. The returned counter tracks the number of logins.
+func newLoginServer(t *testing.T, mount string, expectedPayload map[string]string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var logins atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch {
+		case request.Method == http.MethodPost && request.URL.Path == "/v1/auth/"+mount+"/login":
+			var payload map[string]string
+			if err := json.NewDecoder(request.Body).Decode(&payload); err != nil {
+				writer.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			for k, v := range expectedPayload {
+				if payload[k] != v {
+					writer.WriteHeader(http.StatusBadRequest)
+					writer.Write([]byte(`{"errors": ["invalid ` + k + `"]}`))
+					return
+				}
+			}
+			logins.Add(1)
+			writer.Write([]byte(`{"auth": {"client_token": "issued-token"}}`))
+		case request.Header.Get("X-Vault-Token") == "issued-token":
+			writer.Write([]byte(`{ "data": {"data": { "password": "secret-password" }} }`))
+		default:
+			writer.WriteHeader(http.StatusForbidden)
+			writer.Write([]byte(`{"errors": ["permission denied"]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { Configure(Config{}) })
+
+	return srv, &logins
+}
+
+func TestGetSecretWithDefaultConfiguration_AppRole(t *testing.T) {
+	srv, logins := newLoginServer(t, "approle", map[string]string{"role_id": "my-role", "secret_id": "my-secret-id"})
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_SECRET_ID", "my-secret-id")
+
+	Configure(Config{Auth: AuthConfig{Method: AuthMethodAppRole, RoleId: "my-role"}})
+
+	for i := 0; i < 3; i++ {
+		secret, err := GetSecretWithDefaultConfiguration("secret/myapp", "password")
+		if err != nil {
+			t.Fatalf("GetSecretWithDefaultConfiguration() error = %v, want nil", err)
+		}
+		if secret != "secret-password" {
+			t.Errorf("GetSecretWithDefaultConfiguration() = %v, want %v", secret, "secret-password")
+		}
+	}
+
+	if got := logins.Load(); got != 1 {
+		t.Errorf("expected exactly one login, got %d", got)
+	}
+}
+
+func TestGetSecretWithDefaultConfiguration_JWTFromEnv(t *testing.T) {
+	srv, _ := newLoginServer(t, "gitlab", map[string]string{"role": "ci", "jwt": "ci-job-jwt"})
+	t.Setenv("CI_JOB_JWT", "ci-job-jwt")
+
+	Configure(Config{
+		Address: srv.URL,
+		Auth:    AuthConfig{Method: AuthMethodJWT, Mount: "gitlab", Role: "ci", JwtEnv: "CI_JOB_JWT"},
+	})
+
+	secret, err := GetSecretWithDefaultConfiguration("secret/myapp", "password")
+	if err != nil {
+		t.Fatalf("GetSecretWithDefaultConfiguration() error = %v, want nil", err)
+	}
+	if secret != "secret-password" {
+		t.Errorf("GetSecretWithDefaultConfiguration() = %v, want %v", secret, "secret-password")
+	}
+}
+
+func TestGetSecretWithDefaultConfiguration_Kubernetes(t *testing.T) {
+	srv, _ := newLoginServer(t, "kubernetes", map[string]string{"role": "builder", "jwt": "sa-token"})
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("sa-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_AUTH_METHOD", AuthMethodKubernetes)
+	t.Setenv("VAULT_AUTH_ROLE", "builder")
+
+	Configure(Config{Auth: AuthConfig{TokenFile: tokenFile}})
+
+	secret, err := GetSecretWithDefaultConfiguration("secret/myapp", "password")
+	if err != nil {
+		t.Fatalf("GetSecretWithDefaultConfiguration() error = %v, want nil", err)
+	}
+	if secret != "secret-password" {
+		t.Errorf("GetSecretWithDefaultConfiguration() = %v, want %v", secret, "secret-password")
+	}
+}
+
+func TestGetSecretWithDefaultConfiguration_AuthErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        Config
+		env           map[string]string
+		errorContains string
+	}{
+		{
+			name:          "approle without role id",
+			config:        Config{Auth: AuthConfig{Method: AuthMethodAppRole}},
+			errorContains: "requires a role id",
+		},
+		{
+			name:          "approle without secret id",
+			config:        Config{Auth: AuthConfig{Method: AuthMethodAppRole, RoleId: "my-role"}},
+			errorContains: "VAULT_SECRET_ID",
+		},
+		{
+			name:          "jwt without role",
+			config:        Config{Auth: AuthConfig{Method: AuthMethodJWT}},
+			errorContains: "jwt auth requires a role",
+		},
+		{
+			name:          "jwt without token",
+			config:        Config{Auth: AuthConfig{Method: AuthMethodJWT, Role: "ci"}},
+			errorContains: "VAULT_JWT",
+		},
+		{
+			name:          "kubernetes with missing token file",
+			config:        Config{Auth: AuthConfig{Method: AuthMethodKubernetes, Role: "builder", TokenFile: "/non/existent"}},
+			errorContains: "failed to read token file /non/existent",
+		},
+		{
+			name:          "unsupported method",
+			config:        Config{Auth: AuthConfig{Method: "ldap"}},
+			errorContains: `unsupported vault auth method "ldap"`,
+		},
+		{
+			name:          "rejected login",
+			config:        Config{Auth: AuthConfig{Method: AuthMethodAppRole, RoleId: "wrong-role"}},
+			env:           map[string]string{"VAULT_SECRET_ID": "my-secret-id"},
+			errorContains: "vault login using approle auth failed with HTTP status: 400",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := newLoginServer(t, "approle", map[string]string{"role_id": "my-role", "secret_id": "my-secret-id"})
+			t.Setenv("VAULT_ADDR", srv.URL)
+			t.Setenv("VAULT_SECRET_ID", "")
+			t.Setenv("VAULT_JWT", "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			Configure(tt.config)
+
+			_, err := GetSecretWithDefaultConfiguration("secret/myapp", "password")
+			if err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("expected error containing %q, got %q", tt.errorContains, err.Error())
+			}
+		})
+	}
+}
@@ -3,20 +3,124 @@ package vault
 import (
 	"errors"
 	"os"
+	"sync"
 )
 
-// GetSecretWithDefaultConfiguration using the token configured by the CLI and the
-// environment variable VAULT_ADDR containing the base URL for the vault API
-func GetSecretWithDefaultConfiguration(path string, field string) (string, error) {
-	token, err := LookupToken()
+// Config for accessing vault, usually set from the project configuration
+type Config struct {
+	// Address of the vault API, VAULT_ADDR takes precedence
+	Address string
+	// Namespace for Vault Enterprise, VAULT_NAMESPACE takes precedence
+	Namespace string
+	TLS       TLSConfig
+	Auth      AuthConfig
+}
+
+var errMissingAddress = errors.New("environment variable VAULT_ADDR not set, which is required for fetching secret")
+
+var (
+	mu     sync.Mutex
+	config Config
+	// session is reused as long as the connection settings do not change,
+	// so all secrets of a run share one login and the detected mounts
+	session    *apiClient
+	sessionTLS TLSConfig
+)
+
+// Configure sets the configuration used by GetSecretWithDefaultConfiguration
+// and drops any session from a previous login
+func Configure(c Config) {
+	mu.Lock()
+	defer mu.Unlock()
+	config = c
+	session = nil
+}
+
+func lookupEnvOrDefault(env string, fallback string) string {
+	if val := os.Getenv(env); val != "" {
+		return val
+	}
+	return fallback
+}
+
+// getSession returns the API client to use for requests.
+// Static tokens are looked up every time, tokens obtained by logging in are reused.
+func getSession() (*apiClient, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	auth := config.Auth.withEnvOverrides()
+	var token string
+	if auth.Method == AuthMethodToken {
+		var err error
+		if token, err = LookupToken(); err != nil {
+			return nil, err
+		}
+	}
+
+	addr := lookupEnvOrDefault("VAULT_ADDR", config.Address)
+	if addr == "" {
+		return nil, errMissingAddress
+	}
+
+	namespace := lookupEnvOrDefault("VAULT_NAMESPACE", config.Namespace)
+	tlsConfig, err := config.TLS.withEnvOverrides()
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	if session != nil && session.addr == addr && session.namespace == namespace && sessionTLS == tlsConfig &&
+		(auth.Method != AuthMethodToken || session.token == token) {
+		return session, nil
+	}
+
+	httpClient, err := newHttpClient(tlsConfig)
+	if err != nil {
+		return nil, errors.Join(errors.New("failed to configure vault TLS"), err)
+	}
+
+	client := &apiClient{
+		addr:       addr,
+		namespace:  namespace,
+		httpClient: httpClient,
+		token:      token,
+	}
+
+	if auth.Method != AuthMethodToken {
+		if client.token, err = login(client, auth); err != nil {
+			return nil, err
+		}
+	}
+
+	session = client
+	sessionTLS = tlsConfig
+	return client, nil
+}
+
+// CheckConfiguration verifies that the address of vault is known, without connecting to it
+func CheckConfiguration() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if lookupEnvOrDefault("VAULT_ADDR", config.Address) == "" {
+		return errMissingAddress
 	}
+	return nil
+}
 
-	vaultAddr := os.Getenv("VAULT_ADDR")
-	if vaultAddr == "" {
-		return "", errors.New("environment variable VAULT_ADDR not set, which is required for fetching secret")
+// GetSecretWithDefaultConfiguration reads the latest version of a secret field
+// using the configured auth method and the environment variable VAULT_ADDR or
+// configured address as base URL for the vault API
+func GetSecretWithDefaultConfiguration(path string, field string) (string, error) {
+	return GetSecretVersionWithDefaultConfiguration(path, field, 0)
+}
+
+// GetSecretVersionWithDefaultConfiguration works like GetSecretWithDefaultConfiguration,
+// but reads the given version of the secret, a version of 0 reads the latest version
+func GetSecretVersionWithDefaultConfiguration(path string, field string, version int) (string, error) {
+	client, err := getSession()
+	if err != nil {
+		return "", err
 	}
 
-	return getSecret(vaultAddr, token, path, field)
+	return client.getSecret(path, field, version)
 }
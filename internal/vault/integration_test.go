@@ -79,7 +79,7 @@ func TestVaultIntegration(t *testing.T) {
 	// Test basic connectivity
 	t.Run("test_connectivity", func(t *testing.T) {
 		// Test that we can get the vault status
-		_, err := getSecret(vaultAddr, "test-root-token", "sys/health", "status")
+		_, err := (&apiClient{addr: vaultAddr, token: "test-root-token"}).getSecret("sys/health", "status", 0)
 		if err != nil {
 			t.Logf("Connectivity test failed (expected for sys/health): %v", err)
 			// This is expected to fail since sys/health doesn't return data in the expected format
@@ -93,7 +93,7 @@ func TestVaultIntegration(t *testing.T) {
 		// the full secret creation/retrieval workflow
 
 		// Test that our vault client can communicate with the server
-		_, err := getSecret(vaultAddr, "test-root-token", "secret/data/test", "password")
+		_, err := (&apiClient{addr: vaultAddr, token: "test-root-token"}).getSecret("secret/data/test", "password", 0)
 		if err != nil {
 			t.Logf("Secret retrieval test failed (expected for non-existent secret): %v", err)
 			// This is expected to fail since the secret doesn't exist yet
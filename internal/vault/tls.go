@@ -0,0 +1,125 @@
+package vault
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// TLSConfig for connecting to vault, mirrors the TLS options of the Vault CLI
+type TLSConfig struct {
+	// CACert is a PEM file used to verify the server certificate
+	CACert string
+	// CAPath is a directory of PEM files used to verify the server certificate
+	CAPath string
+	// ClientCert and ClientKey are PEM files used for TLS client authentication
+	ClientCert string
+	ClientKey  string
+	// ServerName to use as SNI host
+	ServerName string
+	// SkipVerify disables verification of the server certificate
+	SkipVerify bool
+}
+
+// withEnvOverrides returns a copy of the config with values set via VAULT_CACERT, VAULT_CAPATH,
+// VAULT_CLIENT_CERT, VAULT_CLIENT_KEY, VAULT_TLS_SERVER_NAME and VAULT_SKIP_VERIFY taking precedence
+func (c TLSConfig) withEnvOverrides() (TLSConfig, error) {
+	overrides := map[string]*string{
+		"VAULT_CACERT":          &c.CACert,
+		"VAULT_CAPATH":          &c.CAPath,
+		"VAULT_CLIENT_CERT":     &c.ClientCert,
+		"VAULT_CLIENT_KEY":      &c.ClientKey,
+		"VAULT_TLS_SERVER_NAME": &c.ServerName,
+	}
+	for env, target := range overrides {
+		if val := os.Getenv(env); val != "" {
+			*target = val
+		}
+	}
+
+	if val := os.Getenv("VAULT_SKIP_VERIFY"); val != "" {
+		skip, err := strconv.ParseBool(val)
+		if err != nil {
+			return c, fmt.Errorf("invalid value for VAULT_SKIP_VERIFY: %q", val)
+		}
+		c.SkipVerify = skip
+	}
+
+	return c, nil
+}
+
+func (c TLSConfig) isDefault() bool {
+	return c == TLSConfig{}
+}
+
+func (c TLSConfig) toTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.SkipVerify,
+	}
+
+	if c.CACert != "" || c.CAPath != "" {
+		pool := x509.NewCertPool()
+		caFiles := []string{}
+		if c.CACert != "" {
+			caFiles = append(caFiles, c.CACert)
+		}
+		if c.CAPath != "" {
+			entries, err := os.ReadDir(c.CAPath)
+			if err != nil {
+				return nil, errors.Join(fmt.Errorf("failed to read vault CA path %s", c.CAPath), err)
+			}
+			for _, entry := range entries {
+				if !entry.IsDir() {
+					caFiles = append(caFiles, filepath.Join(c.CAPath, entry.Name()))
+				}
+			}
+		}
+
+		for _, caFile := range caFiles {
+			pem, err := os.ReadFile(caFile)
+			if err != nil {
+				return nil, errors.Join(fmt.Errorf("failed to read vault CA certificate %s", caFile), err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no valid PEM certificates found in vault CA certificate %s", caFile)
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.ClientCert != "" || c.ClientKey != "" {
+		if c.ClientCert == "" || c.ClientKey == "" {
+			return nil, errors.New("vault TLS client authentication requires both a client certificate and key")
+		}
+		cert, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, errors.Join(errors.New("failed to load vault client certificate"), err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// newHttpClient creates the HTTP client for the given TLS configuration,
+// returning the default client when nothing is configured
+func newHttpClient(c TLSConfig) (*http.Client, error) {
+	if c.isDefault() {
+		return http.DefaultClient, nil
+	}
+
+	tlsConfig, err := c.toTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,95 @@
+package vault
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetSecretWithDefaultConfiguration_CACert(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(`{ "data": {"data": { "password": "tls-password" }} }`))
+	}))
+	defer srv.Close()
+	t.Cleanup(func() { Configure(Config{}) })
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		Configure(Config{})
+		_, err := GetSecretWithDefaultConfiguration("secret/myapp", "password")
+		if err == nil || !strings.Contains(err.Error(), "certificate") {
+			t.Errorf("GetSecretWithDefaultConfiguration() error = %v, want certificate error", err)
+		}
+	})
+
+	t.Run("trusted via VAULT_CACERT", func(t *testing.T) {
+		t.Setenv("VAULT_CACERT", caFile)
+		Configure(Config{})
+		secret, err := GetSecretWithDefaultConfiguration("secret/myapp", "password")
+		if err != nil {
+			t.Fatalf("GetSecretWithDefaultConfiguration() error = %v, want nil", err)
+		}
+		if secret != "tls-password" {
+			t.Errorf("GetSecretWithDefaultConfiguration() = %v, want %v", secret, "tls-password")
+		}
+	})
+
+	t.Run("trusted via configured CA path", func(t *testing.T) {
+		Configure(Config{TLS: TLSConfig{CAPath: filepath.Dir(caFile)}})
+		secret, err := GetSecretWithDefaultConfiguration("secret/myapp", "password")
+		if err != nil {
+			t.Fatalf("GetSecretWithDefaultConfiguration() error = %v, want nil", err)
+		}
+		if secret != "tls-password" {
+			t.Errorf("GetSecretWithDefaultConfiguration() = %v, want %v", secret, "tls-password")
+		}
+	})
+
+	t.Run("verification skipped", func(t *testing.T) {
+		t.Setenv("VAULT_SKIP_VERIFY", "true")
+		Configure(Config{})
+		if _, err := GetSecretWithDefaultConfiguration("secret/myapp", "password"); err != nil {
+			t.Fatalf("GetSecretWithDefaultConfiguration() error = %v, want nil", err)
+		}
+	})
+}
+
+func TestTLSConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        TLSConfig
+		errorContains string
+	}{
+		{
+			name:          "missing CA certificate",
+			config:        TLSConfig{CACert: "/non/existent/ca.pem"},
+			errorContains: "failed to read vault CA certificate /non/existent/ca.pem",
+		},
+		{
+			name:          "client certificate without key",
+			config:        TLSConfig{ClientCert: "/some/cert.pem"},
+			errorContains: "requires both a client certificate and key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newHttpClient(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("newHttpClient() error = %v, want error containing %q", err, tt.errorContains)
+			}
+		})
+	}
+}
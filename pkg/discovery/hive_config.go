@@ -4,6 +4,8 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+
+	"github.com/timo-reymann/ContainerHive/pkg/model"
 )
 
 var hiveConfigFileNames = []string{
@@ -28,3 +30,13 @@ func getContainerHiveConfigFile(root string) (string, error) {
 
 	return "", errors.New("no ContainerHive config file found")
 }
+
+func parseHiveConfigFile(configFilePath string) (*model.HiveProjectConfig, error) {
+	var config model.HiveProjectConfig
+	// An empty config file is valid and results in the defaults
+	if _, problems := decodeYAMLFile(configFilePath, &config); len(problems) > 0 {
+		return nil, problems
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,65 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+func TestParseHiveConfigFile(t *testing.T) {
+	tests := map[string]struct {
+		content  string
+		expected *model.HiveProjectConfig
+		wantErr  bool
+	}{
+		"empty file": {
+			content:  "",
+			expected: &model.HiveProjectConfig{},
+		},
+		"vault config": {
+			content: `
+vault:
+  address: https://vault.example.com
+  auth:
+    method: approle
+    mount: ci-approle
+    role_id: my-role
+`,
+			expected: &model.HiveProjectConfig{
+				Vault: &model.VaultConfig{
+					Address: "https://vault.example.com",
+					Auth: model.VaultAuthConfig{
+						Method: "approle",
+						Mount:  "ci-approle",
+						RoleId: "my-role",
+					},
+				},
+			},
+		},
+		"unknown key": {
+			content: "unknown: true\n",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "hive.yml")
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := parseHiveConfigFile(path)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseHiveConfigFile() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("parseHiveConfigFile() mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package discovery
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
+)
+
+const defaultIgnoreFile = ".hiveignore"
+
+var defaultImageRoots = []string{"images"}
+
+// ignoreMatcher matches paths against the gitignore patterns of the project ignore file
+type ignoreMatcher struct {
+	projectRoot string
+	matcher     gitignore.Matcher
+}
+
+// loadIgnoreFile parses the ignore file relative to the project root, a missing file ignores nothing
+func loadIgnoreFile(projectRoot, ignoreFile string) (*ignoreMatcher, error) {
+	if ignoreFile == "" {
+		ignoreFile = defaultIgnoreFile
+	}
+
+	f, err := os.Open(filepath.Join(projectRoot, ignoreFile))
+	if os.IsNotExist(err) {
+		return &ignoreMatcher{projectRoot: projectRoot}, nil
+	} else if err != nil {
+		return nil, errors.Join(errors.New("failed to open ignore file "+ignoreFile), err)
+	}
+	defer f.Close()
+
+	var patterns []gitignore.Pattern
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, nil))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Join(errors.New("failed to read ignore file "+ignoreFile), err)
+	}
+
+	return &ignoreMatcher{
+		projectRoot: projectRoot,
+		matcher:     gitignore.NewMatcher(patterns),
+	}, nil
+}
+
+// ignored reports if the absolute path is excluded from discovery
+func (i *ignoreMatcher) ignored(path string, isDir bool) bool {
+	if i == nil || i.matcher == nil {
+		return false
+	}
+
+	relativePath, err := filepath.Rel(i.projectRoot, path)
+	if err != nil || relativePath == "." || strings.HasPrefix(relativePath, "..") {
+		return false
+	}
+	return i.matcher.Match(strings.Split(filepath.ToSlash(relativePath), "/"), isDir)
+}
+
+// imageRoots returns the absolute image root directories of the project
+func imageRoots(projectRoot string, config *model.HiveProjectConfig) ([]string, error) {
+	roots := config.ImageRoots
+	if len(roots) == 0 {
+		roots = defaultImageRoots
+	}
+
+	absoluteRoots := make([]string, 0, len(roots))
+	configuredRoots := make(map[string]string, len(roots))
+	for _, root := range roots {
+		if filepath.IsAbs(root) {
+			return nil, errors.New("image root " + root + " must be relative to the project root")
+		}
+		absoluteRoot := filepath.Join(projectRoot, root)
+		if relativeRoot, err := filepath.Rel(projectRoot, absoluteRoot); err != nil || strings.HasPrefix(relativeRoot, "..") {
+			return nil, errors.New("image root " + root + " must be inside the project root")
+		}
+		// the same root listed twice is only walked once
+		if _, exists := configuredRoots[absoluteRoot]; exists {
+			continue
+		}
+		configuredRoots[absoluteRoot] = root
+		absoluteRoots = append(absoluteRoots, absoluteRoot)
+	}
+
+	// nested roots would discover the images of the inner root twice
+	for _, inner := range absoluteRoots {
+		for _, outer := range absoluteRoots {
+			if inner != outer && strings.HasPrefix(inner, outer+string(filepath.Separator)) {
+				return nil, errors.New("image root " + configuredRoots[inner] + " must not be inside image root " + configuredRoots[outer])
+			}
+		}
+	}
+	return absoluteRoots, nil
+}
@@ -0,0 +1,64 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreMatcher_Ignored(t *testing.T) {
+	tests := map[string]struct {
+		patterns string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		"no ignore file": {
+			path:     "images/nginx",
+			isDir:    true,
+			expected: false,
+		},
+		"directory pattern": {
+			patterns: "images/experimental/\n",
+			path:     "images/experimental",
+			isDir:    true,
+			expected: true,
+		},
+		"directory pattern does not match files": {
+			patterns: "images/experimental/\n",
+			path:     "images/experimental",
+			expected: false,
+		},
+		"glob in any directory": {
+			patterns: "# archived images\n*.bak\n",
+			path:     "images/nginx/image.yml.bak",
+			expected: true,
+		},
+		"negated pattern": {
+			patterns: "archived-*\n!archived-keep\n",
+			path:     "shared/archived-keep",
+			isDir:    true,
+			expected: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			if tc.patterns != "" {
+				if err := os.WriteFile(filepath.Join(root, defaultIgnoreFile), []byte(tc.patterns), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			matcher, err := loadIgnoreFile(root, "")
+			if err != nil {
+				t.Fatalf("loadIgnoreFile() error = %v", err)
+			}
+
+			if got := matcher.ignored(filepath.Join(root, tc.path), tc.isDir); got != tc.expected {
+				t.Errorf("ignored(%q) = %v, expected %v", tc.path, got, tc.expected)
+			}
+		})
+	}
+}
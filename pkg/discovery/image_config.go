@@ -42,32 +42,29 @@ func getRoofsPath(imageRoot string) (string, error) {
 	return filepath.Join(imageRoot, rootFsDirName), nil
 }
 
-func parseImageConfigFile(configFilePath string) (*model.ImageDefinitionConfig, error) {
-	f, err := os.Open(configFilePath)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	d := yaml.NewDecoder(f)
-	d.KnownFields(true)
+func parseImageConfigFile(configFilePath string) (*model.ImageDefinitionConfig, *yaml.Node, Problems) {
 	var config model.ImageDefinitionConfig
-	if err := d.Decode(&config); err != nil {
-		return nil, err
+	document, problems := decodeYAMLFile(configFilePath, &config)
+	if document != nil && len(document.Content) == 0 {
+		problems = append(problems, &Problem{File: configFilePath, Message: "image config file is empty"})
 	}
-	return &config, nil
+	return &config, document, problems
 }
 
-func processImageConfig(projectRoot, configFilePath string) (*model.Image, error) {
+// processImageConfig builds the image from its config file, collecting all problems found instead of
+// stopping at the first one
+func processImageConfig(projectRoot, configFilePath string) (*model.Image, Problems) {
 	imageRoot := filepath.Dir(configFilePath)
 	relativeRoot, err := filepath.Rel(projectRoot, imageRoot)
 	if err != nil {
-		return nil, err
+		return nil, Problems{{File: configFilePath, Message: err.Error()}}
 	}
 
+	parsedImageDef, document, problems := parseImageConfigFile(configFilePath)
+
 	testConfigFilePath, err := getTestConfigFilePath(imageRoot)
 	if err != nil {
-		return nil, errors.Join(errors.New("failed to discover test config file"), err)
+		problems = append(problems, &Problem{File: imageRoot, Message: "failed to discover test config file: " + err.Error()})
 	}
 
 	isNested := strings.ContainsRune(relativeRoot, os.PathSeparator)
@@ -78,24 +75,24 @@ func processImageConfig(projectRoot, configFilePath string) (*model.Image, error
 		name = relativeRoot
 	}
 
-	parsedImageDef, err := parseImageConfigFile(configFilePath)
-	if err != nil {
-		return nil, errors.Join(errors.New("failed to parse image config file"), err)
-	}
-
 	rootFsPath, err := getRoofsPath(imageRoot)
 	if err != nil {
-		return nil, errors.Join(errors.New("failed to discover rootfs dir"), err)
+		problems = append(problems, &Problem{File: filepath.Join(imageRoot, rootFsDirName), Message: err.Error()})
 	}
 
-	indexedVariants, err := processVariants(parsedImageDef, imageRoot)
-	if err != nil {
-		return nil, err
-	}
+	indexedVariants, variantProblems := processVariants(parsedImageDef, imageRoot, configFilePath, document)
+	problems = append(problems, variantProblems...)
 
 	dockerfilePath, err := getBuildEntrypointPath(imageRoot)
 	if err != nil {
-		return nil, errors.Join(errors.New("failed to discover Dockerfile"), err)
+		problems = append(problems, &Problem{File: imageRoot, Message: "no Dockerfile found"})
+	}
+
+	tags, tagProblems := processTags(parsedImageDef, configFilePath, document)
+	problems = append(problems, tagProblems...)
+
+	if len(problems) > 0 {
+		return nil, problems
 	}
 
 	return &model.Image{
@@ -110,37 +107,69 @@ func processImageConfig(projectRoot, configFilePath string) (*model.Image, error
 		BuildArgs:           parsedImageDef.BuildArgs,
 		Secrets:             ensureSecretsInitialized(parsedImageDef.Secrets),
 		Variants:            indexedVariants,
-		Tags:                processTags(parsedImageDef),
+		Tags:                tags,
 		DependsOn:           parsedImageDef.DependsOn,
+		SSH:                 parsedImageDef.SSH,
+		Cache:               parsedImageDef.Cache,
+		Compression:         parsedImageDef.Compression,
+		SourceDateEpoch:     parsedImageDef.SourceDateEpoch,
+		Labels:              parsedImageDef.Labels,
+		Platforms:           parsedImageDef.Platforms,
+		Build:               parsedImageDef.Build,
 	}, nil
 }
 
-func processTags(imageDef *model.ImageDefinitionConfig) map[string]*model.Tag {
+func processTags(imageDef *model.ImageDefinitionConfig, configFilePath string, document *yaml.Node) (map[string]*model.Tag, Problems) {
+	var problems Problems
 	tags := make(map[string]*model.Tag)
-	for _, tag := range imageDef.Tags {
+	for idx, tag := range imageDef.Tags {
+		if tag == nil {
+			continue
+		}
+		if _, exists := tags[tag.Name]; exists {
+			problems = append(problems, ProblemAt(configFilePath, NodeAt(document, "tags", idx, "name"), "duplicate tag "+tag.Name))
+			continue
+		}
 		tags[tag.Name] = tag
 	}
-	return tags
+	return tags, problems
 }
 
-func processVariants(imageDef *model.ImageDefinitionConfig, imageRoot string) (map[string]*model.ImageVariant, error) {
+func processVariants(imageDef *model.ImageDefinitionConfig, imageRoot, configFilePath string, document *yaml.Node) (map[string]*model.ImageVariant, Problems) {
+	var problems Problems
 	indexedVariants := make(map[string]*model.ImageVariant)
-	for _, v := range imageDef.Variants {
+	variantsBySuffix := make(map[string]string)
+	for idx, v := range imageDef.Variants {
+		nameNode := NodeAt(document, "variants", idx, "name")
+		if _, exists := indexedVariants[v.Name]; exists {
+			problems = append(problems, ProblemAt(configFilePath, nameNode, "duplicate variant "+v.Name))
+			continue
+		}
+
+		// an empty suffix is allowed for variants that are published without one
+		if v.TagSuffix != "" {
+			if other, exists := variantsBySuffix[v.TagSuffix]; exists {
+				problems = append(problems, ProblemAt(configFilePath, NodeAt(document, "variants", idx, "tag_suffix"), "variant "+v.Name+" uses the tag_suffix "+v.TagSuffix+" of variant "+other))
+			} else {
+				variantsBySuffix[v.TagSuffix] = v.Name
+			}
+		}
+
 		variantRoot := filepath.Join(imageRoot, v.Name)
 
 		variantFsRoot, err := getRoofsPath(variantRoot)
 		if err != nil {
-			return nil, errors.Join(errors.New("failed to discover rootfs dir for variant "+v.Name), err)
+			problems = append(problems, &Problem{File: filepath.Join(variantRoot, rootFsDirName), Message: err.Error()})
 		}
 
 		testConfigFilePath, err := getTestConfigFilePath(variantRoot)
 		if err != nil {
-			return nil, errors.Join(errors.New("failed to discover test config file for variant "+v.Name), err)
+			problems = append(problems, &Problem{File: variantRoot, Message: "failed to discover test config file for variant " + v.Name + ": " + err.Error()})
 		}
 
 		dockerfilePath, err := file_resolver.ResolveFirstExistingFile(variantRoot, dockerfileConfigFileNames...)
 		if err != nil {
-			return nil, errors.Join(errors.New("failed to discover Dockerfile for variant "+v.Name), err)
+			problems = append(problems, ProblemAt(configFilePath, nameNode, "no Dockerfile found for variant "+v.Name+" in "+variantRoot))
 		}
 
 		variant := &model.ImageVariant{
@@ -151,10 +180,13 @@ func processVariants(imageDef *model.ImageDefinitionConfig, imageRoot string) (m
 			TagSuffix:           v.TagSuffix,
 			Versions:            v.Versions,
 			BuildArgs:           v.BuildArgs,
+			Secrets:             v.Secrets,
+			Labels:              v.Labels,
+			Build:               v.Build,
 			RootFSDir:           variantFsRoot,
 		}
 
 		indexedVariants[v.Name] = variant
 	}
-	return indexedVariants, nil
+	return indexedVariants, problems
 }
@@ -30,59 +30,113 @@ func verifyProjectRoot(root string) error {
 	return nil
 }
 
-func discoverImages(ctx context.Context, rootPath string) (map[string]*model.Image, error) {
+// foundImageConfig is an image config file together with the image root it was found in
+type foundImageConfig struct {
+	imageRoot string
+	path      string
+}
+
+// discoverImages walks all image roots and processes the image configs found. Problems of all images are collected
+// and returned together, so that a broken image does not hide the problems of the others.
+func discoverImages(ctx context.Context, imageRoots []string, ignore *ignoreMatcher) (map[string]*model.Image, error) {
 	eg, ctx := errgroup.WithContext(ctx)
 	images := map[string]*model.Image{}
-	foundImageConfigs := make(chan string)
+	var problems Problems
+	foundImageConfigs := make(chan foundImageConfig)
 	var mutex sync.Mutex
+	report := func(found ...*Problem) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		problems = append(problems, found...)
+	}
 
 	eg.Go(func() error {
-		err := filepath.WalkDir(rootPath, func(path string, d fs.DirEntry, err error) error {
-			if err := ctx.Err(); err != nil {
-				return filepath.SkipDir
-			}
-			if err != nil {
-				return err
-			}
+		defer close(foundImageConfigs)
+		for _, imageRoot := range imageRoots {
+			err := filepath.WalkDir(imageRoot, func(path string, d fs.DirEntry, err error) error {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err != nil {
+					if path == imageRoot && os.IsNotExist(err) {
+						report(&Problem{File: path, Message: "image root does not exist"})
+					} else {
+						report(&Problem{File: path, Message: err.Error()})
+					}
+					if d != nil && d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+
+				if path != imageRoot && ignore.ignored(path, d.IsDir()) {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+
+				if d.IsDir() {
+					if d.Name() == "rootfs" {
+						return filepath.SkipDir
+					}
+					return nil
+				}
 
-			if d.IsDir() {
-				if d.Name() == "rootfs" {
+				name := d.Name()
+				if slices.Contains(imageConfigFileNames, name) {
+					foundImageConfigs <- foundImageConfig{imageRoot: imageRoot, path: path}
 					return filepath.SkipDir
 				}
-				return nil
-			}
 
-			name := d.Name()
-			if slices.Contains(imageConfigFileNames, name) {
-				foundImageConfigs <- path
-				return filepath.SkipDir
+				return nil
+			})
+			if err != nil {
+				return err
 			}
-
-			return nil
-		})
-		close(foundImageConfigs)
-		return err
+		}
+		return nil
 	})
 	eg.Go(func() error {
-		for image := range foundImageConfigs {
+		for found := range foundImageConfigs {
 			eg.Go(func() error {
-				config, err := processImageConfig(rootPath, image)
-				if err != nil {
-					return err
+				config, imageProblems := processImageConfig(found.imageRoot, found.path)
+				if len(imageProblems) > 0 {
+					report(imageProblems...)
+					return nil
 				}
 
 				mutex.Lock()
+				defer mutex.Unlock()
+				if existing, ok := images[config.Identifier]; ok {
+					// identifiers are relative to their image root, so images of different roots can collide.
+					// The problem is reported for the later path, independent of the order the configs are processed in.
+					first, second := existing, config
+					if second.DefinitionFilePath < first.DefinitionFilePath {
+						first, second = second, first
+					}
+					images[config.Identifier] = first
+					problems = append(problems, &Problem{
+						File:    second.DefinitionFilePath,
+						Message: fmt.Sprintf("image %s is already defined in %s", config.Identifier, first.DefinitionFilePath),
+					})
+					return nil
+				}
 				images[config.Identifier] = config
-				mutex.Unlock()
 				return nil
 			})
-
-			go fmt.Println(image)
 		}
 		return nil
 	})
 
-	return images, eg.Wait()
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		problems.Sort()
+		return nil, problems
+	}
+	return images, nil
 }
 
 func DiscoverProject(ctx context.Context, root string) (*model.ContainerHiveProject, error) {
@@ -103,7 +157,21 @@ func DiscoverProject(ctx context.Context, root string) (*model.ContainerHiveProj
 		return nil, errors.Join(errors.New("failed to determine absolute config path"), err)
 	}
 
-	images, err := discoverImages(ctx, filepath.Join(absoluteRoot, "images"))
+	config, err := parseHiveConfigFile(absoluteConfigPath)
+	if err != nil {
+		return nil, errors.Join(errors.New("failed to parse ContainerHive config file"), err)
+	}
+
+	roots, err := imageRoots(absoluteRoot, config)
+	if err != nil {
+		return nil, errors.Join(errors.New("invalid image roots"), err)
+	}
+	ignore, err := loadIgnoreFile(absoluteRoot, config.IgnoreFile)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := discoverImages(ctx, roots, ignore)
 	if err != nil {
 		return nil, errors.Join(errors.New("failed to discover images"), err)
 	}
@@ -115,6 +183,7 @@ func DiscoverProject(ctx context.Context, root string) (*model.ContainerHiveProj
 	project := &model.ContainerHiveProject{
 		RootDir:            absoluteRoot,
 		ConfigFilePath:     absoluteConfigPath,
+		Config:             config,
 		ImagesByIdentifier: images,
 		ImagesByName:       imagesByName,
 	}
@@ -1,7 +1,11 @@
 package discovery
 
 import (
+	"maps"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -29,6 +33,7 @@ func TestDiscoverProject(t *testing.T) {
 			expected: &model.ContainerHiveProject{
 				RootDir:        mustAbs(t, "../testdata/simple-project"),
 				ConfigFilePath: mustAbs(t, "../testdata/simple-project/hive.yml"),
+				Config:         &model.HiveProjectConfig{},
 				ImagesByIdentifier: map[string]*model.Image{
 					"dotnet/8": {
 						BuildEntryPointPath: mustAbs(t, "../testdata/simple-project/images/dotnet/8/Dockerfile"),
@@ -47,6 +52,9 @@ func TestDiscoverProject(t *testing.T) {
 								TestConfigFilePath:  mustAbs(t, "../testdata/simple-project/images/dotnet/8/node/test.yml.gotpl"),
 								TagSuffix:           "-node",
 								Versions:            model.Versions{"nodejs": "24"},
+								Secrets: model.Secrets{
+									"npm_token": model.Secret{SourceType: "plain", Value: "variant_npm_token"},
+								},
 							},
 						},
 						BuildArgs: model.BuildArgs{"foo": "bar"},
@@ -94,6 +102,10 @@ func TestDiscoverProject(t *testing.T) {
 								Value:      "secret_token_value",
 							},
 						},
+						SSH: []model.SSHConfig{
+							{ID: "default"},
+							{ID: "github", Paths: []string{"~/.ssh/id_ed25519"}},
+						},
 						Variants: map[string]*model.ImageVariant{},
 						Tags: map[string]*model.Tag{
 							"3.13.7": {
@@ -125,6 +137,9 @@ func TestDiscoverProject(t *testing.T) {
 									TestConfigFilePath:  mustAbs(t, "../testdata/simple-project/images/dotnet/8/node/test.yml.gotpl"),
 									TagSuffix:           "-node",
 									Versions:            model.Versions{"nodejs": "24"},
+									Secrets: model.Secrets{
+										"npm_token": model.Secret{SourceType: "plain", Value: "variant_npm_token"},
+									},
 								},
 							},
 							Tags: map[string]*model.Tag{
@@ -173,6 +188,10 @@ func TestDiscoverProject(t *testing.T) {
 									Value:      "secret_token_value",
 								},
 							},
+							SSH: []model.SSHConfig{
+								{ID: "default"},
+								{ID: "github", Paths: []string{"~/.ssh/id_ed25519"}},
+							},
 							Variants: map[string]*model.ImageVariant{},
 							Tags: map[string]*model.Tag{
 								"3.13.7": {
@@ -235,3 +254,110 @@ func TestDiscoverProject_DependencyProject(t *testing.T) {
 		}
 	})
 }
+
+func TestDiscoverProject_IgnoreProject(t *testing.T) {
+	project, err := DiscoverProject(t.Context(), "../testdata/ignore-project")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var identifiers []string
+	for identifier := range project.ImagesByIdentifier {
+		identifiers = append(identifiers, identifier)
+	}
+	slices.Sort(identifiers)
+	if diff := cmp.Diff([]string{"nginx", "redis"}, identifiers); diff != "" {
+		t.Errorf("DiscoverProject() images mismatch (-expected +got):\n%s", diff)
+	}
+
+	redis := project.ImagesByIdentifier["redis"]
+	if redis == nil || redis.RootDir != mustAbs(t, "../testdata/ignore-project/shared/redis") {
+		t.Errorf("expected redis to be discovered in the shared image root, got %v", redis)
+	}
+}
+
+func TestDiscoverProject_ImageRoots(t *testing.T) {
+	writeImage := func(t *testing.T, dir string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM scratch\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "image.yml"), []byte("tags:\n  - name: latest\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// {root} in the expected error is replaced with the project root
+	tests := map[string]struct {
+		hiveConfig          string
+		images              []string
+		errorContains       string
+		expectedIdentifiers []string
+	}{
+		"same identifier in multiple roots": {
+			hiveConfig:    "image_roots: [shared, images]\n",
+			images:        []string{"images/nginx", "shared/nginx"},
+			errorContains: "{root}/shared/nginx/image.yml: image nginx is already defined in {root}/images/nginx/image.yml",
+		},
+		"same identifier in sibling roots": {
+			hiveConfig:    "image_roots: [roots/a, roots/b]\n",
+			images:        []string{"roots/a/python", "roots/b/python"},
+			errorContains: "{root}/roots/b/python/image.yml: image python is already defined in {root}/roots/a/python/image.yml",
+		},
+		"nested roots": {
+			hiveConfig:    "image_roots: [images, images/base]\n",
+			images:        []string{"images/base/ubuntu"},
+			errorContains: "image root images/base must not be inside image root images",
+		},
+		"nested in project root": {
+			hiveConfig:    "image_roots: [images, .]\n",
+			images:        []string{"images/ubuntu"},
+			errorContains: "image root images must not be inside image root .",
+		},
+		"same root listed twice": {
+			hiveConfig:          "image_roots: [images, ./images/]\n",
+			images:              []string{"images/ubuntu"},
+			expectedIdentifiers: []string{"ubuntu"},
+		},
+		"root outside of project": {
+			hiveConfig:    "image_roots: [../images]\n",
+			errorContains: "image root ../images must be inside the project root",
+		},
+		"missing root": {
+			hiveConfig:    "image_roots: [images, missing]\n",
+			images:        []string{"images/nginx"},
+			errorContains: "missing: image root does not exist",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.WriteFile(filepath.Join(root, "hive.yml"), []byte(tc.hiveConfig), 0644); err != nil {
+				t.Fatal(err)
+			}
+			for _, image := range tc.images {
+				writeImage(t, filepath.Join(root, image))
+			}
+
+			project, err := DiscoverProject(t.Context(), root)
+			if tc.errorContains == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				identifiers := slices.Sorted(maps.Keys(project.ImagesByIdentifier))
+				if diff := cmp.Diff(tc.expectedIdentifiers, identifiers); diff != "" {
+					t.Errorf("DiscoverProject() images mismatch (-expected +got):\n%s", diff)
+				}
+				return
+			}
+			errorContains := strings.ReplaceAll(tc.errorContains, "{root}", root)
+			if err == nil || !strings.Contains(err.Error(), errorContains) {
+				t.Fatalf("expected error containing %q, got %v", errorContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package discovery
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is an issue found in a project file, Line and Column are set when the location is known
+type Problem struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p *Problem) Error() string {
+	location := p.File
+	if p.Line > 0 {
+		location += ":" + strconv.Itoa(p.Line)
+		if p.Column > 0 {
+			location += ":" + strconv.Itoa(p.Column)
+		}
+	}
+	return location + ": " + p.Message
+}
+
+// Problems are all issues found in a project, reported together instead of failing on the first one
+type Problems []*Problem
+
+func (p Problems) Error() string {
+	lines := make([]string, len(p))
+	for idx, problem := range p {
+		lines[idx] = "  " + problem.Error()
+	}
+	return fmt.Sprintf("%d problem(s) found:\n%s", len(p), strings.Join(lines, "\n"))
+}
+
+// Sort orders the problems by file and location
+func (p Problems) Sort() {
+	slices.SortStableFunc(p, func(a, b *Problem) int {
+		return cmp.Or(
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+			cmp.Compare(a.Message, b.Message),
+		)
+	})
+}
+
+// ProblemAt returns a problem located at the node, falling back to the file when the node is unknown
+func ProblemAt(file string, node *yaml.Node, message string) *Problem {
+	problem := &Problem{File: file, Message: message}
+	if node != nil {
+		problem.Line = node.Line
+		problem.Column = node.Column
+	}
+	return problem
+}
+
+var (
+	yamlLinePattern     = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownFieldPattern = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+)
+
+// decodeYAMLFile strictly decodes the file into out and reports all errors with their location.
+// The returned document node is empty for an empty file.
+func decodeYAMLFile(path string, out any) (*yaml.Node, Problems) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, Problems{{File: path, Message: err.Error()}}
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, Problems{yamlProblem(path, nil, err.Error())}
+	}
+
+	d := yaml.NewDecoder(bytes.NewReader(content))
+	d.KnownFields(true)
+	err = d.Decode(out)
+	if err == nil || errors.Is(err, io.EOF) {
+		return &document, nil
+	}
+
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return &document, Problems{yamlProblem(path, &document, err.Error())}
+	}
+	problems := make(Problems, 0, len(typeErr.Errors))
+	for _, msg := range typeErr.Errors {
+		problems = append(problems, yamlProblem(path, &document, msg))
+	}
+	return &document, problems
+}
+
+// yamlProblem converts a yaml error message to a problem, locating the column using the parsed document
+func yamlProblem(path string, document *yaml.Node, msg string) *Problem {
+	match := yamlLinePattern.FindStringSubmatch(msg)
+	if match == nil {
+		return &Problem{File: path, Message: strings.TrimPrefix(msg, "yaml: ")}
+	}
+
+	line, _ := strconv.Atoi(match[1])
+	problem := &Problem{File: path, Line: line, Message: match[2]}
+	if field := unknownFieldPattern.FindStringSubmatch(match[2]); field != nil {
+		problem.Message = "unknown key " + field[1]
+		if key := findNodeOnLine(document, line, field[1]); key != nil {
+			problem.Column = key.Column
+		}
+	} else if value := findNodeOnLine(document, line, ""); value != nil {
+		problem.Column = value.Column
+	}
+	return problem
+}
+
+// findNodeOnLine returns the mapping key with the given name on the line, or the first scalar value on the line
+// when no key name is given
+func findNodeOnLine(node *yaml.Node, line int, key string) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	if key == "" && node.Kind == yaml.ScalarNode && node.Line == line {
+		return node
+	}
+
+	for idx, child := range node.Content {
+		isKey := node.Kind == yaml.MappingNode && idx%2 == 0
+		if isKey {
+			if key != "" && child.Line == line && child.Value == key {
+				return child
+			}
+			continue
+		}
+		if found := findNodeOnLine(child, line, key); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// NodeAt returns the node at the path of mapping keys and sequence indices, nil if it does not exist
+func NodeAt(document *yaml.Node, path ...any) *yaml.Node {
+	if document == nil || len(document.Content) == 0 {
+		return nil
+	}
+
+	node := document.Content[0]
+	for _, segment := range path {
+		var next *yaml.Node
+		switch segment := segment.(type) {
+		case string:
+			if node.Kind != yaml.MappingNode {
+				return nil
+			}
+			for idx := 0; idx+1 < len(node.Content); idx += 2 {
+				if node.Content[idx].Value == segment {
+					next = node.Content[idx+1]
+					break
+				}
+			}
+		case int:
+			if node.Kind != yaml.SequenceNode || segment < 0 || segment >= len(node.Content) {
+				return nil
+			}
+			next = node.Content[segment]
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
@@ -0,0 +1,92 @@
+package discovery
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDiscoverProject_Problems(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"hive.yml": "",
+		// syntax error
+		"images/broken/Dockerfile": "FROM scratch\n",
+		"images/broken/image.yml":  "tags:\n\t- name: latest\n",
+		// unknown keys and wrong types
+		"images/unknown/Dockerfile": "FROM scratch\n",
+		"images/unknown/image.yml":  "tags:\n  - name: latest\n    unknown: true\nsource_date_epoch: yesterday\n",
+		// missing Dockerfile and rootfs being a file
+		"images/incomplete/image.yml": "tags:\n  - name: latest\n",
+		"images/incomplete/rootfs":    "not a directory",
+		// duplicate tags and variant suffixes
+		"images/duplicates/Dockerfile":      "FROM scratch\n",
+		"images/duplicates/slim/Dockerfile": "FROM scratch\n",
+		"images/duplicates/full/Dockerfile": "FROM scratch\n",
+		"images/duplicates/image.yml": `tags:
+  - name: "1.0"
+  - name: "1.0"
+variants:
+  - name: slim
+    tag_suffix: -slim
+  - name: full
+    tag_suffix: -slim
+  - name: dev
+`,
+		"images/valid/Dockerfile": "FROM scratch\n",
+		"images/valid/image.yml":  "tags:\n  - name: latest\n",
+	})
+
+	_, err := DiscoverProject(t.Context(), root)
+
+	var problems Problems
+	if !errors.As(err, &problems) {
+		t.Fatalf("expected problems, got %v", err)
+	}
+	var got []string
+	for _, problem := range problems {
+		got = append(got, strings.TrimPrefix(problem.Error(), root+string(os.PathSeparator)))
+	}
+	expected := []string{
+		"images/broken/image.yml:2: found character that cannot start any token",
+		"images/duplicates/image.yml:3:11: duplicate tag 1.0",
+		"images/duplicates/image.yml:8:17: variant full uses the tag_suffix -slim of variant slim",
+		"images/duplicates/image.yml:9:11: no Dockerfile found for variant dev in " + filepath.Join(root, "images/duplicates/dev"),
+		"images/incomplete: no Dockerfile found",
+		"images/incomplete/rootfs: rootfs dir is not a directory",
+		"images/unknown/image.yml:3:5: unknown key unknown",
+		"images/unknown/image.yml:4:20: cannot unmarshal !!str `yesterday` into int64",
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("DiscoverProject() problems mismatch (-expected +got):\n%s", diff)
+	}
+}
+
+func TestParseHiveConfigFile_Problems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hive.yml")
+	writeFiles(t, filepath.Dir(path), map[string]string{"hive.yml": "labels:\n  team: platform\nimage_root: images\n"})
+
+	_, err := parseHiveConfigFile(path)
+
+	expected := path + ":3:1: unknown key image_root"
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("expected error containing %q, got %v", expected, err)
+	}
+}
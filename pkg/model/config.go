@@ -7,31 +7,217 @@ type Secrets map[string]Secret
 // Secret represents a named secret with its value configuration
 // This is a simplified version for the model package
 type Secret struct {
-	SourceType string `yaml:"source,omitempty" json:"source,omitempty" jsonschema:"Source type of the secret (env, plain). If omitted, auto-detected from value."`
-	Value      string `yaml:"value" json:"value" jsonschema:"Value of the secret (env var name or plain text)"`
+	SourceType string `yaml:"source,omitempty" json:"source,omitempty" jsonschema:"Source type of the secret (env, plain, vault, file, exec, sops). If omitted, auto-detected from value."`
+	Value      string `yaml:"value" json:"value" jsonschema:"Value of the secret (env var name, plain text, vault path, file path, command or sops file and key path)"`
 }
 
 // SecretValue represents how a secret value should be resolved
 type SecretValue struct {
-	SourceType string `yaml:"source,omitempty" json:"source,omitempty" jsonschema:"Source type of the secret (env, plain). If omitted, auto-detected from value."`
-	Value      string `yaml:"value" json:"value" jsonschema:"Value of the secret (env var name or plain text)"`
+	SourceType string `yaml:"source,omitempty" json:"source,omitempty" jsonschema:"Source type of the secret (env, plain, vault, file, exec, sops). If omitted, auto-detected from value."`
+	Value      string `yaml:"value" json:"value" jsonschema:"Value of the secret (env var name, plain text, vault path, file path, command or sops file and key path)"`
 }
 
 type VariantConfig struct {
-	Name      string    `yaml:"name" json:"name" jsonschema:"Name of the variant"`
-	TagSuffix string    `yaml:"tag_suffix" json:"tag_suffix" jsonschema:"Suffix to append to the tag name for this variant"`
-	Versions  Versions  `yaml:"versions" json:"versions,omitempty" jsonschema:"Versions to use for this variant"`
-	BuildArgs BuildArgs `yaml:"build_args" json:"build_args,omitempty" jsonschema:"Build args to add for this variant"`
+	Name      string       `yaml:"name" json:"name" jsonschema:"Name of the variant"`
+	TagSuffix string       `yaml:"tag_suffix" json:"tag_suffix" jsonschema:"Suffix to append to the tag name for this variant"`
+	Versions  Versions     `yaml:"versions" json:"versions,omitempty" jsonschema:"Versions to use for this variant"`
+	BuildArgs BuildArgs    `yaml:"build_args" json:"build_args,omitempty" jsonschema:"Build args to add for this variant"`
+	Secrets   Secrets      `yaml:"secrets" json:"secrets,omitempty" jsonschema:"Secrets to resolve for this variant, overriding tag and image secrets with the same name"`
+	Labels    Labels       `yaml:"labels" json:"labels,omitempty" jsonschema:"Labels to add for this variant, overriding tag and image labels with the same name"`
+	Build     *BuildConfig `yaml:"build" json:"build,omitempty" jsonschema:"Build settings for this variant, overriding tag and image settings"`
 }
 
 type ImageDefinitionConfig struct {
-	Tags      []*Tag          `yaml:"tags" json:"tags" jsonschema:"Tags to create for this image"`
-	Variants  []VariantConfig `yaml:"variants" json:"variants,omitempty" jsonschema:"Variants to create for this image"`
-	Versions  Versions        `yaml:"versions" json:"versions,omitempty" jsonschema:"Versions to use for this image"`
-	BuildArgs BuildArgs       `yaml:"build_args" json:"build_args,omitempty" jsonschema:"Build args to add for this image"`
-	Secrets   Secrets         `yaml:"secrets" json:"secrets,omitempty" jsonschema:"Secrets to resolve for this image"`
-	DependsOn []string        `yaml:"depends_on" json:"depends_on,omitempty" jsonschema:"Names of other images in this project that must be built before this image"`
+	Tags            []*Tag             `yaml:"tags" json:"tags" jsonschema:"Tags to create for this image"`
+	Variants        []VariantConfig    `yaml:"variants" json:"variants,omitempty" jsonschema:"Variants to create for this image"`
+	Versions        Versions           `yaml:"versions" json:"versions,omitempty" jsonschema:"Versions to use for this image"`
+	BuildArgs       BuildArgs          `yaml:"build_args" json:"build_args,omitempty" jsonschema:"Build args to add for this image"`
+	Secrets         Secrets            `yaml:"secrets" json:"secrets,omitempty" jsonschema:"Secrets to resolve for this image"`
+	DependsOn       []string           `yaml:"depends_on" json:"depends_on,omitempty" jsonschema:"Names of other images in this project that must be built before this image"`
+	SSH             []SSHConfig        `yaml:"ssh" json:"ssh,omitempty" jsonschema:"SSH agents or keys to forward to the build for RUN --mount=type=ssh"`
+	Cache           *ImageCacheConfig  `yaml:"cache" json:"cache,omitempty" jsonschema:"Cache settings for this image"`
+	Compression     *CompressionConfig `yaml:"compression" json:"compression,omitempty" jsonschema:"Layer compression for this image, overriding the project settings"`
+	SourceDateEpoch int64              `yaml:"source_date_epoch" json:"source_date_epoch,omitempty" jsonschema:"Unix timestamp used for all timestamps of this image, overriding the project setting. A configured value takes precedence over the SOURCE_DATE_EPOCH environment variable, which takes precedence over the last git commit touching the image directory."`
+	Labels          Labels             `yaml:"labels" json:"labels,omitempty" jsonschema:"Labels to add for this image, overriding project labels with the same name"`
+	Platforms       []string           `yaml:"platforms" json:"platforms,omitempty" jsonschema:"Platforms to build this image for, overriding the project platforms"`
+	Build           *BuildConfig       `yaml:"build" json:"build,omitempty" jsonschema:"Build settings for this image"`
+}
+
+type ImageCacheConfig struct {
+	Disabled bool `yaml:"disabled" json:"disabled,omitempty" jsonschema:"Disable importing and exporting the build cache for this image"`
+}
+
+// NetworkMode is the network of RUN instructions
+type NetworkMode string
+
+const (
+	NetworkModeDefault NetworkMode = "default"
+	NetworkModeNone    NetworkMode = "none"
+	NetworkModeHost    NetworkMode = "host"
+)
+
+// NetworkModes are all supported network modes
+var NetworkModes = []NetworkMode{NetworkModeDefault, NetworkModeNone, NetworkModeHost}
+
+// Entitlement is a privilege the build needs to be granted by BuildKit
+type Entitlement string
+
+const (
+	EntitlementNetworkHost      Entitlement = "network.host"
+	EntitlementSecurityInsecure Entitlement = "security.insecure"
+)
+
+// Entitlements are all supported entitlements
+var Entitlements = []Entitlement{EntitlementNetworkHost, EntitlementSecurityInsecure}
+
+type BuildConfig struct {
+	Target        string        `yaml:"target" json:"target,omitempty" jsonschema:"Stage of the Dockerfile to build, defaults to the last stage"`
+	Network       NetworkMode   `yaml:"network" json:"network,omitempty" jsonschema:"Network of RUN instructions (default, none, host). host requires the network.host entitlement."`
+	AddHosts      []string      `yaml:"add_hosts" json:"add_hosts,omitempty" jsonschema:"Additional /etc/hosts entries for RUN instructions as host=ip or host:ip"`
+	ShmSize       string        `yaml:"shm_size" json:"shm_size,omitempty" jsonschema:"Size of /dev/shm for RUN instructions, e.g. 128m"`
+	Ulimits       []string      `yaml:"ulimits" json:"ulimits,omitempty" jsonschema:"Ulimits for RUN instructions as name=soft[:hard], e.g. nofile=1024:2048"`
+	NoCache       *bool         `yaml:"no_cache" json:"no_cache,omitempty" jsonschema:"Build all steps without using the cache"`
+	NoCacheFilter []string      `yaml:"no_cache_filter" json:"no_cache_filter,omitempty" jsonschema:"Stages to build without using the cache, all other stages still use it"`
+	Entitlements  []Entitlement `yaml:"entitlements" json:"entitlements,omitempty" jsonschema:"Entitlements to grant the build (network.host, security.insecure), they must be allowed by the BuildKit daemon as well"`
+}
+
+type CompressionConfig struct {
+	Type  string `yaml:"type" json:"type,omitempty" jsonschema:"Compression of exported layers (gzip, zstd, estargz, uncompressed). Defaults to gzip."`
+	Level *int   `yaml:"level" json:"level,omitempty" jsonschema:"Compression level, 0-9 for gzip and estargz, 0-22 for zstd"`
+	Force *bool  `yaml:"force" json:"force,omitempty" jsonschema:"Recompress layers inherited from base images that use a different compression"`
+}
+
+type SSHConfig struct {
+	ID    string   `yaml:"id" json:"id,omitempty" jsonschema:"ID to reference in RUN --mount=type=ssh,id=<id>. Defaults to default."`
+	Paths []string `yaml:"paths" json:"paths,omitempty" jsonschema:"Agent sockets or private key files to forward, relative to the image directory. Defaults to the agent socket from SSH_AUTH_SOCK."`
+}
+
+type VaultAuthConfig struct {
+	Method    string `yaml:"method" json:"method,omitempty" jsonschema:"Auth method to use (token, approle, jwt, kubernetes). Defaults to token."`
+	Mount     string `yaml:"mount" json:"mount,omitempty" jsonschema:"Path the auth method is mounted at. Defaults to the method name."`
+	Role      string `yaml:"role" json:"role,omitempty" jsonschema:"Role to log in with for jwt and kubernetes auth"`
+	RoleId    string `yaml:"role_id" json:"role_id,omitempty" jsonschema:"Role ID for approle auth, the secret ID is always read from VAULT_SECRET_ID"`
+	JwtEnv    string `yaml:"jwt_env" json:"jwt_env,omitempty" jsonschema:"Environment variable containing the JWT for jwt auth. Defaults to VAULT_JWT."`
+	TokenFile string `yaml:"token_file" json:"token_file,omitempty" jsonschema:"File containing the JWT for jwt or kubernetes auth. Defaults to the service account token for kubernetes."`
+}
+
+type VaultTLSConfig struct {
+	CACert     string `yaml:"ca_cert" json:"ca_cert,omitempty" jsonschema:"PEM file used to verify the server certificate, VAULT_CACERT takes precedence"`
+	CAPath     string `yaml:"ca_path" json:"ca_path,omitempty" jsonschema:"Directory of PEM files used to verify the server certificate, VAULT_CAPATH takes precedence"`
+	ClientCert string `yaml:"client_cert" json:"client_cert,omitempty" jsonschema:"PEM file with the client certificate for TLS authentication, VAULT_CLIENT_CERT takes precedence"`
+	ClientKey  string `yaml:"client_key" json:"client_key,omitempty" jsonschema:"PEM file with the client key for TLS authentication, VAULT_CLIENT_KEY takes precedence"`
+	ServerName string `yaml:"server_name" json:"server_name,omitempty" jsonschema:"Server name to use as SNI host, VAULT_TLS_SERVER_NAME takes precedence"`
+	SkipVerify bool   `yaml:"skip_verify" json:"skip_verify,omitempty" jsonschema:"Disable verification of the server certificate, VAULT_SKIP_VERIFY takes precedence"`
+}
+
+type VaultConfig struct {
+	Address   string          `yaml:"address" json:"address,omitempty" jsonschema:"Base URL of the Vault API, VAULT_ADDR takes precedence"`
+	Namespace string          `yaml:"namespace" json:"namespace,omitempty" jsonschema:"Vault Enterprise namespace, VAULT_NAMESPACE takes precedence"`
+	TLS       VaultTLSConfig  `yaml:"tls" json:"tls,omitempty" jsonschema:"TLS settings for connecting to Vault"`
+	Auth      VaultAuthConfig `yaml:"auth" json:"auth,omitempty" jsonschema:"Authentication against Vault, VAULT_AUTH_* variables take precedence"`
+}
+
+type S3CacheConfig struct {
+	EndpointUrl     string `yaml:"endpoint_url" json:"endpoint_url,omitempty" jsonschema:"Endpoint of the S3 compatible API"`
+	Bucket          string `yaml:"bucket" json:"bucket" jsonschema:"Bucket to store the cache in"`
+	Region          string `yaml:"region" json:"region,omitempty" jsonschema:"Region of the bucket"`
+	UsePathStyle    bool   `yaml:"use_path_style" json:"use_path_style,omitempty" jsonschema:"Use path style instead of virtual hosted style bucket URLs"`
+	AccessKeyId     string `yaml:"access_key_id" json:"access_key_id,omitempty" jsonschema:"Access key ID, AWS_ACCESS_KEY_ID takes precedence"`
+	SecretAccessKey string `yaml:"secret_access_key" json:"secret_access_key,omitempty" jsonschema:"Secret access key, AWS_SECRET_ACCESS_KEY takes precedence"`
+}
+
+type RegistryCacheConfig struct {
+	Repository string `yaml:"repository" json:"repository" jsonschema:"Repository to store the cache in, the cache key is used as tag"`
+	Insecure   bool   `yaml:"insecure" json:"insecure,omitempty" jsonschema:"Allow connecting to the registry via HTTP"`
+}
+
+type LocalCacheConfig struct {
+	Dir     string `yaml:"dir" json:"dir,omitempty" jsonschema:"Root directory to store the caches of all images in, relative to the project root. Defaults to container-hive/cache in the user cache directory."`
+	MaxSize string `yaml:"max_size" json:"max_size,omitempty" jsonschema:"Maximum total size of the cache enforced by ch prune-cache, e.g. 10GB"`
+	MaxAge  string `yaml:"max_age" json:"max_age,omitempty" jsonschema:"Maximum age of unused cache entries enforced by ch prune-cache, e.g. 7d or 48h"`
+}
+
+type InlineCacheConfig struct {
+	Repository string `yaml:"repository" json:"repository,omitempty" jsonschema:"Repository of previously pushed images to import the inline cache from, the cache key is used as tag"`
+}
+
+type AzBlobCacheConfig struct {
+	AccountUrl      string `yaml:"account_url" json:"account_url" jsonschema:"URL of the storage account"`
+	SecretAccessKey string `yaml:"secret_access_key" json:"secret_access_key,omitempty" jsonschema:"Secret access key of the storage account, AZURE_STORAGE_ACCOUNT_KEY takes precedence"`
+}
+
+type GHACacheConfig struct {
+	Url   string `yaml:"url" json:"url,omitempty" jsonschema:"URL of the GitHub Actions cache service, ACTIONS_CACHE_URL takes precedence"`
+	Token string `yaml:"token" json:"token,omitempty" jsonschema:"Token for the GitHub Actions cache service, ACTIONS_RUNTIME_TOKEN takes precedence"`
+}
+
+type CacheConfig struct {
+	Type       string               `yaml:"type" json:"type" jsonschema:"Cache backend to use (s3, registry, local, inline, azblob, gha)"`
+	ImportFrom []string             `yaml:"import_from" json:"import_from,omitempty" jsonschema:"Scopes to import the cache from, e.g. the main and the current branch. Environment variables are expanded. Defaults to the unscoped cache."`
+	ExportTo   []string             `yaml:"export_to" json:"export_to,omitempty" jsonschema:"Scopes to export the cache to, e.g. the current branch. Environment variables are expanded. Defaults to the unscoped cache."`
+	S3         *S3CacheConfig       `yaml:"s3" json:"s3,omitempty" jsonschema:"Settings for the s3 cache backend"`
+	Registry   *RegistryCacheConfig `yaml:"registry" json:"registry,omitempty" jsonschema:"Settings for the registry cache backend"`
+	Local      *LocalCacheConfig    `yaml:"local" json:"local,omitempty" jsonschema:"Settings for the local cache backend"`
+	Inline     *InlineCacheConfig   `yaml:"inline" json:"inline,omitempty" jsonschema:"Settings for the inline cache backend"`
+	AzBlob     *AzBlobCacheConfig   `yaml:"azblob" json:"azblob,omitempty" jsonschema:"Settings for the azblob cache backend"`
+	GHA        *GHACacheConfig      `yaml:"gha" json:"gha,omitempty" jsonschema:"Settings for the gha cache backend"`
+}
+
+type ExportConfig struct {
+	Mode     string `yaml:"mode" json:"mode,omitempty" jsonschema:"How built images are exported: tar writes an OCI tar per image, layout adds them to an OCI layout directory shared by all images, push lets BuildKit push to the registry directly. push can be combined with tar or layout, e.g. layout+push. Defaults to tar."`
+	Registry string `yaml:"registry" json:"registry,omitempty" jsonschema:"Registry to push to, e.g. ghcr.io/org. Defaults to the staging registry."`
+	Insecure bool   `yaml:"insecure" json:"insecure,omitempty" jsonschema:"Allow pushing to the registry via HTTP"`
+}
+
+type BuildkitTLSConfig struct {
+	CACert     string `yaml:"ca_cert" json:"ca_cert,omitempty" jsonschema:"PEM file used to verify the server certificate, BUILDKIT_TLS_CA_CERT takes precedence. Defaults to the system pool when other TLS settings are present."`
+	Cert       string `yaml:"cert" json:"cert,omitempty" jsonschema:"PEM file with the client certificate for TLS authentication, BUILDKIT_TLS_CERT takes precedence"`
+	Key        string `yaml:"key" json:"key,omitempty" jsonschema:"PEM file with the client key for TLS authentication, BUILDKIT_TLS_KEY takes precedence"`
+	ServerName string `yaml:"server_name" json:"server_name,omitempty" jsonschema:"Server name to verify the server certificate against, BUILDKIT_TLS_SERVER_NAME takes precedence"`
+}
+
+type BuildkitConfig struct {
+	Address        string               `yaml:"address" json:"address,omitempty" jsonschema:"Address of the BuildKit daemon: tcp://<host>:<port>, unix://<socket>, docker-container://<container> or kube-pod://<pod>. BUILDKIT_HOST takes precedence."`
+	TLS            BuildkitTLSConfig    `yaml:"tls" json:"tls,omitempty" jsonschema:"TLS settings for tcp addresses"`
+	ConnectTimeout string               `yaml:"connect_timeout" json:"connect_timeout,omitempty" jsonschema:"Timeout for establishing the connection, e.g. 10s. BUILDKIT_CONNECT_TIMEOUT takes precedence. Defaults to 30s."`
+	AutoStart      bool                 `yaml:"auto_start" json:"auto_start,omitempty" jsonschema:"Start a rootless buildkitd listening on a unix socket when the daemon is not reachable, stopped again on exit. It only allows the insecure entitlements requested by the images. CONTAINER_HIVE_BUILDKIT_AUTO_START takes precedence."`
+	StateDir       string               `yaml:"state_dir" json:"state_dir,omitempty" jsonschema:"State directory of the automatically started buildkitd, relative to the project root. Defaults to container-hive/buildkitd in the user cache directory."`
+	Nodes          []BuildkitNodeConfig `yaml:"nodes" json:"nodes,omitempty" jsonschema:"Additional BuildKit daemons building specific platforms natively, all other platforms are built on the default daemon"`
+}
+
+type BuildkitNodeConfig struct {
+	Platforms      []string          `yaml:"platforms" json:"platforms" jsonschema:"Platforms built on this node, e.g. linux/arm64"`
+	Address        string            `yaml:"address" json:"address" jsonschema:"Address of the BuildKit daemon: tcp://<host>:<port>, unix://<socket>, docker-container://<container> or kube-pod://<pod>"`
+	TLS            BuildkitTLSConfig `yaml:"tls" json:"tls,omitempty" jsonschema:"TLS settings for tcp addresses"`
+	ConnectTimeout string            `yaml:"connect_timeout" json:"connect_timeout,omitempty" jsonschema:"Timeout for establishing the connection, e.g. 10s. Defaults to 30s."`
+}
+
+type RetryPolicyConfig struct {
+	MaxAttempts    int    `yaml:"max_attempts" json:"max_attempts,omitempty" jsonschema:"Maximum number of attempts including the first one, 1 disables retries. Defaults to 3."`
+	InitialBackoff string `yaml:"initial_backoff" json:"initial_backoff,omitempty" jsonschema:"Wait time before the first retry, doubled for every further retry, e.g. 2s. Defaults to 2s."`
+	MaxBackoff     string `yaml:"max_backoff" json:"max_backoff,omitempty" jsonschema:"Upper limit of the wait time between retries, e.g. 1m. Defaults to 30s."`
+}
+
+type RetryConfig struct {
+	Build *RetryPolicyConfig `yaml:"build" json:"build,omitempty" jsonschema:"Retries of image builds failing with transient errors"`
+	Push  *RetryPolicyConfig `yaml:"push" json:"push,omitempty" jsonschema:"Retries of registry pushes failing with transient errors"`
+}
+
+type SecretsConfig struct {
+	ExecTimeout string `yaml:"exec_timeout" json:"exec_timeout,omitempty" jsonschema:"Maximum time an exec:// secret command may run, e.g. 5m for credential helpers waiting on an interactive login. Defaults to 30s."`
 }
 
 type HiveProjectConfig struct {
+	Buildkit        *BuildkitConfig    `yaml:"buildkit" json:"buildkit,omitempty" jsonschema:"Connection to the BuildKit daemon, defaults to tcp://127.0.0.1:8502"`
+	Vault           *VaultConfig       `yaml:"vault" json:"vault,omitempty" jsonschema:"Vault connection used to resolve vault:// secrets"`
+	Secrets         *SecretsConfig     `yaml:"secrets" json:"secrets,omitempty" jsonschema:"Settings for resolving secrets"`
+	Cache           *CacheConfig       `yaml:"cache" json:"cache,omitempty" jsonschema:"Build cache backend, caching is disabled when omitted"`
+	Export          *ExportConfig      `yaml:"export" json:"export,omitempty" jsonschema:"How built images are exported, defaults to an OCI tar per image"`
+	Compression     *CompressionConfig `yaml:"compression" json:"compression,omitempty" jsonschema:"Layer compression of all images, defaults to gzip"`
+	SourceDateEpoch int64              `yaml:"source_date_epoch" json:"source_date_epoch,omitempty" jsonschema:"Unix timestamp used for all timestamps of all images. A configured value takes precedence over the SOURCE_DATE_EPOCH environment variable, which takes precedence over the last git commit touching the image directory."`
+	Labels          Labels             `yaml:"labels" json:"labels,omitempty" jsonschema:"Labels to add for all images, overriding the automatically added org.opencontainers.image labels"`
+	Platforms       []string           `yaml:"platforms" json:"platforms,omitempty" jsonschema:"Platforms to build all images for, e.g. linux/amd64 and linux/arm64. Defaults to linux and the host architecture."`
+	Retry           *RetryConfig       `yaml:"retry" json:"retry,omitempty" jsonschema:"Retries of builds and pushes failing with transient errors like network issues, registry 5xx responses or a lost BuildKit connection"`
+	ImageRoots      []string           `yaml:"image_roots" json:"image_roots,omitempty" jsonschema:"Directories containing the image definitions, relative to the project root, must not be nested in each other. Defaults to images."`
+	IgnoreFile      string             `yaml:"ignore_file" json:"ignore_file,omitempty" jsonschema:"File with gitignore patterns of paths to exclude from image discovery, relative to the project root. Defaults to .hiveignore."`
 }
@@ -4,10 +4,15 @@ type Versions map[string]string
 
 type BuildArgs map[string]string
 
+type Labels map[string]string
+
 type Tag struct {
-	Name      string    `yaml:"name" json:"name" jsonschema:"Name of the tag"`
-	Versions  Versions  `yaml:"versions" json:"versions,omitempty" jsonschema:"Versions to use for this tag"`
-	BuildArgs BuildArgs `yaml:"build_args" json:"build_args,omitempty" jsonschema:"Build args to specify for this tag"`
+	Name      string       `yaml:"name" json:"name" jsonschema:"Name of the tag"`
+	Versions  Versions     `yaml:"versions" json:"versions,omitempty" jsonschema:"Versions to use for this tag"`
+	BuildArgs BuildArgs    `yaml:"build_args" json:"build_args,omitempty" jsonschema:"Build args to specify for this tag"`
+	Secrets   Secrets      `yaml:"secrets" json:"secrets,omitempty" jsonschema:"Secrets to resolve for this tag, overriding image secrets with the same name"`
+	Labels    Labels       `yaml:"labels" json:"labels,omitempty" jsonschema:"Labels to add for this tag, overriding image labels with the same name"`
+	Build     *BuildConfig `yaml:"build" json:"build,omitempty" jsonschema:"Build settings for this tag, overriding image settings"`
 }
 
 type Image struct {
@@ -24,6 +29,13 @@ type Image struct {
 	Tags                map[string]*Tag
 	Variants            map[string]*ImageVariant
 	DependsOn           []string
+	SSH                 []SSHConfig
+	Cache               *ImageCacheConfig
+	Compression         *CompressionConfig
+	SourceDateEpoch     int64
+	Labels              Labels
+	Platforms           []string
+	Build               *BuildConfig
 }
 
 type ImageVariant struct {
@@ -35,11 +47,15 @@ type ImageVariant struct {
 	TestConfigFilePath  string
 	Versions            Versions
 	BuildArgs           BuildArgs `yaml:"build_args"`
+	Secrets             Secrets   `yaml:"secrets"`
+	Labels              Labels
+	Build               *BuildConfig
 }
 
 type ContainerHiveProject struct {
 	RootDir            string
 	ConfigFilePath     string
+	Config             *HiveProjectConfig
 	ImagesByIdentifier map[string]*Image
 	ImagesByName       map[string][]*Image
 }
@@ -61,15 +61,11 @@ func fixUpEntrypoint(root, entryPath string) string {
 }
 
 func setupImageTagDir(tagPath string, image *model.Image, tag *model.Tag) error {
-	if err := mkdir(tagPath); err != nil {
+	if err := replaceDir(tagPath); err != nil {
 		return errors.Join(errors.New("failed to create tag directory"), err)
 	}
 
-	resolved, err := buildconfig_resolver.ForTag(image, tag)
-	if err != nil {
-		return errors.Join(errors.New("failed to resolve build configuration"), err)
-	}
-
+	resolved := buildconfig_resolver.ForTag(image, tag)
 	tmplCtx := newTemplateContext(image, resolved)
 
 	if image.BuildEntryPointPath != "" {
@@ -100,14 +96,10 @@ func setupImageTagDir(tagPath string, image *model.Image, tag *model.Tag) error
 }
 
 func setupVariantDir(variantPath string, image *model.Image, tag *model.Tag, variantDef *model.ImageVariant) error {
-	resolved, err := buildconfig_resolver.ForTagVariant(image, variantDef, tag)
-	if err != nil {
-		return errors.Join(errors.New("failed to resolve build configuration for variant"), err)
-	}
-
+	resolved := buildconfig_resolver.ForTagVariant(image, variantDef, tag)
 	tmplCtx := newTemplateContext(image, resolved)
 
-	if err := mkdir(variantPath); err != nil {
+	if err := replaceDir(variantPath); err != nil {
 		return errors.Join(errors.New("failed to create variant directory"), err)
 	}
 
@@ -151,9 +143,15 @@ func setupVariantDir(variantPath string, image *model.Image, tag *model.Tag, var
 	return nil
 }
 
+// RenderProject renders all images of the project to targetPath, replacing its previous content
 func RenderProject(ctx context.Context, project *model.ContainerHiveProject, targetPath string) error {
 	_ = os.RemoveAll(targetPath)
+	return RenderImages(ctx, project, targetPath)
+}
 
+// RenderImages renders the images of the project to targetPath, only replacing the directories of the rendered tags
+// and variants. The output of other images, e.g. ones outside a selection, is kept.
+func RenderImages(ctx context.Context, project *model.ContainerHiveProject, targetPath string) error {
 	err := mkdir(targetPath)
 	if err != nil {
 		return errors.Join(errors.New("failed to create target directory"), err)
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/timo-reymann/ContainerHive/pkg/discovery"
+	"github.com/timo-reymann/ContainerHive/pkg/model"
 )
 
 func discoverAndRender(t *testing.T, projectPath string) string {
@@ -326,3 +327,33 @@ func TestRenderProject_MultiVariantProject(t *testing.T) {
 		})
 	})
 }
+
+func TestRenderImages_KeepsOtherImages(t *testing.T) {
+	project, err := discovery.DiscoverProject(t.Context(), "../testdata/dependency-project")
+	if err != nil {
+		t.Fatalf("failed to discover project: %v", err)
+	}
+	dist := filepath.Join(t.TempDir(), "dist")
+	if err := RenderProject(t.Context(), project, dist); err != nil {
+		t.Fatalf("failed to render project: %v", err)
+	}
+	staleFile := filepath.Join(dist, "ubuntu", "22.04", "stale")
+	if err := os.WriteFile(staleFile, []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	selected := *project
+	selected.ImagesByName = map[string][]*model.Image{"ubuntu": project.ImagesByName["ubuntu"]}
+	if err := RenderImages(t.Context(), &selected, dist); err != nil {
+		t.Fatalf("failed to render images: %v", err)
+	}
+
+	t.Run("keeps output of other images", func(t *testing.T) {
+		assertFileContains(t, filepath.Join(dist, "python", "3.13", "Dockerfile"), "FROM __hive__/ubuntu:22.04")
+	})
+
+	t.Run("replaces output of rendered tags", func(t *testing.T) {
+		assertFileExists(t, filepath.Join(dist, "ubuntu", "22.04", "Dockerfile"))
+		assertNotExists(t, staleFile)
+	})
+}
@@ -5,3 +5,11 @@ import "os"
 func mkdir(targetPath string) error {
 	return os.MkdirAll(targetPath, 0755)
 }
+
+// replaceDir creates an empty directory, removing previously rendered content
+func replaceDir(targetPath string) error {
+	if err := os.RemoveAll(targetPath); err != nil {
+		return err
+	}
+	return mkdir(targetPath)
+}
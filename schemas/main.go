@@ -0,0 +1,14 @@
+// Package schemas contains the JSON schemas of the ContainerHive configuration files
+package schemas
+
+import _ "embed"
+
+// Project is the JSON schema of the hive.yml project configuration
+//
+//go:embed project.schema.json
+var Project []byte
+
+// Image is the JSON schema of the image.yml image definitions
+//
+//go:embed image.schema.json
+var Image []byte
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"log"
 	"os"
+	"reflect"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/timo-reymann/ContainerHive/pkg/model"
@@ -13,7 +14,12 @@ import (
 
 func main() {
 	log.Println("Generating image schema...")
-	schema, err := jsonschema.For[model.ImageDefinitionConfig](&jsonschema.ForOptions{})
+	schema, err := jsonschema.For[model.ImageDefinitionConfig](&jsonschema.ForOptions{
+		TypeSchemas: map[reflect.Type]*jsonschema.Schema{
+			reflect.TypeFor[model.NetworkMode](): enumSchema(model.NetworkModes),
+			reflect.TypeFor[model.Entitlement](): enumSchema(model.Entitlements),
+		},
+	})
 	if err != nil {
 		log.Fatal("failed to generate schema", err)
 	}
@@ -33,3 +39,12 @@ func main() {
 		log.Fatal("failed to write schema file", err)
 	}
 }
+
+// enumSchema restricts the string type to the given values
+func enumSchema[T ~string](values []T) *jsonschema.Schema {
+	enum := make([]any, len(values))
+	for i, v := range values {
+		enum[i] = string(v)
+	}
+	return &jsonschema.Schema{Type: "string", Enum: enum}
+}